@@ -2,22 +2,40 @@ package main
 
 import (
 	"flag"
+	"fmt"
 	"log"
 	"os"
+	"slices"
+	"strconv"
 	"time"
 
 	"github.com/joho/godotenv"
+	"gorm.io/gorm"
 
+	"my-go-data-generator/internal/config"
+	"my-go-data-generator/internal/databend"
 	"my-go-data-generator/internal/db"
 	"my-go-data-generator/internal/generator"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+	"my-go-data-generator/internal/schemaspec"
+	"my-go-data-generator/internal/validate"
 )
 
 func main() {
 	// 加载环境变量（例如 MYSQL_DSN）
 	godotenv.Load()
 	
-	action := flag.String("action", "generate", "操作类型：migrate 或 generate")
+	action := flag.String("action", "generate", "操作类型：migrate（其后可跟 up、down [N]、to <版本>、status）、generate、validate、partition 或 databend-ddl（其后可跟 live）")
 	flag.Parse()
+	cfg := config.Load()
+	money.UseDecimal(cfg.MoneyType == config.MoneyDecimal)
+
+	if *action == "databend-ddl" && flag.Arg(0) != "live" {
+		// 由模型与表结构说明生成 Databend 建表语句输出到标准输出，不连接 MySQL；设置了 DATABEND_DSN 时同时在该 Databend 上执行
+		databendDDL(cfg, nil)
+		return
+	}
 
 	// 从环境变量中获取DSN，如果没有则使用默认配置
 	dsn := os.Getenv("MYSQL_DSN")
@@ -28,12 +46,61 @@ func main() {
 
 	// 连接数据库
 	dbConn := db.Connect(dsn)
+	db.UseSchemaMode(dbConn, cfg.SchemaMode)
+
+	// databend-ddl live 按 MySQL 中的实际表（含表结构变更之后的列）生成 Databend 建表语句，不执行迁移
+	if *action == "databend-ddl" {
+		databendDDL(cfg, dbConn)
+		return
+	}
 
-	// 自动执行数据库迁移逻辑，确保所需表已经存在
-	db.Migrate(dbConn)
+	// 回退、迁移到指定版本与查看迁移状态只操作版本化迁移，不执行其余的建表逻辑
+	if *action == "migrate" && flag.NArg() > 0 && flag.Arg(0) != "up" {
+		migrateCommand(dbConn, cfg.SchemaMode, flag.Args())
+		return
+	}
+
+	generator.Configure(cfg)
+
+	// 自动执行数据库迁移逻辑，确保所需表已经存在；只有批量生成与定时任务按模型写入，表结构与模型不一致时才退出
+	db.Migrate(dbConn, cfg.SchemaMode, cfg.Partitioning, *action == "generate")
+	generator.MigrateSpecTables(dbConn)
 
 	if *action == "migrate" {
 		return
+	} else if *action == "partition" {
+		// 分区维护：补齐未来分区并删除过期分区，可由 cron 定期执行
+		if cfg.Partitioning.Unit == "" {
+			log.Fatal("未设置 PARTITION_BY，没有需要维护的分区表")
+		}
+		if err := db.MaintainPartitions(dbConn, cfg.Partitioning, time.Now()); err != nil {
+			log.Fatalf("分区维护失败: %v", err)
+		}
+		log.Println("分区维护完成")
+		return
+	} else if *action == "validate" {
+		// 校验目标库中用户、产品与订单的不变量，VALIDATE_DSN 可指向 Databend 等 MySQL 协议的目标库
+		target := dbConn
+		if targetDSN := os.Getenv("VALIDATE_DSN"); targetDSN != "" {
+			target = db.Connect(targetDSN)
+		}
+		since := db.RetainedSince(cfg.Partitioning, time.Now())
+		if !since.IsZero() {
+			log.Printf("已设置 PARTITION_RETENTION，跳过 %s 之前下单的订单", since.Format("2006-01-02"))
+		}
+		report, err := validate.Database(target, 1000, since)
+		if err != nil {
+			log.Fatalf("回读校验失败: %v", err)
+		}
+		for _, v := range report.Violations {
+			log.Printf("违规: %s", v)
+		}
+		log.Printf("回读校验完成：检查用户 %d 条、产品 %d 条、订单 %d 条、订单行 %d 条、支付 %d 条、退款 %d 条、物流事件 %d 条、评论 %d 条、库存流水 %d 条、转化会话事件 %d 条，违规 %d 处",
+			report.Users, report.Products, report.Orders, report.OrderItems, report.Payments, report.Refunds, report.ShipmentEvents, report.Reviews, report.Movements, report.PageViews, report.Total)
+		if report.Total > 0 {
+			os.Exit(1)
+		}
+		return
 	} else if *action == "generate" {
 		startTime := time.Now()
 		log.Println("开始批量生成数据...")
@@ -50,3 +117,115 @@ func main() {
 	// 阻塞主线程，保持定时任务运行
 	select {}
 }
+
+// migrateCommand 执行 migrate 的子命令：down [N] 回退 N 个版本（默认 1），to <版本> 升级或回退到该版本，status 查看迁移状态
+func migrateCommand(conn *gorm.DB, mode string, args []string) {
+	var err error
+	switch args[0] {
+	case "down":
+		n := 1
+		if len(args) > 1 {
+			if n, err = strconv.Atoi(args[1]); err != nil || n < 1 {
+				log.Fatalf("回退的版本数 %q 无效", args[1])
+			}
+		}
+		err = db.MigrateDown(conn, mode, n)
+	case "to":
+		if len(args) < 2 {
+			log.Fatal("migrate to 需要指定目标版本，0 表示回退全部迁移")
+		}
+		target, convErr := strconv.Atoi(args[1])
+		if convErr != nil || target < 0 {
+			log.Fatalf("目标版本 %q 无效", args[1])
+		}
+		err = db.MigrateTo(conn, mode, target)
+	case "status":
+		report, statusErr := db.MigrationStatus(conn, mode)
+		if statusErr != nil {
+			log.Fatalf("查询迁移状态失败: %v", statusErr)
+		}
+		for _, v := range report.Versions {
+			state := "待执行"
+			if v.AppliedAt != nil {
+				state = "已执行于 " + v.AppliedAt.Format("2006-01-02 15:04:05")
+			}
+			log.Printf("%04d_%s\t%s", v.Version, v.Name, state)
+		}
+		for _, d := range report.Drift {
+			log.Printf("模型与表结构不一致: %s", d)
+		}
+		log.Printf("当前版本 %d，模型与表结构不一致 %d 处", report.Current, len(report.Drift))
+		if len(report.Drift) > 0 {
+			os.Exit(1)
+		}
+		return
+	default:
+		log.Fatalf("未知的 migrate 子命令 %q，可用 up、down [N]、to <版本>、status", args[0])
+	}
+	if err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+	log.Println("数据库迁移完成")
+}
+
+// databendDDL 按表结构模式、金额列类型、TYPE_ZOO_ROWS、ROW_HISTORY 与 SCHEMA_SPEC 生成与 MySQL 表对应的 Databend 建表语句。
+// live 为 nil 时由模型与表结构说明生成；否则读取 live 所连 MySQL 中这些表的实际列
+func databendDDL(cfg config.Config, live *gorm.DB) {
+	all := db.Models(cfg.SchemaMode)
+	if cfg.TypeZooRows > 0 {
+		all = append(all, &models.TypeZoo{})
+	}
+	opts := databend.Options{Normalized: cfg.SchemaMode == config.SchemaNormalized}
+	if cfg.RowHistory {
+		opts.History = db.HistoryTables
+	}
+	var spec []*schemaspec.Table
+	if cfg.SchemaSpec != "" {
+		s, err := schemaspec.ParseFile(cfg.SchemaSpec)
+		if err != nil {
+			log.Fatalf("表结构说明 %s 有误: %v", cfg.SchemaSpec, err)
+		}
+		spec = s.Tables
+	}
+
+	var stmts []string
+	if live == nil {
+		modelStmts, err := databend.Statements(all, opts)
+		if err != nil {
+			log.Fatalf("生成 Databend 建表语句失败: %v", err)
+		}
+		specStmts, err := databend.SpecStatements(spec)
+		if err != nil {
+			log.Fatalf("生成 Databend 建表语句失败: %v", err)
+		}
+		stmts = append(modelStmts, specStmts...)
+	} else {
+		var tables []string
+		for _, model := range all {
+			stmt := &gorm.Statement{DB: live}
+			if err := stmt.Parse(model); err != nil {
+				log.Fatalf("解析模型失败: %v", err)
+			}
+			tables = append(tables, stmt.Schema.Table)
+			if slices.Contains(opts.History, stmt.Schema.Table) {
+				tables = append(tables, stmt.Schema.Table+db.HistorySuffix)
+			}
+		}
+		for _, t := range spec {
+			tables = append(tables, t.Name)
+		}
+		var err error
+		if stmts, err = databend.LiveStatements(live, all, tables); err != nil {
+			log.Fatalf("生成 Databend 建表语句失败: %v", err)
+		}
+	}
+	for _, stmt := range stmts {
+		fmt.Printf("%s;\n\n", stmt)
+	}
+	if dsn := os.Getenv("DATABEND_DSN"); dsn != "" {
+		if err := databend.Apply(db.Connect(dsn), stmts); err != nil {
+			log.Fatalf("在 Databend 上建表失败: %v", err)
+		}
+		log.Printf("已在 Databend 上执行建表语句 %d 条", len(stmts))
+	}
+}
@@ -0,0 +1,121 @@
+package anomaly
+
+import (
+	"fmt"
+	"math/rand"
+	"strings"
+	"time"
+
+	"my-go-data-generator/internal/models"
+)
+
+// 可注入的数据质量缺陷类型
+const (
+	OrphanUser          = "orphan_user"           // 订单引用不存在的用户
+	OrphanProduct       = "orphan_product"        // 订单引用不存在的产品
+	DuplicateUser       = "duplicate_user"        // 与已有用户近似重复的用户
+	NonPositiveAmount   = "non_positive_amount"   // 订单总额为零或负数
+	DeliveryBeforeOrder = "delivery_before_order" // 送达日期早于下单日期
+	ImpossibleAge       = "impossible_age"        // 不可能的年龄
+)
+
+// Kinds 所有缺陷类型
+var Kinds = []string{OrphanUser, OrphanProduct, DuplicateUser, NonPositiveAmount, DeliveryBeforeOrder, ImpossibleAge}
+
+// orphanIDBase 孤儿引用使用的主键起点，远大于任何实际生成的主键
+const orphanIDBase = 900000000000
+
+var impossibleAges = []int{-1, 0, 3, 150, 999}
+
+// Anomaly 一处注入的缺陷
+type Anomaly struct {
+	Kind   string
+	Column string
+	Detail string
+}
+
+// Injector 按缺陷类型配置的比例注入数据质量缺陷
+type Injector struct {
+	rates map[string]float64
+}
+
+// New 创建注入器，rates 以缺陷类型为键；rates 为空时返回 nil（不注入）
+func New(rates map[string]float64) *Injector {
+	if len(rates) == 0 {
+		return nil
+	}
+	return &Injector{rates: rates}
+}
+
+func (in *Injector) hit(kind string) bool {
+	return in != nil && rand.Float64() < in.rates[kind]
+}
+
+// User 向用户注入行内缺陷（不可能的年龄）
+func (in *Injector) User(u *models.User) []Anomaly {
+	var out []Anomaly
+	if in.hit(ImpossibleAge) {
+		before := u.Age
+		u.Age = impossibleAges[rand.Intn(len(impossibleAges))]
+		out = append(out, Anomaly{ImpossibleAge, "age", fmt.Sprintf("%d -> %d", before, u.Age)})
+	}
+	return out
+}
+
+// WantDuplicate 是否将下一个用户替换为近似重复的用户
+func (in *Injector) WantDuplicate() bool {
+	return in.hit(DuplicateUser)
+}
+
+// NearDuplicate 基于 src 构造一个近似重复的用户：姓名、年龄、地址相同或只有细微差异，
+// 邮箱与电话追加后缀以满足唯一索引
+func NearDuplicate(src models.User, now time.Time) models.User {
+	dup := src
+	dup.ID = 0
+	switch rand.Intn(3) {
+	case 0:
+		dup.Username = src.Username + " "
+	case 1:
+		dup.Username = strings.ToUpper(src.Username)
+	default:
+		dup.Username = strings.ReplaceAll(src.Username, " ", "")
+	}
+	local, domain, _ := strings.Cut(src.Email, "@")
+	dup.Email = local + "+dup@" + domain
+	dup.Phone = src.Phone + "#1"
+	dup.Address = strings.TrimSpace(src.Address) + " "
+	dup.RegistrationDate = now
+	dup.LastLogin = now
+	dup.LoyaltyPoints = 0
+	dup.CreatedAt = now
+	dup.UpdatedAt = now
+	return dup
+}
+
+// Order 向订单注入缺陷：孤儿引用、非正金额、送达日期早于下单日期
+func (in *Injector) Order(o *models.Order) []Anomaly {
+	var out []Anomaly
+	if in.hit(OrphanUser) {
+		o.UserID = uint(orphanIDBase + rand.Intn(1000000))
+		out = append(out, Anomaly{OrphanUser, "user_id", fmt.Sprint(o.UserID)})
+	}
+	if in.hit(OrphanProduct) {
+		o.ProductID = uint(orphanIDBase + rand.Intn(1000000))
+		out = append(out, Anomaly{OrphanProduct, "product_id", fmt.Sprint(o.ProductID)})
+	}
+	if in.hit(NonPositiveAmount) {
+		before := o.TotalAmount
+		if rand.Intn(2) == 0 {
+			o.TotalAmount = 0
+		} else {
+			o.TotalAmount = -o.TotalAmount
+		}
+		out = append(out, Anomaly{NonPositiveAmount, "total_amount", fmt.Sprintf("%s -> %s", before, o.TotalAmount)})
+	}
+	if in.hit(DeliveryBeforeOrder) {
+		delivery := o.OrderDate.Add(-time.Duration(rand.Intn(72)+1) * time.Hour)
+		o.DeliveryDate = &delivery
+		out = append(out, Anomaly{DeliveryBeforeOrder, "delivery_date", delivery.Format(time.RFC3339)})
+	}
+	return out
+}
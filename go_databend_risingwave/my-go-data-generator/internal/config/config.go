@@ -0,0 +1,429 @@
+package config
+
+import (
+	"fmt"
+	"log"
+	"os"
+	"strconv"
+	"strings"
+)
+
+// Config 生成器的可调参数，全部从环境变量读取（可写在 .env 中）
+type Config struct {
+	OrderStatusWeights    Weights // ORDER_STATUS_WEIGHTS 订单状态分布，如 "待付款:5,已完成:60"
+	ReturnStatusWeights   Weights // RETURN_STATUS_WEIGHTS 已完成订单发生退货时的退货状态分布
+	ReturnRate            float64 // ORDER_RETURN_RATE 已完成订单发起退货的比例
+	GiftRate              float64 // ORDER_GIFT_RATE 礼物订单比例
+	CustomerNoteRate      float64 // ORDER_CUSTOMER_NOTE_RATE 订单带客户备注的比例
+	ItemCountWeights      Weights // ORDER_ITEM_COUNTS 每笔订单的订单行数分布，如 "1:60,2:25,3:15"
+	PaymentFailureRate    float64 // PAYMENT_FAILURE_RATE 每次支付尝试失败的概率
+	PartialPaymentRate    float64 // PAYMENT_PARTIAL_RATE 已支付订单分两笔部分支付的比例
+	ShipmentExceptionRate float64 // SHIPMENT_EXCEPTION_RATE 物流轨迹中出现异常事件的订单比例
+	ReviewRate            float64 // REVIEW_RATE 已完成订单中每个商品被评论的比例
+
+	ClickstreamGB            float64 // CLICKSTREAM_GB 批量生成的浏览会话点击流数据量（GB），与订单规模无关
+	ClickstreamRate          float64 // CLICKSTREAM_EVENTS_PER_SECOND 定时任务每秒追加的点击流事件数，0 表示不追加
+	ClickstreamAnonymousRate float64 // CLICKSTREAM_ANONYMOUS_RATE 浏览会话中未登录访客的比例
+
+	SchemaMode  string // SCHEMA_MODE 表结构模式：denormalized（文本列，默认）或 normalized（维度表 + 维度ID）
+	MoneyType   string // MONEY_TYPE 金额列类型：double（默认）或 decimal（DECIMAL(18,2)）；金额计算始终为定点运算
+	TypeZooRows int    // TYPE_ZOO_ROWS 批量写入 type_zoo 类型覆盖表的行数，0 表示不创建该表；定时任务对其插入、更新与删除
+
+	// Partitioning 订单等按时间写入的表的 RANGE 分区（PARTITION_BY、PARTITION_AHEAD、PARTITION_RETENTION）
+	Partitioning Partitioning
+
+	SoftDelete bool // SOFT_DELETE 定时任务以写入 deleted_at 的软删除代替物理删除用户与订单
+	RowHistory bool // ROW_HISTORY 定时任务每次更新或删除用户、产品与订单前，将原行连同有效期写入对应的 *_history 表
+
+	SchemaSpec string // SCHEMA_SPEC 表结构说明文件（带生成器注释的 CREATE TABLE 语句），其中的表按说明建表、生成与追加
+
+	SchemaEvolution    string // SCHEMA_EVOLUTION 表结构变更计划文件，定时任务按计划对在写的表执行 ALTER TABLE
+	SchemaEvolutionLog string // SCHEMA_EVOLUTION_LOG 表结构变更日志文件，记录每条 DDL 的执行时刻及其前后的写入位置
+
+	NullRates  Rates  // COLUMN_NULL_RATES 可空列写入 NULL 的比例，如 "users.hobby:0.2"
+	EmptyRates Rates  // COLUMN_EMPTY_RATES 可空列写入空字符串的比例
+	CSVDir     string // CSV_DIR 非空时同时将生成的数据导出为 CSV 文件到该目录
+
+	TargetGB        float64 // TARGET_GB 目标数据量（GB），用于推算各表记录数
+	TextLengths     Ranges  // TEXT_LENGTHS 各文本列的字符长度区间，如 "products.description:100-2000"
+	EnglishTextRate float64 // TEXT_ENGLISH_RATE 文本列生成英文内容的比例
+
+	LocaleWeights Weights // LOCALE_MIX 用户所属地区的分布，如 "zh_CN:70,en_US:10,ja_JP:10,de_DE:10"
+
+	EdgeCaseRates    Rates  // EDGE_CASE_RATES 各字符串列注入边界字符串的比例，为空表示不注入
+	EdgeCaseManifest string // EDGE_CASE_MANIFEST 边界字符串注入清单文件
+
+	AnomalyRates    Rates  // ANOMALY_RATES 各类数据质量缺陷的注入比例，如 "orphan_user:0.001"，为空表示不注入
+	AnomalyManifest string // ANOMALY_MANIFEST 缺陷注入清单文件（真值）
+
+	// FieldGenerators FIELD_GENERATORS 覆盖模型 gen 标签的字段生成器，
+	// 格式为 "表.列=生成器;表.列=生成器"，如 "orders.payment_method=enum(支付宝:60,微信支付:40)"
+	FieldGenerators map[string]string
+}
+
+// 金额列类型
+const (
+	MoneyDouble  = "double"
+	MoneyDecimal = "decimal"
+)
+
+// Partitioning 按时间列 RANGE 分区的设置，只在建表时生效；已有的未分区表保持不变
+type Partitioning struct {
+	Unit      string // PARTITION_BY 分区粒度：month 或 day，为空表示不分区
+	Ahead     int    // PARTITION_AHEAD 当前周期之后预先建好的分区数
+	Retention int    // PARTITION_RETENTION 当前周期之前保留的分区数，更早的分区由维护任务删除；0 表示不删除
+}
+
+// 分区粒度
+const (
+	PartitionMonth = "month"
+	PartitionDay   = "day"
+)
+
+// 表结构模式
+const (
+	SchemaDenormalized = "denormalized" // 分类、制造商等以文本列存放在各行上（宽表）
+	SchemaNormalized   = "normalized"   // 生成维度表，各行以维度ID引用（星型模型）
+)
+
+// Default 返回默认配置
+func Default() Config {
+	return Config{
+		OrderStatusWeights: Weights{
+			{"待付款", 5}, {"已付款", 5}, {"待发货", 10}, {"已发货", 15}, {"已完成", 55}, {"已取消", 10},
+		},
+		ReturnStatusWeights: Weights{
+			{"退货申请中", 20}, {"退货中", 20}, {"已退货", 50}, {"退货被拒", 10},
+		},
+		ReturnRate:       0.08,
+		GiftRate:         0.1,
+		CustomerNoteRate: 0.3,
+		ItemCountWeights: Weights{
+			{"1", 55}, {"2", 25}, {"3", 12}, {"4", 5}, {"5", 3},
+		},
+		PaymentFailureRate:       0.1,
+		PartialPaymentRate:       0.05,
+		ShipmentExceptionRate:    0.03,
+		ReviewRate:               0.3,
+		ClickstreamGB:            5,
+		ClickstreamRate:          20,
+		ClickstreamAnonymousRate: 0.4,
+		SchemaMode:               SchemaDenormalized,
+		MoneyType:                MoneyDouble,
+		Partitioning:             Partitioning{Ahead: 3},
+		NullRates: Rates{
+			"users.hobby":              0.15,
+			"products.description":     0.02,
+			"products.warranty_period": 0.05,
+			"orders.internal_note":     0.2,
+			"orders.extra_info":        0.5,
+			"reviews.content":          0.3,
+		},
+		TargetGB: 50,
+		TextLengths: Ranges{
+			"products.description": {60, 800},
+			"orders.customer_note": {5, 120},
+			"orders.internal_note": {10, 200},
+			"orders.gift_message":  {4, 60},
+			"orders.extra_info":    {20, 400},
+			"reviews.content":      {10, 300},
+		},
+		EnglishTextRate: 0.2,
+		LocaleWeights: Weights{
+			{"zh_CN", 70}, {"en_US", 10}, {"ja_JP", 8}, {"de_DE", 6}, {"en_GB", 6},
+		},
+		EdgeCaseManifest:   "edge_cases.csv",
+		AnomalyManifest:    "anomalies.csv",
+		SchemaEvolutionLog: "schema_evolution.csv",
+		EmptyRates: Rates{
+			"users.hobby":          0.05,
+			"products.description": 0.01,
+			"orders.customer_note": 0.05,
+			"orders.gift_message":  0.1,
+			"orders.extra_info":    0.1,
+		},
+	}
+}
+
+// Load 在默认配置的基础上读取环境变量覆盖项
+func Load() Config {
+	c := Default()
+	c.OrderStatusWeights = envWeights("ORDER_STATUS_WEIGHTS", c.OrderStatusWeights)
+	c.ReturnStatusWeights = envWeights("RETURN_STATUS_WEIGHTS", c.ReturnStatusWeights)
+	c.ReturnRate = envFloat("ORDER_RETURN_RATE", c.ReturnRate)
+	c.GiftRate = envFloat("ORDER_GIFT_RATE", c.GiftRate)
+	c.CustomerNoteRate = envFloat("ORDER_CUSTOMER_NOTE_RATE", c.CustomerNoteRate)
+	c.ItemCountWeights = envWeights("ORDER_ITEM_COUNTS", c.ItemCountWeights)
+	c.PaymentFailureRate = envFloat("PAYMENT_FAILURE_RATE", c.PaymentFailureRate)
+	c.PartialPaymentRate = envFloat("PAYMENT_PARTIAL_RATE", c.PartialPaymentRate)
+	c.ShipmentExceptionRate = envFloat("SHIPMENT_EXCEPTION_RATE", c.ShipmentExceptionRate)
+	c.ReviewRate = envFloat("REVIEW_RATE", c.ReviewRate)
+	c.ClickstreamGB = envFloat("CLICKSTREAM_GB", c.ClickstreamGB)
+	c.ClickstreamRate = envFloat("CLICKSTREAM_EVENTS_PER_SECOND", c.ClickstreamRate)
+	c.ClickstreamAnonymousRate = envFloat("CLICKSTREAM_ANONYMOUS_RATE", c.ClickstreamAnonymousRate)
+	switch s := os.Getenv("SCHEMA_MODE"); s {
+	case "":
+	case SchemaDenormalized, SchemaNormalized:
+		c.SchemaMode = s
+	default:
+		log.Printf("环境变量 SCHEMA_MODE=%q 无效，使用默认值 %s", s, c.SchemaMode)
+	}
+	switch s := os.Getenv("MONEY_TYPE"); s {
+	case "":
+	case MoneyDouble, MoneyDecimal:
+		c.MoneyType = s
+	default:
+		log.Printf("环境变量 MONEY_TYPE=%q 无效，使用默认值 %s", s, c.MoneyType)
+	}
+	c.TypeZooRows = envInt("TYPE_ZOO_ROWS", c.TypeZooRows)
+	switch s := os.Getenv("PARTITION_BY"); s {
+	case "", PartitionMonth, PartitionDay:
+		c.Partitioning.Unit = s
+	default:
+		log.Printf("环境变量 PARTITION_BY=%q 无效，不分区", s)
+	}
+	c.Partitioning.Ahead = envInt("PARTITION_AHEAD", c.Partitioning.Ahead)
+	c.Partitioning.Retention = envInt("PARTITION_RETENTION", c.Partitioning.Retention)
+	c.SoftDelete = envBool("SOFT_DELETE", c.SoftDelete)
+	c.RowHistory = envBool("ROW_HISTORY", c.RowHistory)
+	c.SchemaSpec = os.Getenv("SCHEMA_SPEC")
+	c.SchemaEvolution = os.Getenv("SCHEMA_EVOLUTION")
+	if s := os.Getenv("SCHEMA_EVOLUTION_LOG"); s != "" {
+		c.SchemaEvolutionLog = s
+	}
+	c.NullRates = envRates("COLUMN_NULL_RATES", c.NullRates)
+	c.EmptyRates = envRates("COLUMN_EMPTY_RATES", c.EmptyRates)
+	c.CSVDir = os.Getenv("CSV_DIR")
+	c.TargetGB = envFloat("TARGET_GB", c.TargetGB)
+	c.TextLengths = envRanges("TEXT_LENGTHS", c.TextLengths)
+	c.EnglishTextRate = envFloat("TEXT_ENGLISH_RATE", c.EnglishTextRate)
+	c.LocaleWeights = envWeights("LOCALE_MIX", c.LocaleWeights)
+	c.EdgeCaseRates = envRates("EDGE_CASE_RATES", c.EdgeCaseRates)
+	if s := os.Getenv("EDGE_CASE_MANIFEST"); s != "" {
+		c.EdgeCaseManifest = s
+	}
+	c.FieldGenerators = envAssignments("FIELD_GENERATORS")
+	c.AnomalyRates = envRates("ANOMALY_RATES", c.AnomalyRates)
+	if s := os.Getenv("ANOMALY_MANIFEST"); s != "" {
+		c.AnomalyManifest = s
+	}
+	return c
+}
+
+// Weight 带权重的取值
+type Weight struct {
+	Value  string
+	Weight float64
+}
+
+// Weights 加权分布，格式为 "值:权重,值:权重"
+type Weights []Weight
+
+// Pick 按权重选取一个值，r 为 [0,1) 区间的随机数
+func (w Weights) Pick(r float64) string {
+	var total float64
+	for _, item := range w {
+		total += item.Weight
+	}
+	target := r * total
+	for _, item := range w {
+		if target < item.Weight {
+			return item.Value
+		}
+		target -= item.Weight
+	}
+	return w[len(w)-1].Value
+}
+
+// ParseWeights 解析 "值:权重,值:权重" 格式的分布
+func ParseWeights(s string) (Weights, error) {
+	var w Weights
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		value, weight, found := strings.Cut(part, ":")
+		item := Weight{Value: strings.TrimSpace(value), Weight: 1}
+		if found {
+			f, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			if err != nil {
+				return nil, err
+			}
+			item.Weight = f
+		}
+		w = append(w, item)
+	}
+	return w, nil
+}
+
+// Rates 以 "表.列" 为键的比例配置
+type Rates map[string]float64
+
+// Of 返回指定表列的比例，未配置时为 0
+func (r Rates) Of(table, column string) float64 {
+	return r[table+"."+column]
+}
+
+// envRates 解析 "表.列:比例" 格式的环境变量，并覆盖默认配置中的同名项
+func envRates(key string, def Rates) Rates {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	w, err := ParseWeights(s)
+	if err != nil {
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值: %v", key, s, err)
+		return def
+	}
+	r := make(Rates, len(def)+len(w))
+	for k, v := range def {
+		r[k] = v
+	}
+	for _, item := range w {
+		r[item.Value] = item.Weight
+	}
+	return r
+}
+
+// Range 闭区间 [Min, Max]
+type Range struct {
+	Min int
+	Max int
+}
+
+// Ranges 以 "表.列" 为键的区间配置
+type Ranges map[string]Range
+
+// Of 返回指定表列的区间，ok 表示是否配置
+func (r Ranges) Of(table, column string) (Range, bool) {
+	v, ok := r[table+"."+column]
+	return v, ok
+}
+
+// ParseRanges 解析 "表.列:最小-最大,表.列:最小-最大" 格式的区间配置
+func ParseRanges(s string) (Ranges, error) {
+	r := make(Ranges)
+	for _, part := range strings.Split(s, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		key, bounds, found := strings.Cut(part, ":")
+		if !found {
+			return nil, fmt.Errorf("%q 缺少区间", part)
+		}
+		lo, hi, found := strings.Cut(bounds, "-")
+		if !found {
+			hi = lo
+		}
+		min, err := strconv.Atoi(strings.TrimSpace(lo))
+		if err != nil {
+			return nil, err
+		}
+		max, err := strconv.Atoi(strings.TrimSpace(hi))
+		if err != nil {
+			return nil, err
+		}
+		if max < min {
+			return nil, fmt.Errorf("%q 的上限小于下限", part)
+		}
+		r[strings.TrimSpace(key)] = Range{Min: min, Max: max}
+	}
+	return r, nil
+}
+
+// envRanges 解析区间配置，并覆盖默认配置中的同名项
+func envRanges(key string, def Ranges) Ranges {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	parsed, err := ParseRanges(s)
+	if err != nil {
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值: %v", key, s, err)
+		return def
+	}
+	r := make(Ranges, len(def)+len(parsed))
+	for k, v := range def {
+		r[k] = v
+	}
+	for k, v := range parsed {
+		r[k] = v
+	}
+	return r
+}
+
+// envAssignments 解析 "键=值;键=值" 格式的环境变量；值中可以包含逗号与冒号
+func envAssignments(key string) map[string]string {
+	s := os.Getenv(key)
+	if s == "" {
+		return nil
+	}
+	m := make(map[string]string)
+	for _, part := range strings.Split(s, ";") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		k, v, found := strings.Cut(part, "=")
+		if !found {
+			log.Printf("环境变量 %s 中的 %q 缺少 =，已忽略", key, part)
+			continue
+		}
+		m[strings.TrimSpace(k)] = strings.TrimSpace(v)
+	}
+	return m
+}
+
+func envWeights(key string, def Weights) Weights {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	w, err := ParseWeights(s)
+	if err != nil || len(w) == 0 {
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值: %v", key, s, err)
+		return def
+	}
+	return w
+}
+
+func envFloat(key string, def float64) float64 {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	f, err := strconv.ParseFloat(s, 64)
+	if err != nil {
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值: %v", key, s, err)
+		return def
+	}
+	return f
+}
+
+func envInt(key string, def int) int {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	n, err := strconv.Atoi(s)
+	if err != nil {
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值: %v", key, s, err)
+		return def
+	}
+	return n
+}
+
+func envBool(key string, def bool) bool {
+	s := os.Getenv(key)
+	if s == "" {
+		return def
+	}
+	b, err := strconv.ParseBool(s)
+	if err != nil {
+		log.Printf("环境变量 %s=%q 格式错误，使用默认值: %v", key, s, err)
+		return def
+	}
+	return b
+}
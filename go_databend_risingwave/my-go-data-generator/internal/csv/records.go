@@ -0,0 +1,311 @@
+package csv
+
+import (
+	"fmt"
+	"strconv"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/models"
+)
+
+// NullValue CSV 中表示 NULL 的标记，与 MySQL LOAD DATA 的默认约定一致；空字符串则原样写为空字段
+const NullValue = `\N`
+
+// timeLayout 与 MySQL DATETIME 兼容的时间格式
+const timeLayout = "2006-01-02 15:04:05.000"
+
+func formatTime(t time.Time) string {
+	return t.Format(timeLayout)
+}
+
+func nullString(s *string) string {
+	if s == nil {
+		return NullValue
+	}
+	return *s
+}
+
+func nullTime(t *time.Time) string {
+	if t == nil {
+		return NullValue
+	}
+	return formatTime(*t)
+}
+
+func nullUint(v *uint) string {
+	if v == nil {
+		return NullValue
+	}
+	return formatUint(*v)
+}
+
+func nullDeleted(d gorm.DeletedAt) string {
+	if !d.Valid {
+		return NullValue
+	}
+	return formatTime(d.Time)
+}
+
+func formatFloat(f float64) string {
+	return strconv.FormatFloat(f, 'f', 2, 64)
+}
+
+// Value 将任意取值格式化为 CSV 字段，用于没有模型的表：nil 写为 NULL，时间与浮点数与模型表的格式一致
+func Value(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return NullValue
+	case time.Time:
+		return formatTime(v)
+	case float64:
+		return strconv.FormatFloat(v, 'f', -1, 64)
+	case bool:
+		return strconv.FormatBool(v)
+	}
+	return fmt.Sprint(v)
+}
+
+func formatUint(v uint) string {
+	return strconv.FormatUint(uint64(v), 10)
+}
+
+// UserHeader users.csv 的表头，列名与数据库列名一致
+var UserHeader = []string{"id", "username", "gender", "age", "email", "phone", "address", "nationality", "region_id", "occupation", "marital_status", "education", "hobby", "income", "registration_date", "last_login", "loyalty_points", "preferred_language", "currency", "timezone", "status", "created_at", "updated_at", "deleted_at"}
+
+// UserRecord 将用户转换为一行 CSV 记录
+func UserRecord(u *models.User) []string {
+	return []string{
+		formatUint(u.ID),
+		u.Username,
+		u.Gender,
+		strconv.Itoa(u.Age),
+		u.Email,
+		u.Phone,
+		u.Address,
+		u.Nationality,
+		nullUint(u.RegionID),
+		u.Occupation,
+		u.MaritalStatus,
+		u.Education,
+		nullString(u.Hobby),
+		u.Income.String(),
+		formatTime(u.RegistrationDate),
+		formatTime(u.LastLogin),
+		strconv.Itoa(u.LoyaltyPoints),
+		u.PreferredLanguage,
+		u.Currency,
+		u.Timezone,
+		u.Status,
+		formatTime(u.CreatedAt),
+		formatTime(u.UpdatedAt),
+		nullDeleted(u.DeletedAt),
+	}
+}
+
+// ProductHeader products.csv 的表头
+var ProductHeader = []string{"id", "product_name", "category", "category_id", "description", "price", "stock", "sku", "manufacturer", "manufacturer_id", "weight", "dimensions", "color", "material", "release_date", "warranty_period", "country_of_origin", "origin_region_id", "rating", "number_of_reviews", "discount", "stock_status", "supplier", "supplier_id", "created_at", "updated_at"}
+
+// ProductRecord 将产品转换为一行 CSV 记录
+func ProductRecord(p *models.Product) []string {
+	return []string{
+		formatUint(p.ID),
+		p.ProductName,
+		p.Category,
+		nullUint(p.CategoryID),
+		nullString(p.Description),
+		p.Price.String(),
+		strconv.Itoa(p.Stock),
+		p.SKU,
+		p.Manufacturer,
+		nullUint(p.ManufacturerID),
+		formatFloat(p.Weight),
+		p.Dimensions,
+		p.Color,
+		p.Material,
+		formatTime(p.ReleaseDate),
+		nullString(p.WarrantyPeriod),
+		p.CountryOfOrigin,
+		nullUint(p.OriginRegionID),
+		formatFloat(p.Rating),
+		strconv.Itoa(p.NumberOfReviews),
+		formatFloat(p.Discount),
+		p.StockStatus,
+		p.Supplier,
+		nullUint(p.SupplierID),
+		formatTime(p.CreatedAt),
+		formatTime(p.UpdatedAt),
+	}
+}
+
+// OrderHeader orders.csv 的表头
+var OrderHeader = []string{"id", "order_number", "user_id", "product_id", "order_date", "quantity", "subtotal", "total_amount", "payment_method", "payment_method_id", "shipping_address", "billing_address", "order_status", "discount_amount", "tax_amount", "shipping_cost", "tracking_number", "shipped_at", "delivery_date", "return_status", "customer_note", "internal_note", "is_gift", "gift_message", "extra_info", "created_at", "updated_at", "deleted_at"}
+
+// OrderRecord 将订单转换为一行 CSV 记录
+func OrderRecord(o *models.Order) []string {
+	return []string{
+		formatUint(o.ID),
+		o.OrderNumber,
+		formatUint(o.UserID),
+		formatUint(o.ProductID),
+		formatTime(o.OrderDate),
+		strconv.Itoa(o.Quantity),
+		o.Subtotal.String(),
+		o.TotalAmount.String(),
+		o.PaymentMethod,
+		nullUint(o.PaymentMethodID),
+		o.ShippingAddress,
+		o.BillingAddress,
+		o.OrderStatus,
+		o.DiscountAmount.String(),
+		o.TaxAmount.String(),
+		o.ShippingCost.String(),
+		nullString(o.TrackingNumber),
+		nullTime(o.ShippedAt),
+		nullTime(o.DeliveryDate),
+		nullString(o.ReturnStatus),
+		nullString(o.CustomerNote),
+		nullString(o.InternalNote),
+		strconv.FormatBool(o.IsGift),
+		nullString(o.GiftMessage),
+		nullString(o.ExtraInfo),
+		formatTime(o.CreatedAt),
+		formatTime(o.UpdatedAt),
+		nullDeleted(o.DeletedAt),
+	}
+}
+
+// OrderItemHeader order_items.csv 的表头
+var OrderItemHeader = []string{"id", "order_id", "line_number", "product_id", "quantity", "currency", "unit_price", "subtotal", "discount_amount", "tax_amount", "line_total", "created_at"}
+
+// OrderItemRecord 将订单行转换为一行 CSV 记录
+func OrderItemRecord(it *models.OrderItem) []string {
+	return []string{
+		formatUint(it.ID),
+		formatUint(it.OrderID),
+		strconv.Itoa(it.LineNumber),
+		formatUint(it.ProductID),
+		strconv.Itoa(it.Quantity),
+		it.Currency,
+		it.UnitPrice.String(),
+		it.Subtotal.String(),
+		it.DiscountAmount.String(),
+		it.TaxAmount.String(),
+		it.LineTotal.String(),
+		formatTime(it.CreatedAt),
+	}
+}
+
+// PaymentHeader payments.csv 的表头
+var PaymentHeader = []string{"id", "order_id", "attempt_number", "method", "payment_method_id", "provider", "transaction_id", "status", "amount", "currency", "failure_reason", "paid_at", "created_at"}
+
+// PaymentRecord 将支付记录转换为一行 CSV 记录
+func PaymentRecord(p *models.Payment) []string {
+	return []string{
+		formatUint(p.ID),
+		formatUint(p.OrderID),
+		strconv.Itoa(p.AttemptNumber),
+		p.Method,
+		nullUint(p.MethodID),
+		p.Provider,
+		nullString(p.TransactionID),
+		p.Status,
+		p.Amount.String(),
+		p.Currency,
+		nullString(p.FailureReason),
+		nullTime(p.PaidAt),
+		formatTime(p.CreatedAt),
+	}
+}
+
+// RefundHeader refunds.csv 的表头
+var RefundHeader = []string{"id", "order_id", "payment_id", "amount", "currency", "status", "requested_at", "refunded_at", "created_at"}
+
+// RefundRecord 将退款记录转换为一行 CSV 记录
+func RefundRecord(r *models.Refund) []string {
+	return []string{
+		formatUint(r.ID),
+		formatUint(r.OrderID),
+		formatUint(r.PaymentID),
+		r.Amount.String(),
+		r.Currency,
+		r.Status,
+		formatTime(r.RequestedAt),
+		nullTime(r.RefundedAt),
+		formatTime(r.CreatedAt),
+	}
+}
+
+// ShipmentEventHeader shipment_events.csv 的表头
+var ShipmentEventHeader = []string{"id", "order_id", "sequence", "tracking_number", "carrier", "event_type", "location", "description", "event_time", "created_at"}
+
+// ShipmentEventRecord 将物流事件转换为一行 CSV 记录
+func ShipmentEventRecord(e *models.ShipmentEvent) []string {
+	return []string{
+		formatUint(e.ID),
+		formatUint(e.OrderID),
+		strconv.Itoa(e.Sequence),
+		e.TrackingNumber,
+		e.Carrier,
+		e.EventType,
+		e.Location,
+		e.Description,
+		formatTime(e.EventTime),
+		formatTime(e.CreatedAt),
+	}
+}
+
+// ReviewHeader reviews.csv 的表头
+var ReviewHeader = []string{"id", "product_id", "user_id", "order_id", "stars", "content", "created_at"}
+
+// ReviewRecord 将评论转换为一行 CSV 记录
+func ReviewRecord(r *models.Review) []string {
+	return []string{
+		formatUint(r.ID),
+		formatUint(r.ProductID),
+		formatUint(r.UserID),
+		formatUint(r.OrderID),
+		strconv.Itoa(r.Stars),
+		nullString(r.Content),
+		formatTime(r.CreatedAt),
+	}
+}
+
+// InventoryMovementHeader inventory_movements.csv 的表头
+var InventoryMovementHeader = []string{"id", "product_id", "order_id", "movement_type", "quantity", "note", "movement_at", "created_at"}
+
+// InventoryMovementRecord 将库存流水转换为一行 CSV 记录
+func InventoryMovementRecord(m *models.InventoryMovement) []string {
+	return []string{
+		formatUint(m.ID),
+		formatUint(m.ProductID),
+		nullUint(m.OrderID),
+		m.MovementType,
+		strconv.Itoa(m.Quantity),
+		nullString(m.Note),
+		formatTime(m.MovementAt),
+		formatTime(m.CreatedAt),
+	}
+}
+
+// PageViewHeader page_views.csv 的表头
+var PageViewHeader = []string{"id", "session_id", "sequence", "anonymous_id", "user_id", "product_id", "order_id", "event_type", "page_url", "referrer", "device", "event_time", "created_at"}
+
+// PageViewRecord 将点击流事件转换为一行 CSV 记录
+func PageViewRecord(v *models.PageView) []string {
+	return []string{
+		formatUint(v.ID),
+		v.SessionID,
+		strconv.Itoa(v.Sequence),
+		v.AnonymousID,
+		nullUint(v.UserID),
+		nullUint(v.ProductID),
+		nullUint(v.OrderID),
+		v.EventType,
+		v.PageURL,
+		nullString(v.Referrer),
+		v.Device,
+		formatTime(v.EventTime),
+		formatTime(v.CreatedAt),
+	}
+}
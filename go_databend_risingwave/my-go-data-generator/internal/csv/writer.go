@@ -2,7 +2,9 @@ package csv
 
 import (
     "encoding/csv"
+    "log"
     "os"
+    "sync"
 )
 
 // WriteToCSV 将 data 写入指定路径的 CSV 文件中
@@ -23,4 +25,27 @@ func WriteToCSV(filePath string, data [][]string) error {
     }
 
     return nil
-}
\ No newline at end of file
+}
+
+// WriteConcurrently 从 records 通道持续读取记录并写入 filePath，通道关闭后刷新文件并通知 wg
+func WriteConcurrently(filePath string, records <-chan []string, wg *sync.WaitGroup) {
+    defer wg.Done()
+    file, err := os.Create(filePath)
+    if err != nil {
+        log.Printf("创建CSV文件 %s 失败: %v", filePath, err)
+        for range records {
+            // 继续消费通道，避免生产者阻塞
+        }
+        return
+    }
+    defer file.Close()
+
+    writer := csv.NewWriter(file)
+    defer writer.Flush()
+
+    for record := range records {
+        if err := writer.Write(record); err != nil {
+            log.Printf("写入CSV文件 %s 失败: %v", filePath, err)
+        }
+    }
+}
@@ -0,0 +1,388 @@
+// Package databend 由模型、表结构说明或 MySQL 中的实际表生成 Databend 的建表 DDL，用作 CDC 同步的目标表：
+// 列类型按 MySQL 类型映射为 Databend 类型，可空性与 MySQL 表一致，并按模型中的索引建议 CLUSTER BY 键
+package databend
+
+import (
+	"fmt"
+	"reflect"
+	"regexp"
+	"slices"
+	"strings"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"my-go-data-generator/internal/db"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/schemaspec"
+)
+
+// Options 生成 DDL 的选项
+type Options struct {
+	Normalized bool     // 规范化模式：不含以维度ID替代的文本列
+	History    []string // 另为这些表生成 *_history 历史表（ROW_HISTORY）
+}
+
+// Statements 按模型生成建表语句，顺序与 models 一致，历史表紧跟在其源表之后
+func Statements(all []interface{}, opts Options) ([]string, error) {
+	var stmts []string
+	for _, model := range all {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			return nil, err
+		}
+		var columns []string
+		for _, f := range s.Fields {
+			if f.DBName == "" || opts.Normalized && isNormalizedColumn(s.Table, f.DBName) {
+				continue
+			}
+			column, err := columnDef(f)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", s.Table, f.DBName, err)
+			}
+			columns = append(columns, column)
+		}
+		key, index := clusterKey(s)
+		stmts = append(stmts, createTable(s.Table, columns, key, index))
+
+		if slices.Contains(opts.History, s.Table) {
+			history := []string{"`history_id` BIGINT UNSIGNED NOT NULL"}
+			for _, c := range columns {
+				history = append(history, nullable(c))
+			}
+			history = append(history,
+				"`valid_from` TIMESTAMP NOT NULL",
+				"`valid_to` TIMESTAMP NOT NULL",
+				"`operation` VARCHAR NOT NULL",
+			)
+			stmts = append(stmts, createTable(s.Table+db.HistorySuffix, history, "id", "idx_history_row"))
+		}
+	}
+	return stmts, nil
+}
+
+// SpecStatements 按表结构说明（SCHEMA_SPEC）生成建表语句。说明中没有索引标签，因此不设置 CLUSTER BY；
+// 列的默认值不保留，其取值由 CDC 从 MySQL 同步
+func SpecStatements(tables []*schemaspec.Table) ([]string, error) {
+	stmts := make([]string, 0, len(tables))
+	for _, t := range tables {
+		columns := make([]string, 0, len(t.Columns))
+		for _, c := range t.Columns {
+			mysql := strings.ToLower(c.Type)
+			if len(c.Args) > 0 {
+				args := make([]string, len(c.Args))
+				for i, a := range c.Args {
+					args[i] = fmt.Sprint(a)
+				}
+				mysql += "(" + strings.Join(args, ",") + ")"
+			}
+			if c.Unsigned {
+				mysql += " unsigned"
+			}
+			typ, err := mysqlType(mysql)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", t.Name, c.Name, err)
+			}
+			columns = append(columns, column(c.Name, typ, c.NotNull, ""))
+		}
+		stmts = append(stmts, createTable(t.Name, columns, "", ""))
+	}
+	return stmts, nil
+}
+
+// LiveStatements 按 MySQL 中各表的实际列生成建表语句，反映 SCHEMA_EVOLUTION 等运行时的表结构变更；
+// tables 中不存在的表跳过。CLUSTER BY 键仍取自模型的索引（该列仍存在时），历史表按 id 聚簇
+func LiveStatements(conn *gorm.DB, all []interface{}, tables []string) ([]string, error) {
+	keys := make(map[string][2]string, len(all))
+	for _, model := range all {
+		s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{})
+		if err != nil {
+			return nil, err
+		}
+		if key, index := clusterKey(s); key != "" {
+			keys[s.Table] = [2]string{key, index}
+		}
+	}
+	m := conn.Migrator()
+	var stmts []string
+	for _, table := range tables {
+		if !m.HasTable(table) {
+			continue
+		}
+		types, err := m.ColumnTypes(table)
+		if err != nil {
+			return nil, err
+		}
+		columns := make([]string, 0, len(types))
+		present := make(map[string]bool, len(types))
+		for _, c := range types {
+			t, ok := c.ColumnType()
+			if !ok {
+				t = c.DatabaseTypeName()
+			}
+			typ, err := mysqlType(t)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%s: %w", table, c.Name(), err)
+			}
+			nullable, _ := c.Nullable()
+			def, _ := c.DefaultValue()
+			columns = append(columns, column(c.Name(), typ, !nullable, literal(typ, def)))
+			present[c.Name()] = true
+		}
+		key := keys[table]
+		if strings.HasSuffix(table, db.HistorySuffix) {
+			key = [2]string{"id", "idx_history_row"}
+		}
+		if !present[key[0]] {
+			key = [2]string{}
+		}
+		stmts = append(stmts, createTable(table, columns, key[0], key[1]))
+	}
+	return stmts, nil
+}
+
+// literal MySQL 列默认值在 Databend 中的写法：字符串加引号，数值原样，布尔值与表达式（如 CURRENT_TIMESTAMP）不保留
+func literal(typ, def string) string {
+	switch {
+	case def == "" || strings.EqualFold(def, "NULL"):
+		return ""
+	case typ == "VARCHAR":
+		return "'" + strings.ReplaceAll(def, "'", "''") + "'"
+	case typ != "BOOLEAN" && numberRe.MatchString(def):
+		return def
+	}
+	return ""
+}
+
+var numberRe = regexp.MustCompile(`^-?\d+(\.\d+)?$`)
+
+// Apply 在 Databend（MySQL 协议端口）上逐条执行建表语句
+func Apply(conn *gorm.DB, stmts []string) error {
+	for _, stmt := range stmts {
+		if err := conn.Exec(stmt).Error; err != nil {
+			return fmt.Errorf("%s: %w", firstLine(stmt), err)
+		}
+	}
+	return nil
+}
+
+func firstLine(stmt string) string {
+	for _, line := range strings.Split(stmt, "\n") {
+		if !strings.HasPrefix(line, "--") {
+			return line
+		}
+	}
+	return stmt
+}
+
+func isNormalizedColumn(table, column string) bool {
+	_, ok := models.NormalizedColumns[table][column]
+	return ok
+}
+
+// createTable 建表语句；key 非空时附加 CLUSTER BY，并注明取自哪个索引
+func createTable(table string, columns []string, key, index string) string {
+	var b strings.Builder
+	if key != "" {
+		fmt.Fprintf(&b, "-- CLUSTER BY 取自索引 %s\n", index)
+	}
+	fmt.Fprintf(&b, "CREATE TABLE IF NOT EXISTS `%s` (\n  %s\n)", table, strings.Join(columns, ",\n  "))
+	if key != "" {
+		fmt.Fprintf(&b, " CLUSTER BY (`%s`)", key)
+	}
+	return b.String()
+}
+
+// nullable 历史表中的列全部可为 NULL
+func nullable(column string) string {
+	column = strings.Replace(column, " NOT NULL", " NULL", 1)
+	if i := strings.Index(column, " DEFAULT "); i >= 0 {
+		column = column[:i]
+	}
+	return column
+}
+
+// column 一列的定义，defaultValue 为空表示没有默认值
+func column(name, typ string, notNull bool, defaultValue string) string {
+	def := fmt.Sprintf("`%s` %s", name, typ)
+	if notNull {
+		def += " NOT NULL"
+	} else {
+		def += " NULL"
+	}
+	if defaultValue != "" {
+		def += " DEFAULT " + defaultValue
+	}
+	return def
+}
+
+// columnDef 模型字段的定义。自增主键在 Databend 中为普通列，其值由 CDC 从 MySQL 同步
+func columnDef(f *schema.Field) (string, error) {
+	t, err := columnType(f)
+	if err != nil {
+		return "", err
+	}
+	def := ""
+	if f.HasDefaultValue && f.DefaultValue != "" && !f.AutoIncrement {
+		if f.DataType == schema.String && !strings.HasPrefix(f.DefaultValue, "'") {
+			def = fmt.Sprintf("'%s'", f.DefaultValue)
+		} else {
+			def = f.DefaultValue
+		}
+	}
+	return column(f.DBName, t, f.NotNull || f.PrimaryKey, def), nil
+}
+
+// dbDataType 自定义了 MySQL 列类型的字段，如 money.Amount
+type dbDataType interface {
+	GormDBDataType(*gorm.DB, *schema.Field) string
+}
+
+// columnType 字段对应的 Databend 类型：gorm 通用类型直接映射，其余按 MySQL 列类型映射
+func columnType(f *schema.Field) (string, error) {
+	if t, ok := reflect.New(f.IndirectFieldType).Interface().(dbDataType); ok {
+		return mysqlType(t.GormDBDataType(nil, f))
+	}
+	switch f.DataType {
+	case schema.Bool:
+		return "BOOLEAN", nil
+	case schema.Int, schema.Uint:
+		t := intType(f.Size)
+		if f.DataType == schema.Uint {
+			t += " UNSIGNED"
+		}
+		return t, nil
+	case schema.Float:
+		if f.Size == 32 {
+			return "FLOAT", nil
+		}
+		return "DOUBLE", nil
+	case schema.String:
+		return "VARCHAR", nil
+	case schema.Time:
+		return "TIMESTAMP", nil
+	case schema.Bytes:
+		return "BINARY", nil
+	}
+	return mysqlType(string(f.DataType))
+}
+
+func intType(bits int) string {
+	switch {
+	case bits <= 8:
+		return "TINYINT"
+	case bits <= 16:
+		return "SMALLINT"
+	case bits <= 32:
+		return "INT"
+	}
+	return "BIGINT"
+}
+
+// mysqlTypeRe 拆出 MySQL 列类型的类型名、参数与 UNSIGNED，忽略 NULL、GENERATED 等其余部分
+var mysqlTypeRe = regexp.MustCompile(`(?i)^\s*(\w+)\s*(?:\(([^)]*)\))?\s*(unsigned)?`)
+
+// mysqlType 将 MySQL 列类型映射为 Databend 类型。Databend 没有 TIME 与空间类型：
+// TIME 存为字符串，空间类型存为 WKB 二进制；生成列作为普通列，其值由 CDC 同步
+func mysqlType(t string) (string, error) {
+	m := mysqlTypeRe.FindStringSubmatch(t)
+	if m == nil {
+		return "", fmt.Errorf("无法识别的列类型 %q", t)
+	}
+	name, args, unsigned := strings.ToLower(m[1]), m[2], ""
+	if m[3] != "" {
+		unsigned = " UNSIGNED"
+	}
+	switch name {
+	case "bool", "boolean":
+		return "BOOLEAN", nil
+	case "tinyint":
+		if args == "1" {
+			return "BOOLEAN", nil
+		}
+		return "TINYINT" + unsigned, nil
+	case "smallint":
+		return "SMALLINT" + unsigned, nil
+	case "mediumint", "int", "integer":
+		return "INT" + unsigned, nil
+	case "bigint":
+		return "BIGINT" + unsigned, nil
+	case "bit":
+		return "BIGINT UNSIGNED", nil
+	case "year":
+		return "SMALLINT", nil
+	case "decimal", "numeric":
+		precision, scale := "10", "0"
+		if parts := strings.Split(args, ","); args != "" {
+			precision = strings.TrimSpace(parts[0])
+			if len(parts) > 1 {
+				scale = strings.TrimSpace(parts[1])
+			}
+		}
+		return fmt.Sprintf("DECIMAL(%s, %s)", precision, scale), nil
+	case "float":
+		return "FLOAT", nil
+	case "double", "real":
+		return "DOUBLE", nil
+	case "char", "varchar", "tinytext", "text", "mediumtext", "longtext", "enum", "set", "time":
+		return "VARCHAR", nil
+	case "json":
+		return "VARIANT", nil
+	case "binary", "varbinary", "tinyblob", "blob", "mediumblob", "longblob",
+		"point", "linestring", "polygon", "geometry", "multipoint", "multilinestring", "multipolygon", "geometrycollection":
+		return "BINARY", nil
+	case "date":
+		return "DATE", nil
+	case "datetime", "timestamp":
+		return "TIMESTAMP", nil
+	}
+	return "", fmt.Errorf("列类型 %q 没有对应的 Databend 类型", t)
+}
+
+// clusterKey 按模型中的索引建议 CLUSTER BY 键：优先取单列时间索引（非空列优先），
+// 其次取复合索引的首列（唯一索引优先）；都没有时不设置，小表与维度表不需要聚簇
+func clusterKey(s *schema.Schema) (column, index string) {
+	order := make(map[string]int, len(s.Fields))
+	for i, f := range s.Fields {
+		order[f.DBName] = i
+	}
+	type candidate struct {
+		rank, order int
+		column      string
+		index       string
+	}
+	var candidates []candidate
+	for _, idx := range s.ParseIndexes() {
+		if len(idx.Fields) == 0 || idx.Fields[0].Field == nil {
+			continue
+		}
+		f := idx.Fields[0].Field
+		rank := -1
+		switch {
+		case len(idx.Fields) == 1 && f.DataType == schema.Time && f.NotNull:
+			rank = 0
+		case len(idx.Fields) == 1 && f.DataType == schema.Time:
+			rank = 1
+		case len(idx.Fields) > 1 && idx.Class == "UNIQUE":
+			rank = 2
+		case len(idx.Fields) > 1:
+			rank = 3
+		}
+		if rank >= 0 {
+			candidates = append(candidates, candidate{rank, order[f.DBName], f.DBName, idx.Name})
+		}
+	}
+	if len(candidates) == 0 {
+		return "", ""
+	}
+	best := slices.MinFunc(candidates, func(a, b candidate) int {
+		if a.rank != b.rank {
+			return a.rank - b.rank
+		}
+		if a.order != b.order {
+			return a.order - b.order
+		}
+		return strings.Compare(a.index, b.index)
+	})
+	return best.column, best.index
+}
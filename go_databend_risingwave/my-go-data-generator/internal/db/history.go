@@ -0,0 +1,59 @@
+package db
+
+import (
+	"fmt"
+	"strings"
+
+	"gorm.io/gorm"
+)
+
+// HistoryTables 开启 ROW_HISTORY 时记录历史版本的表，历史表名为 表名_history
+var HistoryTables = []string{"users", "products", "orders"}
+
+// HistorySuffix 历史表名的后缀
+const HistorySuffix = "_history"
+
+// SyncHistoryTable 表结构变更计划改动源表后，将新增或改变类型的列同步到历史表；改名的列作为新列加入，原列保留以容纳旧版本。
+// 历史表本身由 0003_row_history 迁移创建，模型的改动须在迁移中同时修改源表与历史表
+func SyncHistoryTable(db *gorm.DB, table string) error {
+	m := db.Migrator()
+	history := table + HistorySuffix
+	if !m.HasTable(history) {
+		return fmt.Errorf("历史表 %s 不存在，请先执行迁移", history)
+	}
+	source, err := m.ColumnTypes(table)
+	if err != nil {
+		return err
+	}
+	existing, err := m.ColumnTypes(history)
+	if err != nil {
+		return err
+	}
+	types := make(map[string]string, len(existing))
+	for _, c := range existing {
+		t, _ := c.ColumnType()
+		types[c.Name()] = t
+	}
+	for _, c := range source {
+		t, _ := c.ColumnType()
+		switch current, ok := types[c.Name()]; {
+		case !ok:
+			err = db.Exec(fmt.Sprintf("ALTER TABLE %s ADD COLUMN %s", history, historyColumn(c))).Error
+		case !strings.EqualFold(current, t):
+			err = db.Exec(fmt.Sprintf("ALTER TABLE %s MODIFY COLUMN %s", history, historyColumn(c))).Error
+		}
+		if err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// historyColumn 历史表中与源表同名同类型、可为 NULL 的列定义
+func historyColumn(c gorm.ColumnType) string {
+	t, ok := c.ColumnType()
+	if !ok {
+		t = c.DatabaseTypeName()
+	}
+	return fmt.Sprintf("`%s` %s NULL", c.Name(), t)
+}
@@ -2,17 +2,56 @@ package db
 
 import (
 	"log"
+	"strings"
+	"time"
 
 	"gorm.io/gorm"
+	"my-go-data-generator/internal/config"
 	"my-go-data-generator/internal/models"
 )
 
-// Migrate 执行数据库迁移，自动创建或更新表结构
-func Migrate(db *gorm.DB) {
-	if err := db.AutoMigrate(&models.User{}, &models.Product{}, &models.Order{}); err != nil {
-		log.Fatalf("数据库迁移失败: %v", err)
+// tables 生成器写入的全部表，表结构由 migrations 中的版本化迁移创建，模型改动须同时新增一个迁移
+var tables = []interface{}{&models.User{}, &models.Product{}, &models.Order{}, &models.OrderItem{}, &models.Payment{}, &models.Refund{}, &models.ShipmentEvent{}, &models.Review{}, &models.InventoryMovement{}, &models.PageView{}}
+
+// dimensions 规范化模式下的维度表
+var dimensions = []interface{}{&models.Category{}, &models.Manufacturer{}, &models.Supplier{}, &models.Region{}, &models.PaymentMethod{}}
+
+// Models 按表结构模式返回生成器写入的全部模型，规范化模式下包括维度表
+func Models(mode string) []interface{} {
+	all := append([]interface{}{}, tables...)
+	if mode == config.SchemaNormalized {
+		all = append(all, dimensions...)
 	}
+	return all
+}
 
-	log.Println("数据库迁移成功")
-	// 模型中已定义了一些索引，如需定制可以在此继续追加
-}
\ No newline at end of file
+// Migrate 执行尚未执行的版本化迁移（见 migrations 目录），并检查已有表结构与表结构模式、金额列类型及模型是否一致；
+// 设置了分区粒度时，再将订单等按时间写入的表中仍为空的未分区表改为分区表。
+// 表结构与模型不一致时，strict（随后按模型写入数据）直接退出，以免写入时才因缺列失败，否则只打印警告：
+// 校验、分区维护与迁移本身不按模型写入，SCHEMA_EVOLUTION 执行过的变更留下的差异不应使它们无法运行
+func Migrate(db *gorm.DB, mode string, partitioning config.Partitioning, strict bool) {
+	if err := MigrateUp(db, mode); err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+	if err := checkSchemaMode(db, mode); err != nil {
+		log.Fatalf("数据库迁移失败: %v", err)
+	}
+	drift, err := schemaDrift(db, mode)
+	if err != nil {
+		log.Fatalf("检查表结构失败: %v", err)
+	}
+	if len(drift) > 0 {
+		list := strings.Join(drift, "\n  ")
+		if strict {
+			log.Fatalf("表结构与模型不一致，无法按模型写入。若差异来自 SCHEMA_EVOLUTION 已执行的变更或模型的改动尚无迁移，"+
+				"请先 -action migrate to 0 删除各表后重新迁移，或为模型的改动新增迁移：\n  %s", list)
+		}
+		log.Printf("警告：表结构与模型不一致（可能来自 SCHEMA_EVOLUTION 已执行的变更），继续执行：\n  %s", list)
+	}
+	if partitioning.Unit != "" {
+		if err := migratePartitioned(db, partitioning, time.Now()); err != nil {
+			log.Fatalf("创建分区表失败: %v", err)
+		}
+	}
+	log.Printf("数据库迁移成功（%s）", mode)
+}
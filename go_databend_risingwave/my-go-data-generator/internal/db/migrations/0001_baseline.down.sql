@@ -0,0 +1,16 @@
+-- 删除基线中的全部表（连同其中的数据）
+DROP TABLE IF EXISTS `payment_methods`;
+DROP TABLE IF EXISTS `regions`;
+DROP TABLE IF EXISTS `suppliers`;
+DROP TABLE IF EXISTS `manufacturers`;
+DROP TABLE IF EXISTS `categories`;
+DROP TABLE IF EXISTS `page_views`;
+DROP TABLE IF EXISTS `inventory_movements`;
+DROP TABLE IF EXISTS `reviews`;
+DROP TABLE IF EXISTS `shipment_events`;
+DROP TABLE IF EXISTS `refunds`;
+DROP TABLE IF EXISTS `payments`;
+DROP TABLE IF EXISTS `order_items`;
+DROP TABLE IF EXISTS `orders`;
+DROP TABLE IF EXISTS `products`;
+DROP TABLE IF EXISTS `users`;
@@ -0,0 +1,310 @@
+-- 基线：生成器写入的全部表，与引入版本化迁移时的模型一致。
+-- 使用 IF NOT EXISTS，以前由 AutoMigrate 建好的库执行本迁移时保留已有的表，只补建缺少的表。
+-- {{.Money}} 为 MONEY_TYPE 对应的金额列类型；规范化模式（.Normalized）下不建以维度ID替代的文本列，另建维度表
+
+CREATE TABLE IF NOT EXISTS `users` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `username` varchar(64) NOT NULL,
+  `gender` varchar(10) NOT NULL,
+  `age` bigint NOT NULL,
+  `email` varchar(128) NOT NULL,
+  `phone` varchar(20) NOT NULL,
+  `address` varchar(256) NOT NULL,
+  {{- if not .Normalized}}
+  `nationality` varchar(64) NOT NULL,
+  {{- end}}
+  `region_id` bigint unsigned,
+  `occupation` varchar(64) NOT NULL,
+  `marital_status` varchar(16) NOT NULL,
+  `education` varchar(64) NOT NULL,
+  `hobby` varchar(128),
+  `income` {{.Money}} NOT NULL,
+  `registration_date` datetime(3) NOT NULL,
+  `last_login` datetime(3) NOT NULL,
+  `loyalty_points` bigint NOT NULL,
+  `preferred_language` varchar(32) NOT NULL,
+  `currency` varchar(8) NOT NULL,
+  `timezone` varchar(32) NOT NULL,
+  `status` varchar(16) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_username` (`username`),
+  INDEX `idx_gender` (`gender`),
+  UNIQUE INDEX `idx_email` (`email`),
+  UNIQUE INDEX `idx_phone` (`phone`),
+  INDEX `idx_user_regionid` (`region_id`),
+  INDEX `idx_marital_status` (`marital_status`),
+  INDEX `idx_registration_date` (`registration_date`),
+  INDEX `idx_status` (`status`),
+  INDEX `idx_user_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `products` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `product_name` varchar(128) NOT NULL,
+  {{- if not .Normalized}}
+  `category` varchar(64) NOT NULL,
+  {{- end}}
+  `category_id` bigint unsigned,
+  `description` text,
+  `price` {{.Money}} NOT NULL,
+  `stock` bigint NOT NULL,
+  `sku` varchar(64) NOT NULL,
+  {{- if not .Normalized}}
+  `manufacturer` varchar(128) NOT NULL,
+  {{- end}}
+  `manufacturer_id` bigint unsigned,
+  `weight` double NOT NULL,
+  `dimensions` varchar(64) NOT NULL,
+  `color` varchar(32) NOT NULL,
+  `material` varchar(64) NOT NULL,
+  `release_date` datetime(3) NOT NULL,
+  `warranty_period` varchar(32),
+  {{- if not .Normalized}}
+  `country_of_origin` varchar(64) NOT NULL,
+  {{- end}}
+  `origin_region_id` bigint unsigned,
+  `rating` double NOT NULL,
+  `number_of_reviews` bigint NOT NULL,
+  `discount` double NOT NULL,
+  `stock_status` varchar(32) NOT NULL,
+  {{- if not .Normalized}}
+  `supplier` varchar(128) NOT NULL,
+  {{- end}}
+  `supplier_id` bigint unsigned,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_productname` (`product_name`),
+  {{- if not .Normalized}}
+  INDEX `idx_category` (`category`),
+  {{- end}}
+  INDEX `idx_categoryid` (`category_id`),
+  UNIQUE INDEX `idx_sku` (`sku`),
+  INDEX `idx_manufacturerid` (`manufacturer_id`),
+  INDEX `idx_origin_regionid` (`origin_region_id`),
+  INDEX `idx_rating` (`rating`),
+  INDEX `idx_stock_status` (`stock_status`),
+  INDEX `idx_supplierid` (`supplier_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `orders` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `order_number` varchar(64) NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `product_id` bigint unsigned NOT NULL,
+  `order_date` datetime(3) NOT NULL,
+  `quantity` bigint NOT NULL,
+  `currency` varchar(8) NOT NULL DEFAULT 'CNY',
+  `subtotal` {{.Money}} NOT NULL,
+  `total_amount` {{.Money}} NOT NULL,
+  {{- if not .Normalized}}
+  `payment_method` varchar(32) NOT NULL,
+  {{- end}}
+  `payment_method_id` bigint unsigned,
+  `shipping_address` varchar(256) NOT NULL,
+  `billing_address` varchar(256) NOT NULL,
+  `order_status` varchar(32) NOT NULL,
+  `discount_amount` {{.Money}} NOT NULL,
+  `tax_amount` {{.Money}} NOT NULL,
+  `shipping_cost` {{.Money}} NOT NULL,
+  `tracking_number` varchar(64),
+  `shipped_at` datetime(3) NULL,
+  `delivery_date` datetime(3) NULL,
+  `return_status` varchar(32),
+  `customer_note` text,
+  `internal_note` text,
+  `is_gift` boolean NOT NULL,
+  `gift_message` text,
+  `extra_info` text,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_ordernumber` (`order_number`),
+  INDEX `idx_userid` (`user_id`),
+  INDEX `idx_productid` (`product_id`),
+  INDEX `idx_order_date` (`order_date`),
+  INDEX `idx_payment_methodid` (`payment_method_id`),
+  INDEX `idx_order_status` (`order_status`),
+  INDEX `idx_tracking_number` (`tracking_number`),
+  INDEX `idx_delivery_date` (`delivery_date`),
+  INDEX `idx_return_status` (`return_status`),
+  INDEX `idx_order_deleted_at` (`deleted_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `order_items` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` bigint unsigned NOT NULL,
+  `line_number` bigint NOT NULL,
+  `product_id` bigint unsigned NOT NULL,
+  `quantity` bigint NOT NULL,
+  `currency` varchar(8) NOT NULL DEFAULT 'CNY',
+  `unit_price` {{.Money}} NOT NULL,
+  `subtotal` {{.Money}} NOT NULL,
+  `discount_amount` {{.Money}} NOT NULL,
+  `tax_amount` {{.Money}} NOT NULL,
+  `line_total` {{.Money}} NOT NULL,
+  `created_at` datetime(3) NULL,
+  `line_number`),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_order_line` (`order_id`,
+  INDEX `idx_item_productid` (`product_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `payments` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` bigint unsigned NOT NULL,
+  `attempt_number` bigint NOT NULL,
+  {{- if not .Normalized}}
+  `method` varchar(32) NOT NULL,
+  {{- end}}
+  `payment_method_id` bigint unsigned,
+  `provider` varchar(32) NOT NULL,
+  `transaction_id` varchar(64),
+  `status` varchar(16) NOT NULL,
+  `amount` {{.Money}} NOT NULL,
+  `currency` varchar(8) NOT NULL DEFAULT 'CNY',
+  `failure_reason` varchar(128),
+  `paid_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  `attempt_number`),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_order_attempt` (`order_id`,
+  INDEX `idx_transaction_id` (`transaction_id`),
+  INDEX `idx_payment_status` (`status`),
+  INDEX `idx_paid_at` (`paid_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `refunds` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` bigint unsigned NOT NULL,
+  `payment_id` bigint unsigned NOT NULL,
+  `amount` {{.Money}} NOT NULL,
+  `currency` varchar(8) NOT NULL DEFAULT 'CNY',
+  `status` varchar(16) NOT NULL,
+  `requested_at` datetime(3) NOT NULL,
+  `refunded_at` datetime(3) NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_refund_orderid` (`order_id`),
+  INDEX `idx_refund_paymentid` (`payment_id`),
+  INDEX `idx_refund_status` (`status`)
+);
+
+CREATE TABLE IF NOT EXISTS `shipment_events` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `order_id` bigint unsigned NOT NULL,
+  `sequence` bigint NOT NULL,
+  `tracking_number` varchar(64) NOT NULL,
+  `carrier` varchar(32) NOT NULL,
+  `event_type` varchar(16) NOT NULL,
+  `location` varchar(64) NOT NULL,
+  `description` varchar(256) NOT NULL,
+  `event_time` datetime(3) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `sequence`),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_order_event` (`order_id`,
+  INDEX `idx_event_tracking` (`tracking_number`),
+  INDEX `idx_event_type` (`event_type`),
+  INDEX `idx_event_time` (`event_time`)
+);
+
+CREATE TABLE IF NOT EXISTS `reviews` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` bigint unsigned NOT NULL,
+  `user_id` bigint unsigned NOT NULL,
+  `order_id` bigint unsigned NOT NULL,
+  `stars` bigint NOT NULL,
+  `content` text,
+  `created_at` datetime(3) NULL,
+  `order_id`),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_review_order_product` (`product_id`,
+  INDEX `idx_review_productid` (`product_id`),
+  INDEX `idx_review_userid` (`user_id`),
+  INDEX `idx_review_created_at` (`created_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `inventory_movements` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `product_id` bigint unsigned NOT NULL,
+  `order_id` bigint unsigned,
+  `movement_type` varchar(16) NOT NULL,
+  `quantity` bigint NOT NULL,
+  `note` varchar(128),
+  `movement_at` datetime(3) NOT NULL,
+  `created_at` datetime(3) NULL,
+  PRIMARY KEY (`id`),
+  INDEX `idx_movement_productid` (`product_id`),
+  INDEX `idx_movement_orderid` (`order_id`),
+  INDEX `idx_movement_type` (`movement_type`),
+  INDEX `idx_movement_at` (`movement_at`)
+);
+
+CREATE TABLE IF NOT EXISTS `page_views` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `session_id` varchar(32) NOT NULL,
+  `sequence` bigint NOT NULL,
+  `anonymous_id` varchar(32) NOT NULL,
+  `user_id` bigint unsigned,
+  `product_id` bigint unsigned,
+  `order_id` bigint unsigned,
+  `event_type` varchar(16) NOT NULL,
+  `page_url` varchar(255) NOT NULL,
+  `referrer` varchar(255),
+  `device` varchar(16) NOT NULL,
+  `event_time` datetime(3) NOT NULL,
+  `created_at` datetime(3) NULL,
+  `sequence`),
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_pv_session_seq` (`session_id`,
+  INDEX `idx_pv_anonymousid` (`anonymous_id`),
+  INDEX `idx_pv_userid` (`user_id`),
+  INDEX `idx_pv_productid` (`product_id`),
+  INDEX `idx_pv_orderid` (`order_id`),
+  INDEX `idx_pv_eventtype` (`event_type`),
+  INDEX `idx_pv_eventtime` (`event_time`)
+);
+
+{{if .Normalized -}}
+CREATE TABLE IF NOT EXISTS `categories` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_category_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `manufacturers` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(128) NOT NULL,
+  `region_id` bigint unsigned,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_manufacturer_name` (`name`),
+  INDEX `idx_manufacturer_regionid` (`region_id`)
+);
+
+CREATE TABLE IF NOT EXISTS `suppliers` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(128) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_supplier_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `regions` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(64) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_region_name` (`name`)
+);
+
+CREATE TABLE IF NOT EXISTS `payment_methods` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `name` varchar(32) NOT NULL,
+  PRIMARY KEY (`id`),
+  UNIQUE INDEX `idx_payment_method_name` (`name`)
+);
+{{- end}}
@@ -0,0 +1,2 @@
+-- 删除 type_zoo（连同其中的数据）
+DROP TABLE IF EXISTS `type_zoo`;
@@ -0,0 +1,42 @@
+-- type_zoo 类型覆盖表：覆盖其他模型没有用到的 MySQL 类型，TYPE_ZOO_ROWS > 0 时写入数据，否则为空表。
+-- 以前由 AutoMigrate 建好的 type_zoo 保持原样
+
+CREATE TABLE IF NOT EXISTS `type_zoo` (
+  `id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `decimal_small` decimal(5,2),
+  `decimal_wide` decimal(65,30),
+  `tiny_int` tinyint,
+  `tiny_int_unsigned` tinyint unsigned,
+  `small_int` smallint,
+  `medium_int` mediumint,
+  `int_unsigned` int unsigned,
+  `big_int` bigint,
+  `big_int_unsigned` bigint unsigned,
+  `float_col` float,
+  `double_col` double,
+  `bit_col` bit(64),
+  `char_col` char(10),
+  `varchar_col` varchar(255),
+  `text_col` mediumtext,
+  `enum_col` enum('','小','中','大'),
+  `set_col` set('a','b','c','d'),
+  `json_col` json,
+  `binary_col` binary(16),
+  `varbinary_col` varbinary(255),
+  `blob_col` blob,
+  `date_col` date,
+  `time_col` time(6),
+  `datetime_col` datetime(6),
+  `timestamp_col` timestamp(6) NULL,
+  `year_col` year,
+  `point_col` point,
+  `line_col` linestring,
+  `polygon_col` polygon,
+  `geometry_col` geometry,
+  `gen_sum` bigint GENERATED ALWAYS AS (small_int + medium_int) STORED,
+  `gen_label` varchar(64) GENERATED ALWAYS AS (concat_ws('-', enum_col, year_col)) VIRTUAL,
+  `gen_json_key` varchar(255) GENERATED ALWAYS AS (json_unquote(json_extract(json_col, '$.key'))) VIRTUAL,
+  `created_at` datetime(6),
+  `updated_at` datetime(6),
+  PRIMARY KEY (`id`)
+);
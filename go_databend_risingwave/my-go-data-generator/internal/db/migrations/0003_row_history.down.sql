@@ -0,0 +1,4 @@
+-- 删除历史表（连同其中的历史版本）
+DROP TABLE IF EXISTS `orders_history`;
+DROP TABLE IF EXISTS `products_history`;
+DROP TABLE IF EXISTS `users_history`;
@@ -0,0 +1,121 @@
+-- ROW_HISTORY 的历史表：users、products 与 orders 各一张 *_history，在每次定时更新或删除前记录行的原版本。
+-- 列与源表同名同类型，全部可为 NULL，另有自增主键 history_id、版本有效期 valid_from/valid_to 与变更类型 operation。
+-- 未开启 ROW_HISTORY 时历史表为空；以前在迁移时按源表建好的历史表保持原样
+
+CREATE TABLE IF NOT EXISTS `users_history` (
+  `history_id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `id` bigint unsigned NULL,
+  `username` varchar(64) NULL,
+  `gender` varchar(10) NULL,
+  `age` bigint NULL,
+  `email` varchar(128) NULL,
+  `phone` varchar(20) NULL,
+  `address` varchar(256) NULL,
+  {{- if not .Normalized}}
+  `nationality` varchar(64) NULL,
+  {{- end}}
+  `region_id` bigint unsigned NULL,
+  `occupation` varchar(64) NULL,
+  `marital_status` varchar(16) NULL,
+  `education` varchar(64) NULL,
+  `hobby` varchar(128) NULL,
+  `income` {{.Money}} NULL,
+  `registration_date` datetime(3) NULL,
+  `last_login` datetime(3) NULL,
+  `loyalty_points` bigint NULL,
+  `preferred_language` varchar(32) NULL,
+  `currency` varchar(8) NULL,
+  `timezone` varchar(32) NULL,
+  `status` varchar(16) NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `valid_from` datetime(3) NOT NULL,
+  `valid_to` datetime(3) NOT NULL,
+  `operation` varchar(8) NOT NULL,
+  PRIMARY KEY (`history_id`),
+  INDEX `idx_history_row` (`id`, `valid_from`)
+);
+
+CREATE TABLE IF NOT EXISTS `products_history` (
+  `history_id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `id` bigint unsigned NULL,
+  `product_name` varchar(128) NULL,
+  {{- if not .Normalized}}
+  `category` varchar(64) NULL,
+  {{- end}}
+  `category_id` bigint unsigned NULL,
+  `description` text NULL,
+  `price` {{.Money}} NULL,
+  `stock` bigint NULL,
+  `sku` varchar(64) NULL,
+  {{- if not .Normalized}}
+  `manufacturer` varchar(128) NULL,
+  {{- end}}
+  `manufacturer_id` bigint unsigned NULL,
+  `weight` double NULL,
+  `dimensions` varchar(64) NULL,
+  `color` varchar(32) NULL,
+  `material` varchar(64) NULL,
+  `release_date` datetime(3) NULL,
+  `warranty_period` varchar(32) NULL,
+  {{- if not .Normalized}}
+  `country_of_origin` varchar(64) NULL,
+  {{- end}}
+  `origin_region_id` bigint unsigned NULL,
+  `rating` double NULL,
+  `number_of_reviews` bigint NULL,
+  `discount` double NULL,
+  `stock_status` varchar(32) NULL,
+  {{- if not .Normalized}}
+  `supplier` varchar(128) NULL,
+  {{- end}}
+  `supplier_id` bigint unsigned NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `valid_from` datetime(3) NOT NULL,
+  `valid_to` datetime(3) NOT NULL,
+  `operation` varchar(8) NOT NULL,
+  PRIMARY KEY (`history_id`),
+  INDEX `idx_history_row` (`id`, `valid_from`)
+);
+
+CREATE TABLE IF NOT EXISTS `orders_history` (
+  `history_id` bigint unsigned NOT NULL AUTO_INCREMENT,
+  `id` bigint unsigned NULL,
+  `order_number` varchar(64) NULL,
+  `user_id` bigint unsigned NULL,
+  `product_id` bigint unsigned NULL,
+  `order_date` datetime(3) NULL,
+  `quantity` bigint NULL,
+  `currency` varchar(8) NULL,
+  `subtotal` {{.Money}} NULL,
+  `total_amount` {{.Money}} NULL,
+  {{- if not .Normalized}}
+  `payment_method` varchar(32) NULL,
+  {{- end}}
+  `payment_method_id` bigint unsigned NULL,
+  `shipping_address` varchar(256) NULL,
+  `billing_address` varchar(256) NULL,
+  `order_status` varchar(32) NULL,
+  `discount_amount` {{.Money}} NULL,
+  `tax_amount` {{.Money}} NULL,
+  `shipping_cost` {{.Money}} NULL,
+  `tracking_number` varchar(64) NULL,
+  `shipped_at` datetime(3) NULL,
+  `delivery_date` datetime(3) NULL,
+  `return_status` varchar(32) NULL,
+  `customer_note` text NULL,
+  `internal_note` text NULL,
+  `is_gift` boolean NULL,
+  `gift_message` text NULL,
+  `extra_info` text NULL,
+  `created_at` datetime(3) NULL,
+  `updated_at` datetime(3) NULL,
+  `deleted_at` datetime(3) NULL,
+  `valid_from` datetime(3) NOT NULL,
+  `valid_to` datetime(3) NOT NULL,
+  `operation` varchar(8) NOT NULL,
+  PRIMARY KEY (`history_id`),
+  INDEX `idx_history_row` (`id`, `valid_from`)
+);
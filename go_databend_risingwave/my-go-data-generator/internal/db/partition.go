@@ -0,0 +1,253 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+	"my-go-data-generator/internal/config"
+	"my-go-data-generator/internal/models"
+)
+
+// partitionedTables 开启 PARTITION_BY 时按时间列 RANGE 分区的表及其分区列。
+// 库存流水不分区：删除过期分区会丢掉流水，产品库存将不再等于其流水之和
+var partitionedTables = []struct {
+	model  interface{}
+	column string
+}{
+	{&models.Order{}, "order_date"},
+	{&models.ShipmentEvent{}, "event_time"},
+	{&models.PageView{}, "event_time"},
+}
+
+// partitionHistory 建表时分区至少覆盖的历史跨度，与批量数据的时间跨度一致（已完成订单最早在 180 天前）
+const partitionHistory = 180 * 24 * time.Hour
+
+// maxPartition 兜底分区，容纳晚于最后一个周期分区上界的行
+const maxPartition = "pmax"
+
+// periodStart t 所在分区周期的起点
+func periodStart(unit string, t time.Time) time.Time {
+	y, m, d := t.Date()
+	if unit == config.PartitionMonth {
+		d = 1
+	}
+	return time.Date(y, m, d, 0, 0, 0, 0, t.Location())
+}
+
+// addPeriods 将周期起点向后（n 为负时向前）移动 n 个周期
+func addPeriods(unit string, t time.Time, n int) time.Time {
+	if unit == config.PartitionMonth {
+		return t.AddDate(0, n, 0)
+	}
+	return t.AddDate(0, 0, n)
+}
+
+// partitionDef 以周期起点命名的分区定义，如 p202611 或 p20261105，上界为下一周期的起点
+func partitionDef(unit string, start time.Time) string {
+	layout := "20060102"
+	if unit == config.PartitionMonth {
+		layout = "200601"
+	}
+	return fmt.Sprintf("PARTITION p%s VALUES LESS THAN ('%s')", start.Format(layout), addPeriods(unit, start, 1).Format("2006-01-02"))
+}
+
+// partitionDefs 从 from 所在周期到 to 所在周期（不含）的分区定义
+func partitionDefs(unit string, from, to time.Time) []string {
+	var defs []string
+	for s := periodStart(unit, from); s.Before(to); s = addPeriods(unit, s, 1) {
+		defs = append(defs, partitionDef(unit, s))
+	}
+	return defs
+}
+
+// maxPartitionDef 兜底分区的定义
+func maxPartitionDef(name string) string {
+	return fmt.Sprintf("PARTITION %s VALUES LESS THAN (MAXVALUE)", name)
+}
+
+// RetentionStart 保留期的起点：上界不晚于它的分区会被 MaintainPartitions 删除；未设置 PARTITION_RETENTION 时为零值
+func RetentionStart(p config.Partitioning, now time.Time) time.Time {
+	if p.Unit == "" || p.Retention <= 0 {
+		return time.Time{}
+	}
+	return addPeriods(p.Unit, periodStart(p.Unit, now), -p.Retention)
+}
+
+// partition 已有分区的名称与上界
+type partition struct {
+	name  string
+	bound time.Time // 上界（不含）；MAXVALUE 分区为零值
+}
+
+// partitions 按顺序返回表的分区，未分区的表返回空
+func partitions(db *gorm.DB, table string) ([]partition, error) {
+	var rows []struct {
+		Name        string `gorm:"column:PARTITION_NAME"`
+		Description string `gorm:"column:PARTITION_DESCRIPTION"`
+	}
+	err := db.Raw(`SELECT PARTITION_NAME, PARTITION_DESCRIPTION FROM information_schema.PARTITIONS
+		WHERE TABLE_SCHEMA = DATABASE() AND TABLE_NAME = ? AND PARTITION_NAME IS NOT NULL
+		ORDER BY PARTITION_ORDINAL_POSITION`, table).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]partition, len(rows))
+	for i, r := range rows {
+		out[i].name = r.Name
+		desc := strings.Trim(r.Description, "'")
+		for _, layout := range []string{"2006-01-02 15:04:05", "2006-01-02"} {
+			if t, err := time.ParseInLocation(layout, desc, time.Local); err == nil {
+				out[i].bound = t
+				break
+			}
+		}
+	}
+	return out, nil
+}
+
+// RetainedSince 保留期内从属数据完整的最早下单时间，供 -action validate 跳过更早的订单；未设置 PARTITION_RETENTION 时为零值。
+// 保留期最早一个周期内的订单，其转化会话可能始于已删除的 page_views 分区，因此从下一个周期起算
+func RetainedSince(p config.Partitioning, now time.Time) time.Time {
+	start := RetentionStart(p, now)
+	if start.IsZero() {
+		return start
+	}
+	return addPeriods(p.Unit, start, 1)
+}
+
+// migratePartitioned 在版本化迁移建表之后，将仍为空的未分区表改为分区表：先把分区列加入主键与各唯一索引
+// （MySQL 要求分区列出现在每个唯一键中），再按 RANGE COLUMNS 划分从历史起点到未来 Ahead 个周期的分区，
+// 最后是容纳更晚日期的 pmax。历史起点取保留期起点与 180 天前中较早的一个，使批量生成的历史数据都有分区，
+// 超出保留期的部分在下一次维护时删除。已有数据的未分区表不做转换，只打印提示，以免对大表执行耗时的重建
+func migratePartitioned(db *gorm.DB, p config.Partitioning, now time.Time) error {
+	from := now.Add(-partitionHistory)
+	if start := RetentionStart(p, now); !start.IsZero() && start.Before(from) {
+		from = start
+	}
+	to := addPeriods(p.Unit, periodStart(p.Unit, now), p.Ahead+1)
+	for _, t := range partitionedTables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(t.model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+		parts, err := partitions(db, table)
+		if err != nil {
+			return err
+		}
+		if len(parts) > 0 {
+			continue
+		}
+		var rows int64
+		if err := db.Raw(fmt.Sprintf("SELECT COUNT(*) FROM (SELECT 1 FROM %s LIMIT 1) t", table)).Scan(&rows).Error; err != nil {
+			return err
+		}
+		if rows > 0 {
+			log.Printf("表 %s 已有数据且未分区，保持原样；如需分区请先删除该表再迁移", table)
+			continue
+		}
+		if err := db.Exec(fmt.Sprintf("ALTER TABLE %s %s", table, partitionKeys(stmt.Schema, t.column))).Error; err != nil {
+			return err
+		}
+		defs := append(partitionDefs(p.Unit, from, to), maxPartitionDef(maxPartition))
+		err = db.Exec(fmt.Sprintf("ALTER TABLE %s PARTITION BY RANGE COLUMNS(%s) (\n  %s\n)",
+			table, t.column, strings.Join(defs, ",\n  "))).Error
+		if err != nil {
+			return err
+		}
+		log.Printf("已创建分区表 %s（按 %s 每%s一个分区）", table, t.column, unitName(p.Unit))
+	}
+	return nil
+}
+
+// partitionKeys 将分区列加入主键与各唯一索引的 ALTER 子句。唯一索引加入分区列后只保证组合唯一，
+// 原索引列可能出现重复（如 order_number），因此逐个打印提示；订单号的唯一性由 -action validate 另行检查
+func partitionKeys(s *schema.Schema, column string) string {
+	keys := []string{fmt.Sprintf("DROP PRIMARY KEY, ADD PRIMARY KEY (`id`, `%s`)", column)}
+indexes:
+	for _, idx := range s.ParseIndexes() {
+		if idx.Class != "UNIQUE" {
+			continue
+		}
+		columns := make([]string, 0, len(idx.Fields)+1)
+		for _, f := range idx.Fields {
+			if f.DBName == column {
+				continue indexes
+			}
+			columns = append(columns, "`"+f.DBName+"`")
+		}
+		columns = append(columns, "`"+column+"`")
+		log.Printf("表 %s 的唯一索引 %s 加入分区列 %s 后为 (%s)，原有列不再单独保证唯一", s.Table, idx.Name, column, strings.Join(columns, ", "))
+		keys = append(keys, fmt.Sprintf("DROP INDEX `%s`, ADD UNIQUE INDEX `%s` (%s)", idx.Name, idx.Name, strings.Join(columns, ", ")))
+	}
+	return strings.Join(keys, ", ")
+}
+
+func unitName(unit string) string {
+	if unit == config.PartitionMonth {
+		return "月"
+	}
+	return "天"
+}
+
+// MaintainPartitions 维护各分区表：补齐到当前周期之后 Ahead 个周期的分区，并在设置了 Retention 时
+// 删除上界不晚于保留起点的分区（至少保留一个周期分区）。有 pmax 兜底分区的表以 REORGANIZE 从 pmax 中拆出新分区，
+// pmax 中已有的行随之移入对应分区。DROP PARTITION 直接丢弃整个分区的数据，不产生逐行的删除事件；
+// 被删除的订单留下的订单行、支付与评论等从属数据保持原样
+func MaintainPartitions(db *gorm.DB, p config.Partitioning, now time.Time) error {
+	if p.Unit == "" {
+		return nil
+	}
+	to := addPeriods(p.Unit, periodStart(p.Unit, now), p.Ahead+1)
+	cutoff := RetentionStart(p, now)
+	for _, t := range partitionedTables {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(t.model); err != nil {
+			return err
+		}
+		table := stmt.Schema.Table
+		parts, err := partitions(db, table)
+		if err != nil {
+			return err
+		}
+		// 周期分区在前，MAXVALUE 分区（如有）在最后
+		periods, catchAll := parts, ""
+		if n := len(parts); n > 0 && parts[n-1].bound.IsZero() {
+			periods, catchAll = parts[:n-1], parts[n-1].name
+		}
+		if len(periods) == 0 {
+			continue
+		}
+		if defs := partitionDefs(p.Unit, periods[len(periods)-1].bound, to); len(defs) > 0 {
+			alter := fmt.Sprintf("ALTER TABLE %s ADD PARTITION (\n  %s\n)", table, strings.Join(defs, ",\n  "))
+			if catchAll != "" {
+				alter = fmt.Sprintf("ALTER TABLE %s REORGANIZE PARTITION %s INTO (\n  %s,\n  %s\n)",
+					table, catchAll, strings.Join(defs, ",\n  "), maxPartitionDef(catchAll))
+			}
+			if err := db.Exec(alter).Error; err != nil {
+				return err
+			}
+			log.Printf("表 %s 新增分区 %d 个", table, len(defs))
+		}
+		if cutoff.IsZero() {
+			continue
+		}
+		var expired []string
+		for _, part := range periods[:len(periods)-1] {
+			if !part.bound.After(cutoff) {
+				expired = append(expired, part.name)
+			}
+		}
+		if len(expired) > 0 {
+			if err := db.Exec(fmt.Sprintf("ALTER TABLE %s DROP PARTITION %s", table, strings.Join(expired, ", "))).Error; err != nil {
+				return err
+			}
+			log.Printf("表 %s 删除过期分区 %s", table, strings.Join(expired, ", "))
+		}
+	}
+	return nil
+}
@@ -0,0 +1,55 @@
+package db
+
+import (
+	"fmt"
+	"log"
+	"strings"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/config"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+)
+
+// UseSchemaMode 按表结构模式调整写入：规范化模式下插入时忽略以维度ID替代的文本列
+func UseSchemaMode(db *gorm.DB, mode string) {
+	if mode != config.SchemaNormalized {
+		return
+	}
+	err := db.Callback().Create().Before("gorm:create").Register("schema_mode:omit_text_columns", func(tx *gorm.DB) {
+		if tx.Statement.Schema == nil {
+			return
+		}
+		for column := range models.NormalizedColumns[tx.Statement.Schema.Table] {
+			tx.Statement.Omits = append(tx.Statement.Omits, column)
+		}
+	})
+	if err != nil {
+		log.Fatalf("注册表结构模式回调失败: %v", err)
+	}
+}
+
+// normalizedColumn 规范化模式下 column 是否为 table 中以维度ID替代、不建的文本列
+func normalizedColumn(mode, table, column string) bool {
+	_, ok := models.NormalizedColumns[table][column]
+	return ok && mode == config.SchemaNormalized
+}
+
+// checkSchemaMode 检查已迁移的表结构与当前的表结构模式、金额列类型是否一致。
+// 基线迁移按执行时的配置建表，之后切换 SCHEMA_MODE 或 MONEY_TYPE 不会改动已有的表
+func checkSchemaMode(db *gorm.DB, mode string) error {
+	m := db.Migrator()
+	if hasText := m.HasColumn(&models.User{}, "nationality"); hasText == (mode == config.SchemaNormalized) {
+		return fmt.Errorf("现有表结构与 SCHEMA_MODE=%s 不一致，请先 -action migrate to 0 删除各表后重新迁移", mode)
+	}
+	types, err := m.ColumnTypes(&models.Order{})
+	if err != nil {
+		return err
+	}
+	for _, c := range types {
+		if t, _ := c.ColumnType(); c.Name() == "total_amount" && !strings.EqualFold(t, money.ColumnType()) {
+			return fmt.Errorf("现有金额列类型 %s 与 MONEY_TYPE 不一致（%s），请先 -action migrate to 0 删除各表后重新迁移", t, money.ColumnType())
+		}
+	}
+	return nil
+}
@@ -0,0 +1,319 @@
+package db
+
+import (
+	"bytes"
+	"embed"
+	"fmt"
+	"io/fs"
+	"log"
+	"regexp"
+	"sort"
+	"strconv"
+	"strings"
+	"text/template"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/config"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+)
+
+// migrationFiles 编入二进制的版本化迁移，文件名为 <版本>_<名称>.up.sql 与 <版本>_<名称>.down.sql
+//
+//go:embed migrations/*.sql
+var migrationFiles embed.FS
+
+// MigrationsTable 记录已执行迁移版本的表
+const MigrationsTable = "schema_migrations"
+
+// Migration 一个版本的迁移
+type Migration struct {
+	Version int
+	Name    string
+	Up      string // 升级语句模板
+	Down    string // 回退语句模板，为空表示不可回退
+}
+
+// MigrationState 一个版本的执行状态
+type MigrationState struct {
+	Migration
+	AppliedAt *time.Time // 执行时间，未执行时为 nil
+}
+
+// MigrationReport 迁移状态：各版本的执行情况，以及模型与数据库中实际列的差异
+type MigrationReport struct {
+	Versions []MigrationState
+	Current  int      // 已执行的最高版本
+	Drift    []string // 模型与表结构不一致之处，非空时说明模型的改动还没有对应的迁移
+}
+
+var migrationNameRe = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// loadMigrations 读取编入的迁移文件，按版本排序；同一版本必须有 up 文件
+func loadMigrations() ([]Migration, error) {
+	entries, err := fs.ReadDir(migrationFiles, "migrations")
+	if err != nil {
+		return nil, err
+	}
+	byVersion := map[int]*Migration{}
+	for _, e := range entries {
+		m := migrationNameRe.FindStringSubmatch(e.Name())
+		if m == nil {
+			return nil, fmt.Errorf("迁移文件名 %s 不符合 <版本>_<名称>.up|down.sql", e.Name())
+		}
+		version, _ := strconv.Atoi(m[1])
+		content, err := migrationFiles.ReadFile("migrations/" + e.Name())
+		if err != nil {
+			return nil, err
+		}
+		mig := byVersion[version]
+		if mig == nil {
+			mig = &Migration{Version: version, Name: m[2]}
+			byVersion[version] = mig
+		} else if mig.Name != m[2] {
+			return nil, fmt.Errorf("版本 %d 有两个名称：%s 与 %s", version, mig.Name, m[2])
+		}
+		if m[3] == "up" {
+			mig.Up = string(content)
+		} else {
+			mig.Down = string(content)
+		}
+	}
+	migrations := make([]Migration, 0, len(byVersion))
+	for _, mig := range byVersion {
+		if mig.Up == "" {
+			return nil, fmt.Errorf("版本 %d 缺少 up 文件", mig.Version)
+		}
+		migrations = append(migrations, *mig)
+	}
+	sort.Slice(migrations, func(i, j int) bool { return migrations[i].Version < migrations[j].Version })
+	return migrations, nil
+}
+
+// migrationData 迁移模板的参数：金额列类型与是否规范化模式
+type migrationData struct {
+	Money      string
+	Normalized bool
+}
+
+// statements 按表结构模式与金额列类型渲染迁移模板，并按行尾的分号拆分为语句；只有注释的行被丢弃
+func statements(name, text, mode string) ([]string, error) {
+	tmpl, err := template.New(name).Option("missingkey=error").Parse(text)
+	if err != nil {
+		return nil, err
+	}
+	var buf bytes.Buffer
+	if err := tmpl.Execute(&buf, migrationData{Money: money.ColumnType(), Normalized: mode == config.SchemaNormalized}); err != nil {
+		return nil, err
+	}
+	var (
+		stmts   []string
+		current strings.Builder
+	)
+	for _, line := range strings.Split(buf.String(), "\n") {
+		trimmed := strings.TrimSpace(line)
+		if trimmed == "" || strings.HasPrefix(trimmed, "--") {
+			continue
+		}
+		current.WriteString(line)
+		current.WriteByte('\n')
+		if strings.HasSuffix(trimmed, ";") {
+			stmts = append(stmts, strings.TrimSuffix(strings.TrimSpace(current.String()), ";"))
+			current.Reset()
+		}
+	}
+	if s := strings.TrimSpace(current.String()); s != "" {
+		stmts = append(stmts, s)
+	}
+	return stmts, nil
+}
+
+// appliedVersions 已执行的版本及其执行时间，必要时先创建 schema_migrations
+func appliedVersions(db *gorm.DB) (map[int]time.Time, error) {
+	err := db.Exec("CREATE TABLE IF NOT EXISTS " + MigrationsTable + ` (
+  version bigint NOT NULL,
+  name varchar(255) NOT NULL,
+  applied_at datetime(3) NOT NULL,
+  PRIMARY KEY (version)
+)`).Error
+	if err != nil {
+		return nil, err
+	}
+	var rows []struct {
+		Version   int
+		AppliedAt time.Time
+	}
+	if err := db.Table(MigrationsTable).Select("version, applied_at").Scan(&rows).Error; err != nil {
+		return nil, err
+	}
+	applied := make(map[int]time.Time, len(rows))
+	for _, r := range rows {
+		applied[r.Version] = r.AppliedAt
+	}
+	return applied, nil
+}
+
+// runMigration 执行一个版本的 up 或 down，成功后在 schema_migrations 中登记或注销该版本。
+// MySQL 的 DDL 会隐式提交，语句中途失败时之前的语句已生效，须按报错手工修复后再重试
+func runMigration(db *gorm.DB, mig Migration, up bool, mode string) error {
+	text, direction := mig.Up, "up"
+	if !up {
+		text, direction = mig.Down, "down"
+	}
+	if text == "" {
+		return fmt.Errorf("版本 %d_%s 没有 down 迁移，不能回退", mig.Version, mig.Name)
+	}
+	stmts, err := statements(fmt.Sprintf("%04d_%s.%s", mig.Version, mig.Name, direction), text, mode)
+	if err != nil {
+		return err
+	}
+	for i, s := range stmts {
+		if err := db.Exec(s).Error; err != nil {
+			return fmt.Errorf("版本 %d_%s %s 第 %d 条语句失败: %w", mig.Version, mig.Name, direction, i+1, err)
+		}
+	}
+	if up {
+		err = db.Exec("INSERT INTO "+MigrationsTable+" (version, name, applied_at) VALUES (?, ?, ?)", mig.Version, mig.Name, time.Now()).Error
+	} else {
+		err = db.Exec("DELETE FROM "+MigrationsTable+" WHERE version = ?", mig.Version).Error
+	}
+	if err != nil {
+		return err
+	}
+	log.Printf("迁移 %04d_%s %s 完成", mig.Version, mig.Name, direction)
+	return nil
+}
+
+// MigrateTo 升级或回退到 target 版本：依次执行 target 及之前尚未执行的 up，再从高到低回退 target 之后已执行的版本
+func MigrateTo(db *gorm.DB, mode string, target int) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+	for _, mig := range migrations {
+		if _, ok := applied[mig.Version]; ok || mig.Version > target {
+			continue
+		}
+		if err := runMigration(db, mig, true, mode); err != nil {
+			return err
+		}
+	}
+	for i := len(migrations) - 1; i >= 0; i-- {
+		mig := migrations[i]
+		if _, ok := applied[mig.Version]; !ok || mig.Version <= target {
+			continue
+		}
+		if err := runMigration(db, mig, false, mode); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// MigrateUp 执行全部尚未执行的迁移
+func MigrateUp(db *gorm.DB, mode string) error {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return err
+	}
+	if len(migrations) == 0 {
+		return nil
+	}
+	return MigrateTo(db, mode, migrations[len(migrations)-1].Version)
+}
+
+// MigrateDown 从最高版本起回退 n 个已执行的版本
+func MigrateDown(db *gorm.DB, mode string, n int) error {
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return err
+	}
+	versions := make([]int, 0, len(applied))
+	for v := range applied {
+		versions = append(versions, v)
+	}
+	sort.Sort(sort.Reverse(sort.IntSlice(versions)))
+	if n <= 0 || len(versions) == 0 {
+		return nil
+	}
+	target := 0
+	if n < len(versions) {
+		target = versions[n]
+	}
+	return MigrateTo(db, mode, target)
+}
+
+// MigrationStatus 各版本的执行情况，并比较模型与数据库中的实际列
+func MigrationStatus(db *gorm.DB, mode string) (*MigrationReport, error) {
+	migrations, err := loadMigrations()
+	if err != nil {
+		return nil, err
+	}
+	applied, err := appliedVersions(db)
+	if err != nil {
+		return nil, err
+	}
+	report := &MigrationReport{}
+	for _, mig := range migrations {
+		state := MigrationState{Migration: mig}
+		if at, ok := applied[mig.Version]; ok {
+			state.AppliedAt = &at
+			report.Current = max(report.Current, mig.Version)
+		}
+		report.Versions = append(report.Versions, state)
+	}
+	if report.Drift, err = schemaDrift(db, mode); err != nil {
+		return nil, err
+	}
+	return report, nil
+}
+
+// schemaDrift 找出模型中有而表中没有、或表中有而模型中没有的列；规范化模式下以维度ID替代的文本列不算模型的列。
+// type_zoo 由 0002 迁移创建，只在表已存在时比较
+func schemaDrift(db *gorm.DB, mode string) ([]string, error) {
+	m := db.Migrator()
+	var drift []string
+	for _, model := range append(Models(mode), &models.TypeZoo{}) {
+		stmt := &gorm.Statement{DB: db}
+		if err := stmt.Parse(model); err != nil {
+			return nil, err
+		}
+		table := stmt.Schema.Table
+		if !m.HasTable(table) {
+			if _, ok := model.(*models.TypeZoo); !ok {
+				drift = append(drift, fmt.Sprintf("表 %s 不存在", table))
+			}
+			continue
+		}
+		types, err := m.ColumnTypes(table)
+		if err != nil {
+			return nil, err
+		}
+		actual := make(map[string]bool, len(types))
+		for _, c := range types {
+			actual[c.Name()] = true
+		}
+		expected := map[string]bool{}
+		for _, name := range stmt.Schema.DBNames {
+			if !normalizedColumn(mode, table, name) {
+				expected[name] = true
+			}
+		}
+		for _, name := range stmt.Schema.DBNames {
+			if expected[name] && !actual[name] {
+				drift = append(drift, fmt.Sprintf("表 %s 缺少模型中的列 %s", table, name))
+			}
+		}
+		for _, c := range types {
+			if !expected[c.Name()] {
+				drift = append(drift, fmt.Sprintf("表 %s 的列 %s 不在模型中", table, c.Name()))
+			}
+		}
+	}
+	return drift, nil
+}
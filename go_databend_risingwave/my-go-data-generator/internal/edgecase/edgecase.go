@@ -0,0 +1,133 @@
+package edgecase
+
+import (
+	"context"
+	"math/rand"
+	"reflect"
+	"strings"
+	"sync"
+	"unicode/utf8"
+
+	"gorm.io/gorm/schema"
+)
+
+// Case 一种边界字符串
+type Case struct {
+	Name    string
+	Snippet string // 追加到原值之后的片段；size_limit 类型为空，由 Apply 按列长度填充
+}
+
+// Cases 所有可注入的边界字符串，覆盖 CDC 与 CSV 链路中最常出问题的字符
+var Cases = []Case{
+	{"emoji", "😀🎉👍🏻🇨🇳"},                     // 4 字节 utf8mb4 字符，含修饰符与旗帜组合
+	{"double_quote", `他说"你好"`},              // 双引号
+	{"single_quote", "O'Reilly's"},          // 单引号
+	{"comma", "a,b,,c"},                     // 逗号，CSV 分隔符
+	{"newline", "第一行\n第二行\r\n第三行"},          // 换行与回车
+	{"tab", "列1\t列2"},                       // 制表符
+	{"backslash", `C:\temp\new\table`},      // 反斜杠及看起来像转义的序列
+	{"null_marker", `\N`},                   // 与 CSV NULL 标记相同的字面量
+	{"control", "\x01\x07\x1b[0m\x7f"},      // 控制字符
+	{"nul", "before\x00after"},              // NUL 字节
+	{"zero_width", "零\u200b宽\ufeff字符"},      // 零宽空格与 BOM
+	{"sql_like", "'; DROP TABLE users; --"}, // 形似 SQL 的文本
+	{"size_limit", ""},                      // 恰好达到列的 size 上限
+}
+
+// textLimit TEXT 列的最大字节数
+const textLimit = 65535
+
+// fill 填充到长度上限时使用的多字节字符
+const fill = "界"
+
+// Injection 一次注入：哪一列注入了哪种边界字符串
+type Injection struct {
+	Column string
+	Case   string
+}
+
+// Injector 按 "表.列" 配置的比例向字符串列注入边界字符串
+type Injector struct {
+	rates map[string]float64
+	cache sync.Map
+}
+
+// New 创建注入器，rates 以 "表.列" 为键；rates 为空时返回 nil（不注入）
+func New(rates map[string]float64) *Injector {
+	if len(rates) == 0 {
+		return nil
+	}
+	return &Injector{rates: rates}
+}
+
+// Apply 对 row（模型指针）按比例注入边界字符串，返回本行的注入记录
+// 唯一索引列会保留原值作为前缀以免违反唯一约束
+func (in *Injector) Apply(table string, row interface{}) []Injection {
+	if in == nil {
+		return nil
+	}
+	s, err := schema.Parse(row, &in.cache, schema.NamingStrategy{})
+	if err != nil {
+		return nil
+	}
+	var out []Injection
+	rv := reflect.ValueOf(row)
+	for key, rate := range in.rates {
+		t, column, _ := strings.Cut(key, ".")
+		if t != table || rand.Float64() >= rate {
+			continue
+		}
+		field := s.LookUpField(column)
+		if field == nil {
+			continue
+		}
+		fv := field.ReflectValueOf(context.Background(), rv)
+		var current string
+		switch fv.Kind() {
+		case reflect.String:
+			current = fv.String()
+		case reflect.Ptr:
+			if fv.Type().Elem().Kind() != reflect.String {
+				continue
+			}
+			if !fv.IsNil() {
+				current = fv.Elem().String()
+			}
+		default:
+			continue
+		}
+
+		c := Cases[rand.Intn(len(Cases))]
+		value := inject(current, c, field.Size)
+		if fv.Kind() == reflect.Ptr {
+			fv.Set(reflect.ValueOf(&value))
+		} else {
+			fv.SetString(value)
+		}
+		out = append(out, Injection{Column: column, Case: c.Name})
+	}
+	return out
+}
+
+// inject 将边界字符串拼接到原值之后，并截断到列长度上限（size 按字符计，0 表示 TEXT）
+func inject(current string, c Case, size int) string {
+	if c.Name == "size_limit" {
+		if size > 0 {
+			n := size - utf8.RuneCountInString(current)
+			if n < 0 {
+				return string([]rune(current)[:size])
+			}
+			return current + strings.Repeat(fill, n)
+		}
+		n := textLimit - len(current)
+		if n < 0 {
+			return current[:textLimit]
+		}
+		return current + strings.Repeat("x", n)
+	}
+	value := current + c.Snippet
+	if size > 0 && utf8.RuneCountInString(value) > size {
+		value = string([]rune(value)[:size])
+	}
+	return value
+}
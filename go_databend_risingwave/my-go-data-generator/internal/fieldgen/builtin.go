@@ -0,0 +1,315 @@
+package fieldgen
+
+import (
+	"fmt"
+	"math"
+	"math/rand"
+	"regexp/syntax"
+	"strconv"
+	"strings"
+	"time"
+	"unicode"
+)
+
+func init() {
+	Register("enum", newEnum)
+	Register("zipf", newZipf)
+	Register("intrange", newIntRange)
+	Register("floatrange", newFloatRange)
+	Register("daterange", newDateRange)
+	Register("regex", newRegex)
+	Register("ref", newRef)
+	Register("seq", newSeq)
+	Register("bool", newBool)
+}
+
+// GeneratorFunc 将普通函数适配为 Generator
+type GeneratorFunc func(ctx *Context) interface{}
+
+// Generate 实现 Generator
+func (f GeneratorFunc) Generate(ctx *Context) interface{} {
+	return f(ctx)
+}
+
+// newEnum enum(值:权重,值:权重)，省略权重时为 1
+func newEnum(args []string) (Generator, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("enum 至少需要一个取值")
+	}
+	values := make([]string, len(args))
+	cumulative := make([]float64, len(args))
+	var total float64
+	for i, arg := range args {
+		value, weight, found := strings.Cut(arg, ":")
+		w := 1.0
+		if found {
+			f, err := strconv.ParseFloat(strings.TrimSpace(weight), 64)
+			if err != nil {
+				return nil, fmt.Errorf("enum 权重 %q: %w", arg, err)
+			}
+			w = f
+		}
+		total += w
+		values[i] = strings.TrimSpace(value)
+		cumulative[i] = total
+	}
+	return GeneratorFunc(func(*Context) interface{} {
+		target := rand.Float64() * total
+		for i, c := range cumulative {
+			if target < c {
+				return values[i]
+			}
+		}
+		return values[len(values)-1]
+	}), nil
+}
+
+// zipfIndex 按近似齐夫分布在 [0, n) 内抽取下标：P(i) ∝ 1/(i+1)^s，小下标更常见
+func zipfIndex(n int, s float64) int {
+	if n <= 1 {
+		return 0
+	}
+	u := rand.Float64()
+	var x float64
+	if s == 1 {
+		x = math.Pow(float64(n)+1, u) - 1
+	} else {
+		a := 1 - s
+		x = math.Pow((math.Pow(float64(n)+1, a)-1)*u+1, 1/a) - 1
+	}
+	i := int(x)
+	if i >= n {
+		i = n - 1
+	}
+	return i
+}
+
+// newZipf zipf(指数,最大值)：在 [0, 最大值] 内生成服从齐夫分布的整数
+func newZipf(args []string) (Generator, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("zipf 需要 指数,最大值 两个参数")
+	}
+	s, err := strconv.ParseFloat(args[0], 64)
+	if err != nil || s <= 0 {
+		return nil, fmt.Errorf("zipf 指数 %q 无效", args[0])
+	}
+	max, err := strconv.Atoi(args[1])
+	if err != nil || max < 0 {
+		return nil, fmt.Errorf("zipf 最大值 %q 无效", args[1])
+	}
+	return GeneratorFunc(func(*Context) interface{} {
+		return zipfIndex(max+1, s)
+	}), nil
+}
+
+// newIntRange intrange(最小,最大)：均匀分布的整数，含两端
+func newIntRange(args []string) (Generator, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("intrange 需要 最小,最大 两个参数")
+	}
+	min, err1 := strconv.Atoi(args[0])
+	max, err2 := strconv.Atoi(args[1])
+	if err1 != nil || err2 != nil || max < min {
+		return nil, fmt.Errorf("intrange 参数 %v 无效", args)
+	}
+	return GeneratorFunc(func(*Context) interface{} {
+		return min + rand.Intn(max-min+1)
+	}), nil
+}
+
+// newFloatRange floatrange(最小,最大[,小数位])：均匀分布的浮点数
+func newFloatRange(args []string) (Generator, error) {
+	if len(args) < 2 || len(args) > 3 {
+		return nil, fmt.Errorf("floatrange 需要 最小,最大[,小数位] 参数")
+	}
+	min, err1 := strconv.ParseFloat(args[0], 64)
+	max, err2 := strconv.ParseFloat(args[1], 64)
+	if err1 != nil || err2 != nil || max < min {
+		return nil, fmt.Errorf("floatrange 参数 %v 无效", args)
+	}
+	scale := 100.0
+	if len(args) == 3 {
+		d, err := strconv.Atoi(args[2])
+		if err != nil {
+			return nil, fmt.Errorf("floatrange 小数位 %q 无效", args[2])
+		}
+		scale = math.Pow(10, float64(d))
+	}
+	return GeneratorFunc(func(*Context) interface{} {
+		return math.Round((min+rand.Float64()*(max-min))*scale) / scale
+	}), nil
+}
+
+// parseOffset 解析相对当前时间的偏移，如 -180d、12h、-30m、0
+func parseOffset(s string) (time.Duration, error) {
+	s = strings.TrimSpace(s)
+	if s == "0" || s == "now" {
+		return 0, nil
+	}
+	if strings.HasSuffix(s, "d") {
+		days, err := strconv.ParseFloat(strings.TrimSuffix(s, "d"), 64)
+		if err != nil {
+			return 0, err
+		}
+		return time.Duration(days * float64(24*time.Hour)), nil
+	}
+	return time.ParseDuration(s)
+}
+
+// newDateRange daterange(起,止)：相对当前时间的区间内均匀分布的时间，如 daterange(-3650d,0)
+func newDateRange(args []string) (Generator, error) {
+	if len(args) != 2 {
+		return nil, fmt.Errorf("daterange 需要 起,止 两个参数")
+	}
+	from, err := parseOffset(args[0])
+	if err != nil {
+		return nil, fmt.Errorf("daterange 起点 %q: %w", args[0], err)
+	}
+	to, err := parseOffset(args[1])
+	if err != nil {
+		return nil, fmt.Errorf("daterange 终点 %q: %w", args[1], err)
+	}
+	if to < from {
+		return nil, fmt.Errorf("daterange 终点早于起点")
+	}
+	return GeneratorFunc(func(ctx *Context) interface{} {
+		d := from
+		if to > from {
+			d += time.Duration(rand.Int63n(int64(to - from)))
+		}
+		return ctx.Now.Add(d)
+	}), nil
+}
+
+// maxRepeat 正则中 *、+ 等无上限重复的最大展开次数
+const maxRepeat = 8
+
+// newRegex regex(模式)：生成匹配正则表达式的随机字符串
+func newRegex(args []string) (Generator, error) {
+	if len(args) == 0 {
+		return nil, fmt.Errorf("regex 需要模式参数")
+	}
+	pattern := strings.Join(args, ",")
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return nil, fmt.Errorf("regex %q: %w", pattern, err)
+	}
+	re = re.Simplify()
+	return GeneratorFunc(func(*Context) interface{} {
+		var b strings.Builder
+		generateRegex(&b, re)
+		return b.String()
+	}), nil
+}
+
+func generateRegex(b *strings.Builder, re *syntax.Regexp) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		b.WriteString(string(re.Rune))
+	case syntax.OpCharClass:
+		b.WriteRune(randClassRune(re.Rune))
+	case syntax.OpAnyCharNotNL, syntax.OpAnyChar:
+		b.WriteRune(rune('a' + rand.Intn(26)))
+	case syntax.OpCapture:
+		generateRegex(b, re.Sub[0])
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			generateRegex(b, sub)
+		}
+	case syntax.OpAlternate:
+		generateRegex(b, re.Sub[rand.Intn(len(re.Sub))])
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		min, max := re.Min, re.Max
+		switch re.Op {
+		case syntax.OpStar:
+			min, max = 0, maxRepeat
+		case syntax.OpPlus:
+			min, max = 1, maxRepeat
+		case syntax.OpQuest:
+			min, max = 0, 1
+		}
+		if max < 0 {
+			max = min + maxRepeat
+		}
+		n := min + rand.Intn(max-min+1)
+		for i := 0; i < n; i++ {
+			generateRegex(b, re.Sub[0])
+		}
+	}
+}
+
+// randClassRune 从字符类（成对的闭区间）中随机取一个可打印字符
+func randClassRune(ranges []rune) rune {
+	for attempt := 0; attempt < 16; attempt++ {
+		i := rand.Intn(len(ranges)/2) * 2
+		lo, hi := ranges[i], ranges[i+1]
+		if hi > unicode.MaxASCII && lo <= unicode.MaxASCII {
+			hi = unicode.MaxASCII
+		}
+		r := lo + rune(rand.Intn(int(hi-lo)+1))
+		if unicode.IsPrint(r) {
+			return r
+		}
+	}
+	return ranges[0]
+}
+
+// newRef ref(表[,zipf[,指数]])：从上下文中引用表的主键，可选按齐夫分布偏向少数热门行
+func newRef(args []string) (Generator, error) {
+	if len(args) == 0 || args[0] == "" {
+		return nil, fmt.Errorf("ref 需要被引用的表名")
+	}
+	table := args[0]
+	skew := 0.0
+	if len(args) >= 2 {
+		if args[1] != "zipf" {
+			return nil, fmt.Errorf("ref 的分布 %q 不受支持", args[1])
+		}
+		skew = 1.1
+		if len(args) == 3 {
+			s, err := strconv.ParseFloat(args[2], 64)
+			if err != nil || s <= 0 {
+				return nil, fmt.Errorf("ref 的指数 %q 无效", args[2])
+			}
+			skew = s
+		}
+	}
+	return GeneratorFunc(func(ctx *Context) interface{} {
+		pool := ctx.Refs[table]
+		if pool == nil || pool.Len() == 0 {
+			return uint(0)
+		}
+		i := rand.Intn(pool.Len())
+		if skew > 0 {
+			i = zipfIndex(pool.Len(), skew)
+		}
+		ctx.Picked[table] = i
+		return pool.ID(i)
+	}), nil
+}
+
+// newSeq seq(格式)：用行序号格式化出唯一值，如 seq(ORD%010d)
+func newSeq(args []string) (Generator, error) {
+	if len(args) != 1 || !strings.Contains(args[0], "%") {
+		return nil, fmt.Errorf("seq 需要一个包含 %%d 的格式参数")
+	}
+	format := args[0]
+	return GeneratorFunc(func(ctx *Context) interface{} {
+		return fmt.Sprintf(format, ctx.Seq)
+	}), nil
+}
+
+// newBool bool(概率)：以给定概率返回 true
+func newBool(args []string) (Generator, error) {
+	p := 0.5
+	if len(args) == 1 {
+		f, err := strconv.ParseFloat(args[0], 64)
+		if err != nil || f < 0 || f > 1 {
+			return nil, fmt.Errorf("bool 概率 %q 无效", args[0])
+		}
+		p = f
+	}
+	return GeneratorFunc(func(*Context) interface{} {
+		return rand.Float64() < p
+	}), nil
+}
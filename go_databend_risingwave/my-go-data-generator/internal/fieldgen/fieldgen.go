@@ -0,0 +1,262 @@
+package fieldgen
+
+import (
+	"context"
+	"database/sql"
+	"fmt"
+	"reflect"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm/schema"
+)
+
+// Context 生成一行数据时的上下文
+type Context struct {
+	Now  time.Time
+	Seq  int64           // 行序号，用于生成唯一值
+	Refs map[string]Pool // 可被 ref 引用的各表数据
+
+	// Picked 记录 ref 生成器在各表中选中的行下标，供依赖被引用行属性的字段使用
+	Picked map[string]int
+}
+
+// NewContext 创建生成上下文
+func NewContext(now time.Time, seq int64, refs map[string]Pool) *Context {
+	return &Context{Now: now, Seq: seq, Refs: refs, Picked: make(map[string]int)}
+}
+
+// Pool 可被引用的一张表的数据
+type Pool interface {
+	Len() int
+	ID(i int) uint
+}
+
+// Generator 字段生成器
+type Generator interface {
+	Generate(ctx *Context) interface{}
+}
+
+// Factory 根据参数创建生成器，参数为括号内以逗号分隔的部分
+type Factory func(args []string) (Generator, error)
+
+var (
+	registryMu sync.RWMutex
+	registry   = map[string]Factory{}
+)
+
+// Register 注册命名的字段生成器，新的生成器在各自文件的 init 中注册
+func Register(name string, f Factory) {
+	registryMu.Lock()
+	defer registryMu.Unlock()
+	registry[name] = f
+}
+
+// Names 返回已注册的生成器名称
+func Names() []string {
+	registryMu.RLock()
+	defer registryMu.RUnlock()
+	names := make([]string, 0, len(registry))
+	for name := range registry {
+		names = append(names, name)
+	}
+	return names
+}
+
+// Build 解析形如 name(arg1,arg2) 的生成器描述
+func Build(spec string) (Generator, error) {
+	spec = strings.TrimSpace(spec)
+	name, rest, hasArgs := strings.Cut(spec, "(")
+	var args []string
+	if hasArgs {
+		if !strings.HasSuffix(rest, ")") {
+			return nil, fmt.Errorf("生成器 %q 缺少右括号", spec)
+		}
+		args = splitArgs(strings.TrimSuffix(rest, ")"))
+	}
+	registryMu.RLock()
+	f, ok := registry[strings.TrimSpace(name)]
+	registryMu.RUnlock()
+	if !ok {
+		return nil, fmt.Errorf("未知的生成器 %q", name)
+	}
+	return f(args)
+}
+
+// splitArgs 按顶层逗号切分参数，括号与方括号内的逗号不切分（便于 regex 参数）
+func splitArgs(s string) []string {
+	var args []string
+	depth, start := 0, 0
+	for i, r := range s {
+		switch r {
+		case '(', '[', '{':
+			depth++
+		case ')', ']', '}':
+			depth--
+		case ',':
+			if depth == 0 {
+				args = append(args, strings.TrimSpace(s[start:i]))
+				start = i + 1
+			}
+		}
+	}
+	if tail := strings.TrimSpace(s[start:]); tail != "" || len(args) > 0 {
+		args = append(args, tail)
+	}
+	return args
+}
+
+// fieldSpec 一个带生成器的字段
+type fieldSpec struct {
+	field *schema.Field
+	gen   Generator
+}
+
+// Registry 按表缓存编译后的字段生成器，并合并来自配置的覆盖项
+type Registry struct {
+	mu        sync.Mutex
+	cache     sync.Map
+	tags      map[reflect.Type][]fieldSpec
+	overrides map[string]map[string]Generator // 表 -> 列 -> 生成器
+}
+
+// NewRegistry 创建注册表，overrides 以 "表.列" 为键、生成器描述为值
+func NewRegistry(overrides map[string]string) (*Registry, error) {
+	r := &Registry{tags: make(map[reflect.Type][]fieldSpec), overrides: make(map[string]map[string]Generator)}
+	for key, spec := range overrides {
+		table, column, ok := strings.Cut(key, ".")
+		if !ok {
+			return nil, fmt.Errorf("覆盖项 %q 应为 表.列", key)
+		}
+		g, err := Build(spec)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", key, err)
+		}
+		if r.overrides[table] == nil {
+			r.overrides[table] = make(map[string]Generator)
+		}
+		r.overrides[table][column] = g
+	}
+	return r, nil
+}
+
+// compile 解析模型上的 gen 标签，结果按类型缓存
+func (r *Registry) compile(row interface{}) (*schema.Schema, []fieldSpec, error) {
+	s, err := schema.Parse(row, &r.cache, schema.NamingStrategy{})
+	if err != nil {
+		return nil, nil, err
+	}
+	t := reflect.TypeOf(row).Elem()
+	r.mu.Lock()
+	defer r.mu.Unlock()
+	if specs, ok := r.tags[t]; ok {
+		return s, specs, nil
+	}
+	var specs []fieldSpec
+	for _, f := range s.Fields {
+		tag, ok := f.StructField.Tag.Lookup("gen")
+		if !ok {
+			continue
+		}
+		g, err := Build(tag)
+		if err != nil {
+			return nil, nil, fmt.Errorf("%s.%s: %w", s.Table, f.DBName, err)
+		}
+		specs = append(specs, fieldSpec{field: f, gen: g})
+	}
+	r.tags[t] = specs
+	return s, specs, nil
+}
+
+// Fill 为 row（模型指针）中带 gen 标签或配置了覆盖项的字段生成取值。
+// 领域生成逻辑应在 Fill 之后运行，并以这些字段为输入推导相关字段
+func (r *Registry) Fill(row interface{}, ctx *Context) error {
+	s, specs, err := r.compile(row)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(row)
+	overrides := r.overrides[s.Table]
+	for _, spec := range specs {
+		if _, ok := overrides[spec.field.DBName]; ok {
+			continue
+		}
+		if err := set(spec.field, rv, spec.gen.Generate(ctx)); err != nil {
+			return err
+		}
+	}
+	return r.ApplyOverrides(row, ctx)
+}
+
+// ApplyOverrides 只应用配置中的覆盖项；在领域生成逻辑之后再次调用，保证配置优先
+func (r *Registry) ApplyOverrides(row interface{}, ctx *Context) error {
+	s, _, err := r.compile(row)
+	if err != nil {
+		return err
+	}
+	rv := reflect.ValueOf(row)
+	for column, g := range r.overrides[s.Table] {
+		f := s.LookUpField(column)
+		if f == nil {
+			return fmt.Errorf("表 %s 没有列 %s", s.Table, column)
+		}
+		if err := set(f, rv, g.Generate(ctx)); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// Override 返回配置中表的某列的覆盖生成器，供没有模型的表（如表结构说明中的表）使用
+func (r *Registry) Override(table, column string) (Generator, bool) {
+	g, ok := r.overrides[table][column]
+	return g, ok
+}
+
+// set 将生成的值转换为字段类型后写入
+func set(f *schema.Field, row reflect.Value, value interface{}) error {
+	fv := f.ReflectValueOf(context.Background(), row)
+	target := fv.Type()
+	ptr := target.Kind() == reflect.Ptr
+	if ptr {
+		target = target.Elem()
+	}
+	if value == nil {
+		if !ptr {
+			return fmt.Errorf("列 %s 不可为 NULL", f.DBName)
+		}
+		fv.Set(reflect.Zero(fv.Type()))
+		return nil
+	}
+	// 实现 sql.Scanner 的类型（如定点金额）自行解析生成的值，避免按底层整数类型直接转换
+	if scanner, ok := reflect.New(target).Interface().(sql.Scanner); ok {
+		if err := scanner.Scan(value); err != nil {
+			return fmt.Errorf("列 %s: %w", f.DBName, err)
+		}
+		v := reflect.ValueOf(scanner).Elem()
+		if ptr {
+			fv.Set(v.Addr())
+		} else {
+			fv.Set(v)
+		}
+		return nil
+	}
+	v := reflect.ValueOf(value)
+	if !v.Type().ConvertibleTo(target) {
+		if target.Kind() == reflect.String {
+			v = reflect.ValueOf(fmt.Sprint(value))
+		} else {
+			return fmt.Errorf("列 %s 的类型 %s 无法接收 %T", f.DBName, target, value)
+		}
+	}
+	v = v.Convert(target)
+	if ptr {
+		p := reflect.New(target)
+		p.Elem().Set(v)
+		fv.Set(p)
+		return nil
+	}
+	fv.Set(v)
+	return nil
+}
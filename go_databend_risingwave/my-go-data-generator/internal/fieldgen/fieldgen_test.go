@@ -0,0 +1,232 @@
+package fieldgen
+
+import (
+	"reflect"
+	"regexp"
+	"strings"
+	"testing"
+	"time"
+
+	"my-go-data-generator/internal/money"
+)
+
+func TestSplitArgs(t *testing.T) {
+	tests := []struct {
+		in   string
+		want []string
+	}{
+		{"", nil},
+		{"a", []string{"a"}},
+		{"a, b", []string{"a", "b"}},
+		{"a,", []string{"a", ""}},
+		{" , ", []string{"", ""}},
+		{"[0-9]{2,3},x", []string{"[0-9]{2,3}", "x"}},
+		{"(a,b),c", []string{"(a,b)", "c"}},
+		{"A[a,b](c|d){1,2}", []string{"A[a,b](c|d){1,2}"}},
+		{"新品:2, 热销:1", []string{"新品:2", "热销:1"}},
+	}
+	for _, tt := range tests {
+		if got := splitArgs(tt.in); !reflect.DeepEqual(got, tt.want) {
+			t.Errorf("splitArgs(%q) = %q, want %q", tt.in, got, tt.want)
+		}
+	}
+}
+
+type testPool []uint
+
+func (p testPool) Len() int      { return len(p) }
+func (p testPool) ID(i int) uint { return p[i] }
+
+func TestBuild(t *testing.T) {
+	tests := []struct {
+		spec    string
+		want    interface{} // 为 nil 时只检查 match
+		match   string      // 结果字符串须匹配的正则
+		wantErr bool
+	}{
+		{spec: "seq(ORD%05d)", want: "ORD00042"},
+		{spec: " enum( a : 2 ) ", want: "a"},
+		{spec: "enum(only)", want: "only"},
+		{spec: "enum(a:0,b:1)", want: "b"},
+		{spec: "intrange(5,5)", want: 5},
+		{spec: "floatrange(1.5,1.5)", want: 1.5},
+		{spec: "zipf(1.2,0)", want: 0},
+		{spec: "bool(1)", want: true},
+		{spec: "bool(0)", want: false},
+		{spec: "ref(users)", want: uint(7)},
+		{spec: "ref(empty)", want: uint(0)},
+		{spec: "regex(AB[0-9]{3})", match: `^AB[0-9]{3}$`},
+		{spec: "regex(CPN[0-9]{2,4})", match: `^CPN[0-9]{2,4}$`},
+		{spec: "regex(x|y)", match: `^(x|y)$`},
+		{spec: "enum(a", wantErr: true},
+		{spec: "nosuch(1)", wantErr: true},
+		{spec: "", wantErr: true},
+		{spec: "enum()", wantErr: true},
+		{spec: "enum(a:x)", wantErr: true},
+		{spec: "intrange(5,1)", wantErr: true},
+		{spec: "intrange(1)", wantErr: true},
+		{spec: "floatrange(1,2,x)", wantErr: true},
+		{spec: "zipf(0,10)", wantErr: true},
+		{spec: "seq(ORD)", wantErr: true},
+		{spec: "bool(2)", wantErr: true},
+		{spec: "ref()", wantErr: true},
+		{spec: "ref(users,uniform)", wantErr: true},
+		{spec: "daterange(0,-1d)", wantErr: true},
+		{spec: "daterange(x,0)", wantErr: true},
+		{spec: "regex(a[)", wantErr: true},
+	}
+	refs := map[string]Pool{"users": testPool{7}, "empty": testPool{}}
+	for _, tt := range tests {
+		g, err := Build(tt.spec)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Build(%q) err = %v, wantErr %v", tt.spec, err, tt.wantErr)
+			continue
+		}
+		if tt.wantErr {
+			continue
+		}
+		got := g.Generate(NewContext(time.Now(), 42, refs))
+		if tt.want != nil && got != tt.want {
+			t.Errorf("Build(%q) 生成 %#v, want %#v", tt.spec, got, tt.want)
+		}
+		if tt.match != "" {
+			if s, ok := got.(string); !ok || !regexp.MustCompile(tt.match).MatchString(s) {
+				t.Errorf("Build(%q) 生成 %#v, 不匹配 %s", tt.spec, got, tt.match)
+			}
+		}
+	}
+}
+
+func TestRefPicked(t *testing.T) {
+	g, err := Build("ref(users,zipf,2)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	pool := testPool{10, 20, 30}
+	ctx := NewContext(time.Now(), 1, map[string]Pool{"users": pool})
+	id := g.Generate(ctx).(uint)
+	if i, ok := ctx.Picked["users"]; !ok || pool[i] != id {
+		t.Errorf("ref 生成 %d, Picked = %v", id, ctx.Picked)
+	}
+}
+
+func TestParseOffset(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    time.Duration
+		wantErr bool
+	}{
+		{"0", 0, false},
+		{"now", 0, false},
+		{"-180d", -180 * 24 * time.Hour, false},
+		{"1.5d", 36 * time.Hour, false},
+		{"12h", 12 * time.Hour, false},
+		{"-30m", -30 * time.Minute, false},
+		{"xd", 0, true},
+		{"10", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := parseOffset(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("parseOffset(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("parseOffset(%q) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestDateRange(t *testing.T) {
+	g, err := Build("daterange(-1d,0)")
+	if err != nil {
+		t.Fatal(err)
+	}
+	now := time.Date(2024, 6, 1, 12, 0, 0, 0, time.UTC)
+	for i := 0; i < 100; i++ {
+		got := g.Generate(NewContext(now, 0, nil)).(time.Time)
+		if got.Before(now.Add(-24*time.Hour)) || got.After(now) {
+			t.Fatalf("daterange(-1d,0) 生成 %v，不在 [%v, %v] 内", got, now.Add(-24*time.Hour), now)
+		}
+	}
+}
+
+func TestNewRegistry(t *testing.T) {
+	tests := []struct {
+		overrides map[string]string
+		wantErr   string
+	}{
+		{map[string]string{"orders.status": "enum(已完成)"}, ""},
+		{map[string]string{"users.hobby": "enum(阅读)", "orders.status": "seq(S%d)"}, ""},
+		{map[string]string{"orders": "enum(x)"}, "应为 表.列"},
+		{map[string]string{"orders.status": "nosuch()"}, "orders.status"},
+		{nil, ""},
+	}
+	for _, tt := range tests {
+		r, err := NewRegistry(tt.overrides)
+		if tt.wantErr != "" {
+			if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+				t.Errorf("NewRegistry(%v) err = %v, want 包含 %q", tt.overrides, err, tt.wantErr)
+			}
+			continue
+		}
+		if err != nil {
+			t.Errorf("NewRegistry(%v) err = %v", tt.overrides, err)
+			continue
+		}
+		for key := range tt.overrides {
+			table, column, _ := strings.Cut(key, ".")
+			if _, ok := r.Override(table, column); !ok {
+				t.Errorf("NewRegistry(%v) 缺少覆盖项 %s", tt.overrides, key)
+			}
+		}
+	}
+}
+
+type testRow struct {
+	ID    uint
+	Code  string       `gen:"seq(C%03d)"`
+	Level int          `gen:"intrange(3,3)"`
+	Note  *string      `gen:"enum(x)"`
+	Price money.Amount `gen:"floatrange(12.34,12.34)"`
+	Plain string
+}
+
+func TestFill(t *testing.T) {
+	tests := []struct {
+		overrides map[string]string
+		want      testRow
+	}{
+		{nil, testRow{Code: "C005", Level: 3, Price: 1234}},
+		{map[string]string{"test_rows.level": "intrange(7,7)", "test_rows.plain": "enum(p)"}, testRow{Code: "C005", Level: 7, Price: 1234, Plain: "p"}},
+		{map[string]string{"test_rows.price": "enum(0.005)"}, testRow{Code: "C005", Level: 3, Price: 1}},
+	}
+	for _, tt := range tests {
+		r, err := NewRegistry(tt.overrides)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var row testRow
+		if err := r.Fill(&row, NewContext(time.Now(), 5, nil)); err != nil {
+			t.Errorf("Fill(%v) err = %v", tt.overrides, err)
+			continue
+		}
+		if row.Note == nil || *row.Note != "x" {
+			t.Errorf("Fill(%v) Note = %v, want x", tt.overrides, row.Note)
+		}
+		row.Note = nil
+		if row != tt.want {
+			t.Errorf("Fill(%v) = %+v, want %+v", tt.overrides, row, tt.want)
+		}
+	}
+}
+
+func TestFillUnknownColumn(t *testing.T) {
+	r, err := NewRegistry(map[string]string{"test_rows.missing": "enum(x)"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := r.Fill(&testRow{}, NewContext(time.Now(), 0, nil)); err == nil {
+		t.Error("覆盖不存在的列应报错")
+	}
+}
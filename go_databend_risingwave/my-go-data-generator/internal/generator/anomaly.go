@@ -0,0 +1,59 @@
+package generator
+
+import (
+	"log"
+
+	"my-go-data-generator/internal/anomaly"
+	"my-go-data-generator/internal/manifest"
+)
+
+var (
+	// anomalies 数据质量缺陷注入器，未配置 ANOMALY_RATES 时为 nil
+	anomalies *anomaly.Injector
+	// anomalyManifest 缺陷真值清单：表、主键与缺陷类型
+	anomalyManifest *manifest.Writer
+)
+
+// configureAnomalies 按配置创建缺陷注入器；真值清单由 openAnomalyManifest 在写入数据前打开
+func configureAnomalies() {
+	anomalies = anomaly.New(conf.AnomalyRates)
+}
+
+// openAnomalyManifest 以追加方式打开缺陷清单，只在批量生成与定时任务写入数据前调用，
+// migrate、validate 等操作不会改动清单；打开失败时退出，避免注入的缺陷没有真值记录
+func openAnomalyManifest() {
+	if anomalies == nil || anomalyManifest != nil {
+		return
+	}
+	w, err := manifest.Open(conf.AnomalyManifest)
+	if err != nil {
+		log.Fatalf("打开缺陷清单 %s 失败: %v", conf.AnomalyManifest, err)
+	}
+	anomalyManifest = w
+	log.Printf("已启用数据质量缺陷注入，清单追加写入 %s", conf.AnomalyManifest)
+}
+
+// recordAnomalies 入库后按主键记录各行注入的缺陷，found[i] 对应主键 id(i)
+func recordAnomalies(table string, found [][]anomaly.Anomaly, id func(i int) uint) {
+	for i, list := range found {
+		for _, a := range list {
+			entry := manifest.Entry{Table: table, ID: id(i), Column: a.Column, Kind: a.Kind, Detail: a.Detail}
+			if err := anomalyManifest.Record(entry); err != nil {
+				log.Printf("写入缺陷清单失败: %v", err)
+			}
+		}
+	}
+}
+
+// isDuplicate 批次内下标 i 的用户是否已参与近似重复（作为原用户或副本）
+func isDuplicate(duplicateOf map[int]int, i int) bool {
+	if _, ok := duplicateOf[i]; ok {
+		return true
+	}
+	for _, src := range duplicateOf {
+		if src == i {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,244 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+	"my-go-data-generator/internal/pricing"
+)
+
+// brand 品牌，制造商、供应商与产地在同一品牌下保持一致
+type brand struct {
+	Name         string
+	Manufacturer string
+	Supplier     string
+	Country      string
+}
+
+// categorySpec 描述一个分类的商品特征
+type categorySpec struct {
+	Name       string
+	SKUPrefix  string
+	Nouns      []string   // 商品名词
+	Adjectives []string   // 商品修饰词
+	Materials  []string   // 材质
+	Colors     []string   // 颜色
+	Weight     [2]float64 // 重量范围（千克）
+	Dimensions [3][2]int  // 长宽高范围（厘米）
+	Price      [2]float64 // 价格区间（元）
+	Warranty   []int      // 可选保修月数，为空表示不提供保修
+	Brands     []brand    // 该分类下的品牌
+}
+
+var catalog = []categorySpec{
+	{
+		Name:       "电子产品",
+		SKUPrefix:  "EL",
+		Nouns:      []string{"蓝牙耳机", "智能手表", "移动电源", "机械键盘", "无线鼠标", "平板电脑", "智能音箱"},
+		Adjectives: []string{"轻薄", "旗舰", "降噪", "便携", "高性能", "青春版"},
+		Materials:  []string{"铝合金", "ABS塑料", "玻璃", "硅胶"},
+		Colors:     []string{"黑", "白", "银", "深空灰", "蓝"},
+		Weight:     [2]float64{0.05, 1.5},
+		Dimensions: [3][2]int{{5, 35}, {5, 25}, {1, 10}},
+		Price:      [2]float64{59, 4999},
+		Warranty:   []int{12, 24},
+		Brands: []brand{
+			{"星辰", "深圳星辰电子有限公司", "华南数码供应链", "中国"},
+			{"Sonic", "Sonic Electronics Inc.", "环球电子进出口", "美国"},
+			{"光栅", "东莞光栅科技有限公司", "华南数码供应链", "中国"},
+			{"Kaito", "Kaito Denki Co., Ltd.", "东亚电子贸易", "日本"},
+		},
+	},
+	{
+		Name:       "家居用品",
+		SKUPrefix:  "HM",
+		Nouns:      []string{"收纳盒", "保温杯", "床上四件套", "台灯", "抱枕", "置物架", "炒锅"},
+		Adjectives: []string{"北欧风", "简约", "加厚", "大容量", "防滑", "可折叠"},
+		Materials:  []string{"不锈钢", "实木", "纯棉", "陶瓷", "PP塑料"},
+		Colors:     []string{"白", "原木色", "米黄", "灰", "绿"},
+		Weight:     [2]float64{0.2, 8},
+		Dimensions: [3][2]int{{10, 120}, {10, 80}, {5, 60}},
+		Price:      [2]float64{9.9, 899},
+		Warranty:   []int{3, 6, 12},
+		Brands: []brand{
+			{"栖居", "佛山栖居家居有限公司", "华东家居供应链", "中国"},
+			{"木语", "杭州木语工艺品厂", "华东家居供应链", "中国"},
+			{"Nordhem", "Nordhem AB", "北欧家居进口", "瑞典"},
+		},
+	},
+	{
+		Name:       "服装",
+		SKUPrefix:  "AP",
+		Nouns:      []string{"T恤", "牛仔裤", "羽绒服", "卫衣", "连衣裙", "衬衫", "运动短裤"},
+		Adjectives: []string{"修身", "宽松", "纯色", "印花", "加绒", "速干"},
+		Materials:  []string{"纯棉", "涤纶", "羊毛", "丝绸", "牛仔布"},
+		Colors:     []string{"黑", "白", "藏青", "卡其", "红"},
+		Weight:     [2]float64{0.1, 1.8},
+		Dimensions: [3][2]int{{20, 50}, {15, 40}, {2, 10}},
+		Price:      [2]float64{29, 1299},
+		Warranty:   nil,
+		Brands: []brand{
+			{"衣本", "广州衣本服饰有限公司", "华南服装批发", "中国"},
+			{"Urbanite", "Urbanite Apparel Ltd.", "环球服饰贸易", "越南"},
+			{"织造", "苏州织造纺织有限公司", "华东服装供应链", "中国"},
+		},
+	},
+	{
+		Name:       "运动器材",
+		SKUPrefix:  "SP",
+		Nouns:      []string{"瑜伽垫", "哑铃", "跑步机", "篮球", "羽毛球拍", "登山包", "跳绳"},
+		Adjectives: []string{"专业级", "入门款", "加厚", "可调节", "轻量化"},
+		Materials:  []string{"TPE", "碳纤维", "橡胶", "铸铁", "尼龙"},
+		Colors:     []string{"黑", "蓝", "荧光绿", "橙", "紫"},
+		Weight:     [2]float64{0.1, 60},
+		Dimensions: [3][2]int{{10, 180}, {10, 80}, {2, 60}},
+		Price:      [2]float64{19, 3999},
+		Warranty:   []int{3, 6, 12},
+		Brands: []brand{
+			{"跃动", "晋江跃动体育用品有限公司", "华南体育用品批发", "中国"},
+			{"Peakline", "Peakline Sports GmbH", "欧洲运动用品进口", "德国"},
+		},
+	},
+	{
+		Name:       "食品",
+		SKUPrefix:  "FD",
+		Nouns:      []string{"坚果礼盒", "挂耳咖啡", "牛肉干", "绿茶", "手工饼干", "蜂蜜", "燕麦片"},
+		Adjectives: []string{"原味", "低糖", "有机", "家庭装", "便携装"},
+		Materials:  []string{"食品级包装"},
+		Colors:     []string{"原色"},
+		Weight:     [2]float64{0.1, 2.5},
+		Dimensions: [3][2]int{{5, 30}, {5, 25}, {2, 15}},
+		Price:      [2]float64{6.8, 299},
+		Warranty:   nil,
+		Brands: []brand{
+			{"山野", "云南山野食品有限公司", "西南农产品供应链", "中国"},
+			{"禾田", "黑龙江禾田农业有限公司", "东北粮油批发", "中国"},
+			{"Alpenhof", "Alpenhof Lebensmittel GmbH", "欧洲食品进口", "奥地利"},
+		},
+	},
+}
+
+// 分类名列表，保持与 catalog 的顺序一致
+var categories = func() []string {
+	names := make([]string, len(catalog))
+	for i, c := range catalog {
+		names[i] = c.Name
+	}
+	return names
+}()
+
+func pickString(list []string) string {
+	return list[rand.Intn(len(list))]
+}
+
+func randFloat(r [2]float64) float64 {
+	return r[0] + rand.Float64()*(r[1]-r[0])
+}
+
+func randInt(r [2]int) int {
+	return r[0] + rand.Intn(r[1]-r[0]+1)
+}
+
+// newProduct 按分类模型生成一个产品，skuSeq 用于保证 SKU 唯一
+func newProduct(ctx *fieldgen.Context, skuSeq string) models.Product {
+	var p models.Product
+	fill(&p, ctx)
+	now := ctx.Now
+	spec := catalog[rand.Intn(len(catalog))]
+	b := spec.Brands[rand.Intn(len(spec.Brands))]
+	noun := pickString(spec.Nouns)
+
+	warranty := ""
+	if len(spec.Warranty) > 0 {
+		warranty = fmt.Sprintf("%d个月", spec.Warranty[rand.Intn(len(spec.Warranty))])
+	}
+
+	p.ProductName = fmt.Sprintf("%s %s%s %s%d", b.Name, pickString(spec.Adjectives), noun, string(rune('A'+rand.Intn(26))), rand.Intn(100))
+	p.Category = spec.Name
+	p.Description = optional("products", "description", textFor("products", "description", fmt.Sprintf("【%s %s】", b.Name, noun)))
+	p.Price = money.FromFloat(randFloat(spec.Price))
+	p.SKU = fmt.Sprintf("%s-%s", spec.SKUPrefix, skuSeq)
+	p.Manufacturer = b.Manufacturer
+	p.Weight = pricing.Round(randFloat(spec.Weight))
+	p.Dimensions = fmt.Sprintf("%dx%dx%d", randInt(spec.Dimensions[0]), randInt(spec.Dimensions[1]), randInt(spec.Dimensions[2]))
+	p.Color = pickString(spec.Colors)
+	p.Material = pickString(spec.Materials)
+	p.WarrantyPeriod = optional("products", "warranty_period", warranty)
+	p.CountryOfOrigin = b.Country
+	p.Supplier = b.Supplier
+	p.CreatedAt = now
+	p.UpdatedAt = now
+	setStock(&p, now)
+	applyOverrides(&p, ctx)
+	dims.product(&p)
+	return p
+}
+
+// setStock 同时设置库存与库存状态：缺货与预订的库存为 0，预订商品的发布日期在未来
+func setStock(p *models.Product, now time.Time) {
+	switch r := rand.Float64(); {
+	case r < 0.03:
+		p.Stock = 0
+		p.StockStatus = models.StockStatusPreOrder
+		p.ReleaseDate = now.AddDate(0, 0, rand.Intn(60)+1)
+	case r < 0.13:
+		p.Stock = 0
+		p.StockStatus = models.StockStatusOutOfStock
+	default:
+		p.Stock = rand.Intn(5000) + 1
+		p.StockStatus = models.StockStatusInStock
+	}
+}
+
+// stockStatus 按库存余额推导库存状态：有库存即有货，售罄时尚未发布的预订商品仍为预订，否则为缺货
+func stockStatus(stock int, preorder bool) string {
+	switch {
+	case stock > 0:
+		return models.StockStatusInStock
+	case preorder:
+		return models.StockStatusPreOrder
+	default:
+		return models.StockStatusOutOfStock
+	}
+}
+
+// writeBackProducts 订单生成完毕后按评论汇总与库存账本更新产品，并分批回写评分、评论数与库存
+func writeBackProducts(db *gorm.DB, products []models.Product, stats *reviewStats, ledger *inventoryLedger, batchSize int) {
+	for i := range products {
+		stats.apply(&products[i])
+		ledger.apply(&products[i])
+	}
+	if len(products) == 0 {
+		return
+	}
+	// 产品已存在，按主键冲突只更新由评论与库存流水派生的列
+	err := db.Clauses(clause.OnConflict{
+		Columns:   []clause.Column{{Name: "id"}},
+		DoUpdates: clause.AssignmentColumns([]string{"rating", "number_of_reviews", "stock", "stock_status"}),
+	}).CreateInBatches(&products, batchSize).Error
+	if err != nil {
+		log.Printf("回写产品评分与库存失败: %v", err)
+		return
+	}
+	log.Printf("已按评论与库存流水回写 %d 个产品", len(products))
+}
+
+// updateStock 将账本中的库存余额写回单个产品，供定时任务在每笔订单后维护库存；开启 ROW_HISTORY 时先记录原版本
+func updateStock(db *gorm.DB, ledger *inventoryLedger, productID uint, now time.Time) error {
+	p := models.Product{ID: productID}
+	ledger.apply(&p)
+	return db.Transaction(func(tx *gorm.DB) error {
+		if err := recordHistory(tx, "products", historyUpdate, now, productID); err != nil {
+			return err
+		}
+		return asOf(tx, now).Model(&models.Product{}).Where("id = ?", productID).
+			Updates(map[string]interface{}{"stock": p.Stock, "stock_status": p.StockStatus}).Error
+	})
+}
@@ -0,0 +1,349 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"net/url"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/models"
+)
+
+const (
+	siteURL          = "https://shop.example.com"
+	maxSessionEvents = 40                  // 一次会话最多的事件数
+	sessionSpan      = 90 * 24 * time.Hour // 存量浏览会话的时间跨度
+	liveSessionSpan  = 20                  // 定时任务中每个在线会话平均每隔多少秒产生一个事件
+	poolRefresh      = 30 * time.Second    // 定时任务刷新可引用用户与产品的间隔
+	streamUserPool   = 1000                // 定时任务可引用的最近用户数
+	exitPage         = ""                  // 离开站点
+)
+
+var (
+	// searchEngines、socialSites 会话入口的外部来源，直接访问的来源为 NULL
+	searchEngines = []string{"https://www.baidu.com/s?wd=", "https://www.sogou.com/web?query=", "https://cn.bing.com/search?q="}
+	socialSites   = []string{"https://weibo.com/", "https://www.xiaohongshu.com/explore", "https://www.douyin.com/", "https://mail.qq.com/"}
+
+	// nextPages 浏览会话在各页面之后的去向及权重；浏览会话不会下单，结算后即离开（弃单），
+	// 下单事件只出现在与真实订单关联的转化会话中
+	nextPages = map[string][]pageWeight{
+		models.PageViewHome:      {{models.PageViewSearch, 35}, {models.PageViewProduct, 45}, {exitPage, 20}},
+		models.PageViewSearch:    {{models.PageViewProduct, 65}, {models.PageViewSearch, 15}, {exitPage, 20}},
+		models.PageViewProduct:   {{models.PageViewProduct, 35}, {models.PageViewAddToCart, 15}, {models.PageViewSearch, 15}, {models.PageViewHome, 5}, {exitPage, 30}},
+		models.PageViewAddToCart: {{models.PageViewProduct, 35}, {models.PageViewCheckout, 25}, {exitPage, 40}},
+		models.PageViewCheckout:  {{exitPage, 1}},
+	}
+
+	// productRef 按齐夫分布选取浏览的产品，热门产品被浏览得更多
+	productRef = mustGenerator("ref(products,zipf)")
+	// userRef 选取登录访客
+	userRef = mustGenerator("ref(users)")
+)
+
+type pageWeight struct {
+	page   string
+	weight int
+}
+
+func mustGenerator(spec string) fieldgen.Generator {
+	g, err := fieldgen.Build(spec)
+	if err != nil {
+		log.Fatalf("字段生成器配置错误: %v", err)
+	}
+	return g
+}
+
+// randHex 生成 n 个十六进制字符的随机标识
+func randHex(n int) string {
+	const digits = "0123456789abcdef"
+	b := make([]byte, n)
+	for i := range b {
+		b[i] = digits[rand.Intn(len(digits))]
+	}
+	return string(b)
+}
+
+// deviceOf 登录用户的设备标识由用户ID确定，同一用户的多次会话共用设备
+func deviceOf(userID uint) string {
+	h := uint64(userID) * 0x9E3779B97F4A7C15
+	return fmt.Sprintf("%016x%016x", h, h^0xD1B54A32D192ED03)
+}
+
+// session 一次访问会话的状态：事件按页面去向逐个生成，来源页为上一个页面
+type session struct {
+	id         string
+	template   models.PageView // 会话内不变的字段（设备标识、用户、设备类型）
+	seq        int
+	page       string          // 当前所在页面（事件类型），会话开始前为空
+	referrer   *string         // 下一个事件的来源页
+	product    *models.Product // 当前浏览的产品
+	fromSearch bool            // 入口来自搜索引擎，首个页面直接落在商品页
+}
+
+// newSession 开始一次会话：按配置比例为匿名访客或登录用户，设备类型由 page_views.device 的生成器选取，
+// userID 非空时会话属于该用户（转化会话）
+func newSession(ctx *fieldgen.Context, userID *uint) *session {
+	s := &session{id: randHex(32)}
+	fill(&s.template, ctx)
+	switch {
+	case userID != nil:
+		s.template.UserID = userID
+	case rand.Float64() >= conf.ClickstreamAnonymousRate && ctx.Refs["users"] != nil && ctx.Refs["users"].Len() > 0:
+		id := userRef.Generate(ctx).(uint)
+		s.template.UserID = &id
+	}
+	if s.template.UserID != nil {
+		s.template.AnonymousID = deviceOf(*s.template.UserID)
+	} else {
+		s.template.AnonymousID = randHex(32)
+	}
+	switch r := rand.Float64(); {
+	case r < 0.4:
+		// 直接访问
+	case r < 0.7:
+		s.fromSearch = true
+		s.referrer = ptr(pickString(searchEngines) + url.QueryEscape(pickString(categories)))
+	default:
+		s.referrer = ptr(pickString(socialSites))
+	}
+	applyOverrides(&s.template, ctx)
+	return s
+}
+
+// emit 生成会话的下一个事件，页面地址成为之后事件的来源页
+func (s *session) emit(page string, productID *uint, pageURL string, at time.Time) models.PageView {
+	s.seq++
+	s.page = page
+	v := s.template
+	v.SessionID = s.id
+	v.Sequence = s.seq
+	v.EventType = page
+	v.ProductID = productID
+	v.PageURL = pageURL
+	v.Referrer = s.referrer
+	v.EventTime = at
+	v.CreatedAt = at
+	s.referrer = ptr(pageURL)
+	return v
+}
+
+// pick 选取一个产品作为当前浏览的产品
+func (s *session) pick(ctx *fieldgen.Context) bool {
+	pool, ok := ctx.Refs["products"].(productPool)
+	if !ok || pool.Len() == 0 {
+		return false
+	}
+	productRef.Generate(ctx)
+	p := pool[ctx.Picked["products"]]
+	s.product = &p
+	return true
+}
+
+// visit 生成浏览某个页面的事件
+func (s *session) visit(ctx *fieldgen.Context, page string, at time.Time) models.PageView {
+	switch page {
+	case models.PageViewSearch:
+		// 搜索某个分类后通常浏览该分类下的产品
+		q := pickString(categories)
+		if s.pick(ctx) {
+			q = s.product.Category
+		}
+		return s.emit(page, nil, siteURL+"/search?q="+url.QueryEscape(q), at)
+	case models.PageViewProduct:
+		if s.page != models.PageViewSearch || s.product == nil {
+			s.pick(ctx)
+		}
+		if s.product == nil {
+			return s.emit(models.PageViewHome, nil, siteURL+"/", at)
+		}
+		id := s.product.ID
+		return s.emit(page, &id, fmt.Sprintf("%s/product/%d", siteURL, id), at)
+	case models.PageViewAddToCart:
+		id := s.product.ID
+		return s.emit(page, &id, fmt.Sprintf("%s/cart?add=%d", siteURL, id), at)
+	case models.PageViewCheckout:
+		return s.emit(page, nil, siteURL+"/checkout", at)
+	default:
+		return s.emit(models.PageViewHome, nil, siteURL+"/", at)
+	}
+}
+
+// step 生成浏览会话的下一个事件；返回 false 表示会话已结束
+func (s *session) step(ctx *fieldgen.Context, at time.Time) (models.PageView, bool) {
+	if s.seq >= maxSessionEvents {
+		return models.PageView{}, false
+	}
+	var page string
+	if s.page == "" {
+		// 从搜索引擎进入的访客多半直接落在商品页
+		page = models.PageViewHome
+		if s.fromSearch && rand.Intn(10) < 7 {
+			page = models.PageViewProduct
+		}
+	} else {
+		page = nextPage(s.page)
+		if page == exitPage {
+			return models.PageView{}, false
+		}
+	}
+	if page == models.PageViewAddToCart && s.product == nil {
+		page = models.PageViewProduct
+	}
+	return s.visit(ctx, page, at), true
+}
+
+// nextPage 按权重选取下一个页面
+func nextPage(page string) string {
+	options := nextPages[page]
+	total := 0
+	for _, o := range options {
+		total += o.weight
+	}
+	if total == 0 {
+		return exitPage
+	}
+	r := rand.Intn(total)
+	for _, o := range options {
+		if r < o.weight {
+			return o.page
+		}
+		r -= o.weight
+	}
+	return exitPage
+}
+
+// eventGap 会话内相邻事件的间隔
+func eventGap() time.Duration {
+	return randDuration(3*time.Second, 2*time.Minute)
+}
+
+// newBrowseSession 生成一次完整的浏览会话，开始时间在 start 之后，事件时间不晚于 now
+func newBrowseSession(ctx *fieldgen.Context, start, now time.Time) []models.PageView {
+	s := newSession(ctx, nil)
+	var views []models.PageView
+	at := start
+	for {
+		v, ok := s.step(ctx, at)
+		if !ok {
+			return views
+		}
+		views = append(views, v)
+		if at = at.Add(eventGap()); at.After(now) {
+			return views
+		}
+	}
+}
+
+// newConversionSession 生成下单用户促成订单的会话：进入站点、可能浏览其他产品，
+// 依次浏览并加购订单中的每个商品，结算后在下单时间产生下单事件；事件时间从下单时间倒推
+// 下单事件的 OrderID 需在订单入库后由 linkPageViews 回填
+func newConversionSession(ctx *fieldgen.Context, o *models.Order, items []models.OrderItem) []models.PageView {
+	userID := o.UserID
+	s := newSession(ctx, &userID)
+	type visit struct {
+		page    string
+		product *uint
+	}
+	plan := []visit{{page: models.PageViewHome}}
+	if s.fromSearch {
+		plan[0].page = models.PageViewProduct
+	}
+	for i := rand.Intn(3); i > 0; i-- {
+		plan = append(plan, visit{page: models.PageViewProduct})
+	}
+	for _, it := range items {
+		id := it.ProductID
+		plan = append(plan, visit{models.PageViewProduct, &id}, visit{models.PageViewAddToCart, &id})
+	}
+	plan = append(plan, visit{page: models.PageViewCheckout})
+
+	// 倒推各事件时间，下单事件恰在下单时间
+	times := make([]time.Time, len(plan)+1)
+	times[len(plan)] = o.OrderDate
+	for i := len(plan) - 1; i >= 0; i-- {
+		times[i] = times[i+1].Add(-eventGap())
+	}
+	views := make([]models.PageView, 0, len(plan)+1)
+	for i, v := range plan {
+		switch {
+		case v.product != nil && v.page == models.PageViewProduct:
+			views = append(views, s.emit(v.page, v.product, fmt.Sprintf("%s/product/%d", siteURL, *v.product), times[i]))
+		case v.product != nil:
+			views = append(views, s.emit(v.page, v.product, fmt.Sprintf("%s/cart?add=%d", siteURL, *v.product), times[i]))
+		default:
+			views = append(views, s.visit(ctx, v.page, times[i]))
+		}
+	}
+	return append(views, s.emit(models.PageViewPurchase, nil, siteURL+"/order/success?no="+o.OrderNumber, o.OrderDate))
+}
+
+// linkPageViews 订单入库后回填下单事件的 OrderID，并展开为一个切片以便批量插入
+func linkPageViews(orders []models.Order, views [][]models.PageView) []models.PageView {
+	var out []models.PageView
+	for i := range orders {
+		id := orders[i].ID
+		for j := range views[i] {
+			if views[i][j].EventType == models.PageViewPurchase {
+				views[i][j].OrderID = &id
+			}
+		}
+		out = append(out, views[i]...)
+	}
+	return out
+}
+
+// streamClickstream 定时任务的点击流：每秒追加约 CLICKSTREAM_EVENTS_PER_SECOND 个事件，
+// 由一组在线会话交替产生，结束的会话由新会话替补，形成持续的高频只追加事件流
+func streamClickstream(db *gorm.DB) {
+	rate := conf.ClickstreamRate
+	live := int(rate*liveSessionSpan) + 1
+	var sessions []*session
+	var refs map[string]fieldgen.Pool
+	var refreshed time.Time
+	var ticks, total int
+	ticker := time.NewTicker(time.Second)
+	for now := range ticker.C {
+		ticks++
+		if now.Sub(refreshed) >= poolRefresh {
+			var users []models.User
+			var products []models.Product
+			db.Order("id DESC").Limit(streamUserPool).Find(&users)
+			db.Order("id DESC").Limit(streamProductPool).Find(&products)
+			dims.nameUsers(users)
+			dims.nameProducts(products)
+			refs = newRefs(users, products)
+			refreshed = now
+		}
+		ctx := fieldgen.NewContext(now, now.UnixNano(), refs)
+		n := int(rate)
+		if rand.Float64() < rate-float64(n) {
+			n++
+		}
+		var views []models.PageView
+		for len(views) < n {
+			for len(sessions) < live {
+				sessions = append(sessions, newSession(ctx, nil))
+			}
+			i := rand.Intn(len(sessions))
+			if v, ok := sessions[i].step(ctx, now); ok {
+				views = append(views, v)
+			} else {
+				sessions[i] = sessions[len(sessions)-1]
+				sessions = sessions[:len(sessions)-1]
+			}
+		}
+		if len(views) == 0 {
+			continue
+		}
+		if err := db.CreateInBatches(&views, 1000).Error; err != nil {
+			log.Printf("追加点击流事件失败: %v", err)
+			continue
+		}
+		if total += len(views); ticks%60 == 0 {
+			log.Printf("已追加点击流事件 %d 条，在线会话 %d 个", total, len(sessions))
+		}
+	}
+}
@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"sort"
+	"sync"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"my-go-data-generator/internal/config"
+	"my-go-data-generator/internal/csv"
+	"my-go-data-generator/internal/models"
+)
+
+// dimensionSet 规范化模式下各维度表的名称到主键的映射。主键按出现顺序分配：
+// 先按分类模型与支付方式预置，FIELD_GENERATORS 覆盖产生的新名称在生成时追加，等待 flush 写入
+type dimensionSet struct {
+	mu       sync.Mutex
+	ids      map[string]map[string]uint // 维度表 -> 名称 -> 主键
+	names    map[string][]string        // 维度表 -> 按主键排列的名称
+	written  map[string]int             // 维度表 -> 已写入的行数
+	regionOf map[string]string          // 制造商 -> 所在地区
+}
+
+// dims 全局维度映射，批量生成与定时任务共用
+var dims = newDimensionSet()
+
+func newDimensionSet() *dimensionSet {
+	d := &dimensionSet{
+		ids:      make(map[string]map[string]uint),
+		names:    make(map[string][]string),
+		written:  make(map[string]int),
+		regionOf: make(map[string]string),
+	}
+	for _, c := range catalog {
+		d.id("categories", c.Name)
+		for _, b := range c.Brands {
+			d.regionOf[b.Manufacturer] = b.Country
+			d.id("regions", b.Country)
+			d.id("manufacturers", b.Manufacturer)
+			d.id("suppliers", b.Supplier)
+		}
+	}
+	methods := make([]string, 0, len(paymentProviders))
+	for m := range paymentProviders {
+		methods = append(methods, m)
+	}
+	sort.Strings(methods)
+	for _, m := range methods {
+		d.id("payment_methods", m)
+	}
+	return d
+}
+
+// normalized 当前是否为规范化模式
+func normalized() bool {
+	return conf.SchemaMode == config.SchemaNormalized
+}
+
+// id 返回名称在维度表中的主键，新名称分配下一个主键
+func (d *dimensionSet) id(table, name string) *uint {
+	m := d.ids[table]
+	if m == nil {
+		m = make(map[string]uint)
+		d.ids[table] = m
+	}
+	id, ok := m[name]
+	if !ok {
+		d.names[table] = append(d.names[table], name)
+		id = uint(len(d.names[table]))
+		m[name] = id
+	}
+	return &id
+}
+
+// user 规范化模式下设置用户的国籍地区ID
+func (d *dimensionSet) user(u *models.User) {
+	if !normalized() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	u.RegionID = d.id("regions", u.Nationality)
+}
+
+// product 规范化模式下设置产品的分类、制造商、供应商与产地ID
+func (d *dimensionSet) product(p *models.Product) {
+	if !normalized() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	if _, ok := d.regionOf[p.Manufacturer]; !ok {
+		d.regionOf[p.Manufacturer] = p.CountryOfOrigin
+	}
+	p.CategoryID = d.id("categories", p.Category)
+	p.ManufacturerID = d.id("manufacturers", p.Manufacturer)
+	p.SupplierID = d.id("suppliers", p.Supplier)
+	p.OriginRegionID = d.id("regions", p.CountryOfOrigin)
+}
+
+// order 规范化模式下设置订单的支付方式ID
+func (d *dimensionSet) order(o *models.Order) {
+	if !normalized() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	o.PaymentMethodID = d.id("payment_methods", o.PaymentMethod)
+}
+
+// payment 规范化模式下设置支付记录的支付方式ID
+func (d *dimensionSet) payment(p *models.Payment) {
+	if !normalized() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	p.MethodID = d.id("payment_methods", p.Method)
+}
+
+// name 返回维度表中主键对应的名称，主键为空或不在映射中时返回空串；调用方需持有锁
+func (d *dimensionSet) name(table string, id *uint) string {
+	if id == nil || *id == 0 || int(*id) > len(d.names[table]) {
+		return ""
+	}
+	return d.names[table][*id-1]
+}
+
+// nameUsers 规范化模式下按维度ID回填从库中读回的用户的国籍：规范化模式不建文本列，读回的行只有维度ID
+func (d *dimensionSet) nameUsers(users []models.User) {
+	if !normalized() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range users {
+		users[i].Nationality = d.name("regions", users[i].RegionID)
+	}
+}
+
+// nameProducts 规范化模式下按维度ID回填从库中读回的产品的分类、制造商、供应商与产地名称，
+// 否则食品按默认税率计税、搜索词为空
+func (d *dimensionSet) nameProducts(products []models.Product) {
+	if !normalized() {
+		return
+	}
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	for i := range products {
+		p := &products[i]
+		p.Category = d.name("categories", p.CategoryID)
+		p.Manufacturer = d.name("manufacturers", p.ManufacturerID)
+		p.Supplier = d.name("suppliers", p.SupplierID)
+		p.CountryOfOrigin = d.name("regions", p.OriginRegionID)
+	}
+}
+
+// dimensionTables 维度表，按写入顺序排列
+var dimensionTables = []string{"categories", "manufacturers", "suppliers", "regions", "payment_methods"}
+
+// row 维度表中的一行；制造商另有所在地区ID，调用方需持有锁
+func (d *dimensionSet) row(table string, id uint) map[string]interface{} {
+	name := d.names[table][id-1]
+	r := map[string]interface{}{"id": id, "name": name}
+	if table == "manufacturers" {
+		var region *uint
+		if country, ok := d.regionOf[name]; ok {
+			region = d.id("regions", country)
+		}
+		r["region_id"] = region
+	}
+	return r
+}
+
+// pending 取出各维度表中尚未写入的行
+func (d *dimensionSet) pending() map[string][]map[string]interface{} {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	out := make(map[string][]map[string]interface{})
+	// 制造商的所在地区可能是新名称，先处理制造商再处理地区
+	for _, table := range dimensionTables {
+		for id := d.written[table] + 1; id <= len(d.names[table]); id++ {
+			out[table] = append(out[table], d.row(table, uint(id)))
+		}
+		d.written[table] = len(d.names[table])
+	}
+	return out
+}
+
+// records 维度表的全部行，按主键排列，用于导出 CSV
+func (d *dimensionSet) records(table string) [][]string {
+	d.mu.Lock()
+	defer d.mu.Unlock()
+	header := []string{"id", "name"}
+	if table == "manufacturers" {
+		header = append(header, "region_id")
+	}
+	out := [][]string{header}
+	for id := 1; id <= len(d.names[table]); id++ {
+		r := d.row(table, uint(id))
+		record := []string{fmt.Sprint(id), r["name"].(string)}
+		if table == "manufacturers" {
+			region := csv.NullValue
+			if p := r["region_id"].(*uint); p != nil {
+				region = fmt.Sprint(*p)
+			}
+			record = append(record, region)
+		}
+		out = append(out, record)
+	}
+	return out
+}
+
+// flush 规范化模式下将新出现的维度行写入数据库，主键已存在时覆盖名称，便于重复运行
+func (d *dimensionSet) flush(db *gorm.DB) {
+	if !normalized() {
+		return
+	}
+	for table, rows := range d.pending() {
+		columns := []string{"name"}
+		if table == "manufacturers" {
+			columns = append(columns, "region_id")
+		}
+		err := db.Table(table).Clauses(clause.OnConflict{
+			Columns:   []clause.Column{{Name: "id"}},
+			DoUpdates: clause.AssignmentColumns(columns),
+		}).Create(&rows).Error
+		if err != nil {
+			log.Printf("写入维度表 %s 失败: %v", table, err)
+		}
+	}
+}
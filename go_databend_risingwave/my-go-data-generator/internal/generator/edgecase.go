@@ -0,0 +1,45 @@
+package generator
+
+import (
+	"log"
+
+	"my-go-data-generator/internal/edgecase"
+	"my-go-data-generator/internal/manifest"
+)
+
+var (
+	// edges 边界字符串注入器，未配置 EDGE_CASE_RATES 时为 nil
+	edges *edgecase.Injector
+	// edgeManifest 记录哪些行注入了哪些边界字符串
+	edgeManifest *manifest.Writer
+)
+
+// configureEdgeCases 按配置创建注入器；清单文件由 openEdgeManifest 在写入数据前打开
+func configureEdgeCases() {
+	edges = edgecase.New(conf.EdgeCaseRates)
+}
+
+// openEdgeManifest 以追加方式打开边界字符串清单，只在批量生成与定时任务写入数据前调用，
+// migrate、validate 等操作不会改动清单；打开失败时退出，避免注入的行没有记录
+func openEdgeManifest() {
+	if edges == nil || edgeManifest != nil {
+		return
+	}
+	w, err := manifest.Open(conf.EdgeCaseManifest)
+	if err != nil {
+		log.Fatalf("打开边界字符串清单 %s 失败: %v", conf.EdgeCaseManifest, err)
+	}
+	edgeManifest = w
+	log.Printf("已启用边界字符串注入，清单追加写入 %s", conf.EdgeCaseManifest)
+}
+
+// recordEdgeCases 入库后按主键记录各行的注入情况，injections[i] 对应主键 id(i)
+func recordEdgeCases(table string, injections [][]edgecase.Injection, id func(i int) uint) {
+	for i, list := range injections {
+		for _, inj := range list {
+			if err := edgeManifest.Record(manifest.Entry{Table: table, ID: id(i), Column: inj.Column, Kind: inj.Case}); err != nil {
+				log.Printf("写入边界字符串清单失败: %v", err)
+			}
+		}
+	}
+}
@@ -0,0 +1,400 @@
+package generator
+
+import (
+	stdcsv "encoding/csv"
+	"fmt"
+	"log"
+	"os"
+	"slices"
+	"strconv"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+	"gorm.io/gorm/logger"
+	"gorm.io/gorm/schema"
+	"my-go-data-generator/internal/db"
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/schemaspec"
+)
+
+// scheduledChange 计划中的一条变更及其 ADD/MODIFY COLUMN 新列的生成器
+type scheduledChange struct {
+	*schemaspec.Change
+	column *specColumn // 新列的生成器；列交给数据库生成（或 MODIFY 未指定 gen 且类型无默认生成器）时为 nil
+}
+
+// evolvedTable 一张表在已执行的变更之后，实际列与写入代码所用列名之间的差异
+type evolvedTable struct {
+	added    []specColumn          // 新增且需要生成取值的列，按当前列名
+	renamed  map[string]string     // 代码中的列名 -> 当前列名
+	dropped  map[string]bool       // 已删除的代码中的列名
+	override map[string]specColumn // 当前列名 -> MODIFY COLUMN 时以 gen 指定的新生成器
+}
+
+var (
+	// evolution 尚未执行的变更，按执行时间排序；未配置 SCHEMA_EVOLUTION 时为空
+	evolution []*scheduledChange
+
+	evolutionMu sync.RWMutex
+	evolved     = map[string]*evolvedTable{}
+
+	// evolutionSeq 新增列生成取值的行序号，从启动时刻的纳秒数起，重复运行时唯一列不冲突
+	evolutionSeq atomic.Int64
+
+	evolutionLog *stdcsv.Writer
+
+	dmlMu   sync.Mutex
+	lastDML struct {
+		at        time.Time
+		op, table string
+	}
+)
+
+// evolutionHeader 表结构变更日志的表头
+var evolutionHeader = []string{
+	"line", "after", "table", "kind", "column", "new_name", "started_at", "finished_at",
+	"max_id_before", "max_id_after", "binlog_before", "binlog_after", "last_dml_at", "last_dml", "error", "sql",
+}
+
+// queriedColumns 定时任务在 WHERE、JOIN、SELECT 或历史快照中直接引用的列。installEvolution 只改写 VALUES 与 SET，
+// 这些列改名或删除后查询会失败，因此计划中不允许对它们 RENAME 或 DROP；各表的主键另由 fixedColumns 加入
+var queriedColumns = map[string][]string{
+	"users":       {"username", "status", "updated_at", "deleted_at"},
+	"products":    {"product_name", "updated_at"},
+	"orders":      {"order_number", "user_id", "product_id", "order_date", "order_status", "total_amount", "updated_at", "deleted_at"},
+	"order_items": {"order_id"},
+	"payments":    {"order_id", "status"},
+	"type_zoo":    {"created_at"},
+}
+
+// fixedColumns 不能改名或删除的列：queriedColumns 加上各表的主键（模型表为 id，表结构说明中的表为其主键列）
+func fixedColumns() map[string][]string {
+	fixed := make(map[string][]string, len(queriedColumns))
+	for table, columns := range queriedColumns {
+		fixed[table] = slices.Clone(columns)
+	}
+	for _, model := range append(db.Models(conf.SchemaMode), &models.TypeZoo{}) {
+		if s, err := schema.Parse(model, &sync.Map{}, schema.NamingStrategy{}); err == nil {
+			fixed[s.Table] = append(fixed[s.Table], "id")
+		}
+	}
+	for _, t := range specTables {
+		if pk := t.PrimaryKey(); pk != nil {
+			fixed[t.Name] = append(fixed[t.Name], pk.Name)
+		}
+	}
+	return fixed
+}
+
+// configureEvolution 解析 SCHEMA_EVOLUTION 并编译新列的生成器，计划有误时直接退出
+func configureEvolution() {
+	evolution = nil
+	if conf.SchemaEvolution == "" {
+		return
+	}
+	changes, err := schemaspec.ParseChangesFile(conf.SchemaEvolution, fixedColumns())
+	if err != nil {
+		log.Fatalf("表结构变更计划 %s 有误: %v", conf.SchemaEvolution, err)
+	}
+	for _, c := range changes {
+		sc := &scheduledChange{Change: c}
+		if c.Def != nil {
+			g, desc, err := columnGenerator(c.Table, c.Def)
+			switch {
+			case err != nil && (c.Kind == schemaspec.AddColumn || c.Def.Gen != ""):
+				log.Fatalf("表结构变更计划 %s 第 %d 行有误: %v", conf.SchemaEvolution, c.Line, err)
+			case refArgRe.MatchString(desc):
+				log.Fatalf("表结构变更计划 %s 第 %d 行有误: 新列不支持 ref 生成器", conf.SchemaEvolution, c.Line)
+			case g != nil:
+				col := newSpecColumn(c.Table, c.Def, g)
+				sc.column = &col
+			}
+		}
+		evolution = append(evolution, sc)
+	}
+	evolutionSeq.Store(time.Now().UnixNano())
+
+	file, err := os.Create(conf.SchemaEvolutionLog)
+	if err != nil {
+		log.Fatalf("创建表结构变更日志 %s 失败: %v", conf.SchemaEvolutionLog, err)
+	}
+	evolutionLog = stdcsv.NewWriter(file)
+	evolutionLog.Write(evolutionHeader)
+	evolutionLog.Flush()
+	log.Printf("已加载表结构变更计划 %d 条，执行日志写入 %s", len(evolution), conf.SchemaEvolutionLog)
+}
+
+// installEvolution 包装 INSERT 的 VALUES、UPDATE 的 SET 与 DELETE 子句的构建：按已执行的变更改写列，
+// 使模型、表结构说明与 type_zoo 的写入无需修改即可适应新的表结构，同时记录最近一次写入供变更日志使用。
+// 须在定时任务的写入开始前调用
+func installEvolution(conn *gorm.DB) {
+	if len(evolution) == 0 {
+		return
+	}
+	if conn.ClauseBuilders == nil {
+		conn.ClauseBuilders = map[string]clause.ClauseBuilder{}
+	}
+	for name, op := range map[string]string{"VALUES": "INSERT", "SET": "UPDATE", "DELETE": "DELETE"} {
+		build := conn.ClauseBuilders[name]
+		conn.ClauseBuilders[name] = func(c clause.Clause, builder clause.Builder) {
+			if stmt, ok := builder.(*gorm.Statement); ok && stmt.Table != "" {
+				c = evolveClause(stmt.Table, c)
+				noteDML(op, stmt.Table)
+			}
+			if build != nil {
+				build(c, builder)
+			} else {
+				c.Build(builder)
+			}
+		}
+	}
+}
+
+// noteDML 记录最近一次写入
+func noteDML(op, table string) {
+	dmlMu.Lock()
+	defer dmlMu.Unlock()
+	lastDML.at, lastDML.op, lastDML.table = time.Now(), op, table
+}
+
+// evolveClause 按 table 已执行的变更改写 VALUES 或 SET 子句
+func evolveClause(table string, c clause.Clause) clause.Clause {
+	evolutionMu.RLock()
+	defer evolutionMu.RUnlock()
+	t := evolved[table]
+	if t == nil {
+		return c
+	}
+	switch e := c.Expression.(type) {
+	case clause.Values:
+		c.Expression = t.values(e)
+	case clause.Set:
+		c.Expression = t.set(e)
+	}
+	return c
+}
+
+// values 去掉已删除的列，将改名的列换成新列名，按 MODIFY 指定的生成器重新取值，并为新增列生成取值
+func (t *evolvedTable) values(v clause.Values) clause.Values {
+	out := clause.Values{Columns: make([]clause.Column, 0, len(v.Columns)+len(t.added))}
+	index := make([]int, 0, len(v.Columns))
+	present := make(map[string]bool, len(v.Columns))
+	for i, col := range v.Columns {
+		if t.dropped[col.Name] {
+			continue
+		}
+		if name, ok := t.renamed[col.Name]; ok {
+			col.Name = name
+		}
+		out.Columns = append(out.Columns, col)
+		index = append(index, i)
+		present[col.Name] = true
+	}
+	var added []specColumn
+	for _, a := range t.added {
+		if !present[a.name] {
+			added = append(added, a)
+			out.Columns = append(out.Columns, clause.Column{Name: a.name})
+		}
+	}
+	now := time.Now()
+	out.Values = make([][]interface{}, len(v.Values))
+	for r, row := range v.Values {
+		ctx := fieldgen.NewContext(now, evolutionSeq.Add(1), nil)
+		values := make([]interface{}, 0, len(out.Columns))
+		for j, i := range index {
+			if o, ok := t.override[out.Columns[j].Name]; ok {
+				values = append(values, o.value(ctx))
+			} else {
+				values = append(values, row[i])
+			}
+		}
+		for _, a := range added {
+			values = append(values, a.value(ctx))
+		}
+		out.Values[r] = values
+	}
+	return out
+}
+
+// set 去掉已删除的列，将改名的列换成新列名
+func (t *evolvedTable) set(s clause.Set) clause.Set {
+	out := make(clause.Set, 0, len(s))
+	for _, a := range s {
+		if t.dropped[a.Column.Name] {
+			continue
+		}
+		if name, ok := t.renamed[a.Column.Name]; ok {
+			a.Column.Name = name
+		}
+		out = append(out, a)
+	}
+	return out
+}
+
+// addedIndex 新增列在 added 中的位置，不是新增列时返回 -1
+func (t *evolvedTable) addedIndex(name string) int {
+	return slices.IndexFunc(t.added, func(c specColumn) bool { return c.name == name })
+}
+
+// original 当前列名对应的代码中的列名
+func (t *evolvedTable) original(name string) string {
+	for o, n := range t.renamed {
+		if n == name {
+			return o
+		}
+	}
+	return name
+}
+
+// apply 在 DDL 执行成功后更新列的对应关系；对新增列的改名、改类型与删除直接作用于新增列本身
+func (t *evolvedTable) apply(c *scheduledChange) {
+	i := t.addedIndex(c.Column)
+	switch c.Kind {
+	case schemaspec.AddColumn:
+		if c.column != nil && i < 0 {
+			t.added = append(t.added, *c.column)
+		}
+	case schemaspec.ModifyColumn:
+		switch {
+		case i >= 0 && c.column != nil:
+			col := *c.column
+			col.name = t.added[i].name
+			t.added[i] = col
+		case i >= 0:
+			t.added = slices.Delete(t.added, i, i+1)
+		case c.Def.Gen != "" && c.column != nil:
+			t.override[c.Column] = *c.column
+		}
+	case schemaspec.RenameColumn:
+		if i >= 0 {
+			t.added[i].name = c.NewName
+			break
+		}
+		if o, ok := t.override[c.Column]; ok {
+			delete(t.override, c.Column)
+			o.name = c.NewName
+			t.override[c.NewName] = o
+		}
+		t.renamed[t.original(c.Column)] = c.NewName
+	case schemaspec.DropColumn:
+		if i >= 0 {
+			t.added = slices.Delete(t.added, i, i+1)
+			break
+		}
+		delete(t.override, c.Column)
+		o := t.original(c.Column)
+		delete(t.renamed, o)
+		t.dropped[o] = true
+	}
+}
+
+// streamEvolution 定时任务执行到期的表结构变更，并将每条 DDL 的执行情况写入变更日志
+func streamEvolution(conn *gorm.DB, now, start time.Time) {
+	for len(evolution) > 0 && !now.Before(start.Add(evolution[0].After)) {
+		c := evolution[0]
+		evolution = evolution[1:]
+		applyChange(conn, c)
+	}
+}
+
+// applyChange 执行一条变更。执行前后各记录一次表的最大主键与 binlog 位置，
+// 据此可以确定哪些写入在 DDL 之前、哪些在之后；执行成功后立即更新列的对应关系，随后的写入按新结构进行
+func applyChange(conn *gorm.DB, c *scheduledChange) {
+	dmlMu.Lock()
+	dmlAt, dml := lastDML.at, lastDML.op+" "+lastDML.table
+	dmlMu.Unlock()
+	maxBefore := maxID(conn, c.Table)
+	binlogBefore := binlogPosition(conn)
+
+	started := time.Now()
+	err := conn.Exec(c.SQL).Error
+	finished := time.Now()
+	if err == nil {
+		evolutionMu.Lock()
+		t := evolved[c.Table]
+		if t == nil {
+			t = &evolvedTable{renamed: map[string]string{}, dropped: map[string]bool{}, override: map[string]specColumn{}}
+			evolved[c.Table] = t
+		}
+		t.apply(c)
+		evolutionMu.Unlock()
+	}
+	maxAfter := maxID(conn, c.Table)
+	binlogAfter := binlogPosition(conn)
+
+	errText := ""
+	if err != nil {
+		errText = err.Error()
+		log.Printf("表结构变更（第 %d 行）执行失败: %v", c.Line, err)
+	} else {
+		log.Printf("已执行表结构变更（第 %d 行）: %s", c.Line, c.SQL)
+		syncHistory(conn, c.Table)
+	}
+	const layout = "2006-01-02 15:04:05.000000"
+	lastAt := ""
+	if !dmlAt.IsZero() {
+		lastAt = dmlAt.Format(layout)
+	} else {
+		dml = ""
+	}
+	evolutionLog.Write([]string{
+		strconv.Itoa(c.Line), c.After.String(), c.Table, c.Kind, c.Column, c.NewName,
+		started.Format(layout), finished.Format(layout), maxBefore, maxAfter, binlogBefore, binlogAfter,
+		lastAt, dml, errText, c.SQL,
+	})
+	evolutionLog.Flush()
+	if err := evolutionLog.Error(); err != nil {
+		log.Printf("写入表结构变更日志失败: %v", err)
+	}
+}
+
+// syncHistory 记录历史版本的表结构变更后，同步历史表并丢弃缓存的源表列名
+func syncHistory(conn *gorm.DB, table string) {
+	historyMu.Lock()
+	delete(historyColumns, table)
+	historyMu.Unlock()
+	if !conf.RowHistory || !slices.Contains(db.HistoryTables, table) {
+		return
+	}
+	if err := db.SyncHistoryTable(conn, table); err != nil {
+		log.Printf("同步历史表 %s%s 失败: %v", table, db.HistorySuffix, err)
+	}
+}
+
+// maxID 表当前的最大主键，表结构说明中的表按其主键列，其余表按 id；查询失败时为空
+func maxID(conn *gorm.DB, table string) string {
+	pk := "id"
+	for _, t := range specTables {
+		if t.Name == table && t.PrimaryKey() != nil {
+			pk = t.PrimaryKey().Name
+		}
+	}
+	var n int64
+	if err := conn.Raw(fmt.Sprintf("SELECT COALESCE(MAX(%s), 0) FROM %s", pk, table)).Scan(&n).Error; err != nil {
+		return ""
+	}
+	return strconv.FormatInt(n, 10)
+}
+
+// binlogStatements 查询当前 binlog 位置的语句，MySQL 8.4 起为前者
+var binlogStatements = []string{"SHOW BINARY LOG STATUS", "SHOW MASTER STATUS"}
+
+// binlogPosition 当前的 binlog 文件与位置，如 binlog.000012:4711；未开启 binlog 或没有权限时为空
+func binlogPosition(conn *gorm.DB) string {
+	quiet := conn.Session(&gorm.Session{Logger: logger.Discard})
+	for _, q := range binlogStatements {
+		var r struct {
+			File     string `gorm:"column:File"`
+			Position uint64 `gorm:"column:Position"`
+		}
+		if err := quiet.Raw(q).Scan(&r).Error; err == nil && r.File != "" {
+			return fmt.Sprintf("%s:%d", r.File, r.Position)
+		}
+	}
+	return ""
+}
@@ -0,0 +1,234 @@
+package generator
+
+import (
+	"log"
+	"path/filepath"
+	"sync"
+
+	"my-go-data-generator/internal/csv"
+	"my-go-data-generator/internal/models"
+)
+
+// csvExporter 针对每个表建立单独的 CSV 写入 channel 和 writer goroutine
+// 未配置 CSV_DIR 时为 nil，此时所有方法均不做任何事
+type csvExporter struct {
+	users      chan []string
+	products   chan []string
+	orders     chan []string
+	orderItems chan []string
+	payments   chan []string
+	refunds    chan []string
+	shipments  chan []string
+	reviews    chan []string
+	movements  chan []string
+	pageViews  chan []string
+	dir        string
+	wg         sync.WaitGroup
+
+	specMu sync.Mutex
+	spec   map[string]chan []string // 表结构说明中的表，首次写入时创建
+}
+
+// newCSVExporter 在 dir 下为每个表创建一个 CSV 文件（如 users.csv、order_items.csv）并写入表头
+func newCSVExporter(dir string) *csvExporter {
+	if dir == "" {
+		return nil
+	}
+	e := &csvExporter{
+		users:      make(chan []string, 1000),
+		products:   make(chan []string, 1000),
+		orders:     make(chan []string, 1000),
+		orderItems: make(chan []string, 1000),
+		payments:   make(chan []string, 1000),
+		refunds:    make(chan []string, 1000),
+		shipments:  make(chan []string, 1000),
+		reviews:    make(chan []string, 1000),
+		movements:  make(chan []string, 1000),
+		pageViews:  make(chan []string, 1000),
+		dir:        dir,
+	}
+	e.wg.Add(10)
+	go csv.WriteConcurrently(filepath.Join(dir, "users.csv"), e.users, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "products.csv"), e.products, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "orders.csv"), e.orders, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "order_items.csv"), e.orderItems, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "payments.csv"), e.payments, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "refunds.csv"), e.refunds, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "shipment_events.csv"), e.shipments, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "reviews.csv"), e.reviews, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "inventory_movements.csv"), e.movements, &e.wg)
+	go csv.WriteConcurrently(filepath.Join(dir, "page_views.csv"), e.pageViews, &e.wg)
+	e.users <- project("users", csv.UserHeader, csv.UserHeader)
+	e.products <- project("products", csv.ProductHeader, csv.ProductHeader)
+	e.orders <- project("orders", csv.OrderHeader, csv.OrderHeader)
+	e.orderItems <- csv.OrderItemHeader
+	e.payments <- project("payments", csv.PaymentHeader, csv.PaymentHeader)
+	e.refunds <- csv.RefundHeader
+	e.shipments <- csv.ShipmentEventHeader
+	e.reviews <- csv.ReviewHeader
+	e.movements <- csv.InventoryMovementHeader
+	e.pageViews <- csv.PageViewHeader
+	return e
+}
+
+func (e *csvExporter) writeUsers(users []models.User) {
+	if e == nil {
+		return
+	}
+	for i := range users {
+		e.users <- project("users", csv.UserHeader, csv.UserRecord(&users[i]))
+	}
+}
+
+func (e *csvExporter) writeProducts(products []models.Product) {
+	if e == nil {
+		return
+	}
+	for i := range products {
+		e.products <- project("products", csv.ProductHeader, csv.ProductRecord(&products[i]))
+	}
+}
+
+func (e *csvExporter) writeOrders(orders []models.Order) {
+	if e == nil {
+		return
+	}
+	for i := range orders {
+		e.orders <- project("orders", csv.OrderHeader, csv.OrderRecord(&orders[i]))
+	}
+}
+
+func (e *csvExporter) writeOrderItems(items []models.OrderItem) {
+	if e == nil {
+		return
+	}
+	for i := range items {
+		e.orderItems <- csv.OrderItemRecord(&items[i])
+	}
+}
+
+func (e *csvExporter) writePayments(payments []models.Payment) {
+	if e == nil {
+		return
+	}
+	for i := range payments {
+		e.payments <- project("payments", csv.PaymentHeader, csv.PaymentRecord(&payments[i]))
+	}
+}
+
+func (e *csvExporter) writeRefunds(refunds []models.Refund) {
+	if e == nil {
+		return
+	}
+	for i := range refunds {
+		e.refunds <- csv.RefundRecord(&refunds[i])
+	}
+}
+
+func (e *csvExporter) writeShipmentEvents(events []models.ShipmentEvent) {
+	if e == nil {
+		return
+	}
+	for i := range events {
+		e.shipments <- csv.ShipmentEventRecord(&events[i])
+	}
+}
+
+func (e *csvExporter) writeReviews(reviews []models.Review) {
+	if e == nil {
+		return
+	}
+	for i := range reviews {
+		e.reviews <- csv.ReviewRecord(&reviews[i])
+	}
+}
+
+func (e *csvExporter) writeInventoryMovements(movements []models.InventoryMovement) {
+	if e == nil {
+		return
+	}
+	for i := range movements {
+		e.movements <- csv.InventoryMovementRecord(&movements[i])
+	}
+}
+
+func (e *csvExporter) writePageViews(views []models.PageView) {
+	if e == nil {
+		return
+	}
+	for i := range views {
+		e.pageViews <- csv.PageViewRecord(&views[i])
+	}
+}
+
+// writeDimensions 规范化模式下将各维度表整表导出为 CSV（如 categories.csv）
+func (e *csvExporter) writeDimensions(d *dimensionSet) {
+	if e == nil || !normalized() {
+		return
+	}
+	for _, table := range dimensionTables {
+		if err := csv.WriteToCSV(filepath.Join(e.dir, table+".csv"), d.records(table)); err != nil {
+			log.Printf("写入维度表 CSV %s 失败: %v", table, err)
+		}
+	}
+}
+
+// writeSpecRows 导出表结构说明中的表，CSV 只包含生成取值的列（自增主键等由数据库填写的列不在其中）
+func (e *csvExporter) writeSpecRows(t *specTable, rows []map[string]interface{}) {
+	if e == nil {
+		return
+	}
+	e.specMu.Lock()
+	ch, ok := e.spec[t.Name]
+	if !ok {
+		if e.spec == nil {
+			e.spec = make(map[string]chan []string)
+		}
+		ch = make(chan []string, 1000)
+		e.spec[t.Name] = ch
+		e.wg.Add(1)
+		go csv.WriteConcurrently(filepath.Join(e.dir, t.Name+".csv"), ch, &e.wg)
+		ch <- t.header()
+	}
+	e.specMu.Unlock()
+	for _, row := range rows {
+		ch <- t.record(row)
+	}
+}
+
+// project 规范化模式下去掉记录中以维度ID替代的文本列，使 CSV 与表结构一致；header 为该表的完整表头
+func project(table string, header, record []string) []string {
+	dropped := models.NormalizedColumns[table]
+	if !normalized() || len(dropped) == 0 {
+		return record
+	}
+	out := make([]string, 0, len(record))
+	for i, column := range header {
+		if _, ok := dropped[column]; !ok {
+			out = append(out, record[i])
+		}
+	}
+	return out
+}
+
+// close 关闭所有 CSV 通道，等待 CSV 写入 goroutine 完成
+func (e *csvExporter) close() {
+	if e == nil {
+		return
+	}
+	close(e.users)
+	close(e.products)
+	close(e.orders)
+	close(e.orderItems)
+	close(e.payments)
+	close(e.refunds)
+	close(e.shipments)
+	close(e.reviews)
+	close(e.movements)
+	close(e.pageViews)
+	for _, ch := range e.spec {
+		close(ch)
+	}
+	e.wg.Wait()
+	log.Println("CSV文件写入完毕.")
+}
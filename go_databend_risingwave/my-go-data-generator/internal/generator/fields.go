@@ -0,0 +1,117 @@
+package generator
+
+import (
+	"log"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	"my-go-data-generator/internal/config"
+	"my-go-data-generator/internal/db"
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/pricing"
+)
+
+// fields 字段生成器注册表：模型上的 gen 标签加上 FIELD_GENERATORS 中的覆盖项
+var fields = mustRegistry(nil)
+
+func mustRegistry(overrides map[string]string) *fieldgen.Registry {
+	r, err := fieldgen.NewRegistry(overrides)
+	if err != nil {
+		log.Fatalf("字段生成器配置错误: %v", err)
+	}
+	return r
+}
+
+// derivedColumns 由领域逻辑从其他列推导、受 -action validate 不变量约束的列。
+// 覆盖这些列会使金额公式、生命周期或用户画像的相关性失效，因此不接受 FIELD_GENERATORS 覆盖
+var derivedColumns = map[string][]string{
+	"orders": {"product_id", "order_date", "quantity", "currency", "subtotal", "total_amount", "order_status",
+		"discount_amount", "tax_amount", "shipping_cost", "tracking_number", "shipped_at", "delivery_date",
+		"return_status", "is_gift", "gift_message", "created_at", "updated_at"},
+	"order_items": {"order_id", "line_number", "currency", "unit_price", "subtotal", "discount_amount",
+		"tax_amount", "line_total", "created_at"},
+	"users": {"age", "marital_status", "occupation", "education", "income", "nationality", "currency",
+		"preferred_language", "timezone", "registration_date", "last_login", "loyalty_points", "created_at"},
+	"products": {"category", "price", "discount", "rating", "number_of_reviews", "stock", "stock_status"},
+}
+
+// checkOverrides 拒绝对推导列的覆盖，按列名排序以便报错稳定
+func checkOverrides(overrides map[string]string) {
+	keys := make([]string, 0, len(overrides))
+	for key := range overrides {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+	for _, key := range keys {
+		table, column, _ := strings.Cut(key, ".")
+		if slices.Contains(derivedColumns[table], column) {
+			log.Fatalf("字段生成器配置错误: %s 由其他列推导，不能通过 FIELD_GENERATORS 覆盖", key)
+		}
+	}
+}
+
+// configureFields 按配置重建注册表，并预先编译全部模型（含维度表与 type_zoo）的 gen 标签与覆盖项以尽早发现错误
+func configureFields() {
+	checkOverrides(conf.FieldGenerators)
+	fields = mustRegistry(conf.FieldGenerators)
+	ctx := fieldgen.NewContext(time.Now(), 0, nil)
+	for _, row := range append(db.Models(config.SchemaNormalized), &models.TypeZoo{}) {
+		if err := fields.Fill(row, ctx); err != nil {
+			log.Fatalf("字段生成器配置错误: %v", err)
+		}
+	}
+}
+
+// fill 为带 gen 标签的字段生成取值，领域字段随后由各 newXxx 推导
+func fill(row interface{}, ctx *fieldgen.Context) {
+	if err := fields.Fill(row, ctx); err != nil {
+		log.Fatalf("生成字段失败: %v", err)
+	}
+}
+
+// applyOverrides 领域字段推导完成后再次应用配置覆盖项，保证配置优先；推导列已由 checkOverrides 排除
+func applyOverrides(row interface{}, ctx *fieldgen.Context) {
+	if err := fields.ApplyOverrides(row, ctx); err != nil {
+		log.Fatalf("生成字段失败: %v", err)
+	}
+}
+
+// userPool 将已入库的用户暴露给 ref(users) 生成器
+type userPool []models.User
+
+func (p userPool) Len() int      { return len(p) }
+func (p userPool) ID(i int) uint { return p[i].ID }
+
+// productPool 将已入库的产品暴露给 ref(products) 生成器
+type productPool []models.Product
+
+func (p productPool) Len() int      { return len(p) }
+func (p productPool) ID(i int) uint { return p[i].ID }
+
+// newRefs 构造订单生成所需的引用上下文
+func newRefs(users []models.User, products []models.Product) map[string]fieldgen.Pool {
+	return map[string]fieldgen.Pool{"users": userPool(users), "products": productPool(products)}
+}
+
+// pickedUser 返回 ref(users) 选中的用户；未通过 ref 选取时返回以人民币计价的空用户
+func pickedUser(ctx *fieldgen.Context) models.User {
+	if pool, ok := ctx.Refs["users"].(userPool); ok {
+		if i, ok := ctx.Picked["users"]; ok {
+			return pool[i]
+		}
+	}
+	return models.User{Currency: pricing.BaseCurrency}
+}
+
+// pickedProduct 返回 ref(products) 选中的产品
+func pickedProduct(ctx *fieldgen.Context) models.Product {
+	if pool, ok := ctx.Refs["products"].(productPool); ok {
+		if i, ok := ctx.Picked["products"]; ok {
+			return pool[i]
+		}
+	}
+	return models.Product{}
+}
@@ -9,48 +9,36 @@ import (
 	"time"
 
 	"gorm.io/gorm"
+	"my-go-data-generator/internal/anomaly"
+	"my-go-data-generator/internal/config"
+	"my-go-data-generator/internal/edgecase"
+	"my-go-data-generator/internal/fieldgen"
 	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+	"my-go-data-generator/internal/validate"
 )
 
 var (
-	// 记录数由 CalculateRecordCounts 动态计算得出
+	// 记录数由 planRecordCounts 按目标数据量动态计算得出
 	numUsers    int
 	numProducts int
 	numOrders   int
+	numSessions int // 浏览会话数，按 CLICKSTREAM_GB 单独规划
 )
 
-var (
-	genders        = []string{"男", "女", "其他"}
-	occupations    = []string{"工程师", "医生", "教师", "艺术家", "律师"}
-	maritalStatus  = []string{"未婚", "已婚", "离异"}
-	educationList  = []string{"高中", "本科", "硕士", "博士"}
-	productNames   = []string{"产品A", "产品B", "产品C", "产品D", "产品E"}
-	categories     = []string{"电子产品", "家居用品", "服装", "运动器材", "食品"}
-	paymentMethods = []string{"信用卡", "支付宝", "微信支付", "现金"}
-	orderStatuses  = []string{"待付款", "已付款", "待发货", "已发货", "已完成", "已取消"}
-	stockStatuses  = []string{"有货", "缺货", "预订"}
-)
-
-func init() {
-	// 以目标数据量 50GB 动态计算记录数
-	numUsers, numProducts, numOrders = CalculateRecordCounts(50) // 50GB
-	log.Printf("目标数据量设置：用户=%d, 产品=%d, 订单=%d", numUsers, numProducts, numOrders)
-}
-
-// CalculateRecordCounts 根据目标 GB 数据量及各表的预估平均行大小计算记录数
-// 假设比例：产品数量 = 用户数量/10，订单数量 = 用户数量*10
-func CalculateRecordCounts(totalGB int) (int, int, int) {
-	// 预估每行大小（单位：字节），需要根据实际字段长度和数据内容调试
-	estimatedUserRowSize := 300.0    // 例如用户记录约300字节
-	estimatedProductRowSize := 400.0 // 产品记录约400字节
-	estimatedOrderRowSize := 500.0   // 订单记录约500字节
+// conf 生成器配置，由 Configure 在启动时注入
+var conf = config.Default()
 
-	// 总体数据量 = 用户记录总字节 + 产品记录总字节 + 订单记录总字节
-	//               = u*estimatedUserRowSize + (u/10)*estimatedProductRowSize + (u*10)*estimatedOrderRowSize
-	factor := estimatedUserRowSize + estimatedProductRowSize/10 + 10*estimatedOrderRowSize
-	totalBytes := float64(totalGB) * 1024 * 1024 * 1024
-	u := totalBytes / factor
-	return int(u), int(u) / 10, int(u) * 10
+// Configure 设置生成器使用的配置，并据此重新规划各表记录数
+func Configure(c config.Config) {
+	conf = c
+	checkOrderStatuses()
+	configureFields()
+	configureSpec()
+	configureEvolution()
+	planRecordCounts()
+	configureEdgeCases()
+	configureAnomalies()
 }
 
 var (
@@ -60,28 +48,22 @@ var (
 )
 
 // GenerateData 并发生成用户、产品和订单数据，使用批量插入和并发提高性能
-// CSV 写入部分已暂时注释掉
+// 配置 CSV_DIR 后同时将数据导出为 CSV，NULL 写为 \N
 func GenerateData(db *gorm.DB) error {
 	rand.Seed(time.Now().UnixNano())
 	batchSize := 1000
+	openEdgeManifest()
+	openAnomalyManifest()
 
-	/*
-		// 以下CSV相关代码暂时注释掉
-		// 针对每个表建立单独的 CSV 写入 channel 和 writer goroutine
-		userCSVChan := make(chan []string, 1000)
-		productCSVChan := make(chan []string, 1000)
-		orderCSVChan := make(chan []string, 1000)
-		var csvWg sync.WaitGroup
-		csvWg.Add(3)
-		go WriteCSVConcurrently("users.csv", userCSVChan, &csvWg)
-		go WriteCSVConcurrently("products.csv", productCSVChan, &csvWg)
-		go WriteCSVConcurrently("orders.csv", orderCSVChan, &csvWg)
+	// 配置了 CSV_DIR 时同时导出 CSV 文件，记录在入库后写出以带上自增主键
+	exporter := newCSVExporter(conf.CSVDir)
+	defer exporter.close()
 
-		// 写入CSV文件的表头
-		userCSVChan <- []string{"ID", "Username", "Gender", "Age", "Email", "Phone", "Address", "Nationality", "Occupation", "MaritalStatus", "Education", "Hobby", "Income", "RegistrationDate", "LastLogin", "LoyaltyPoints", "PreferredLanguage", "Currency", "Timezone", "Status", "CreatedAt", "UpdatedAt"}
-		productCSVChan <- []string{"ID", "ProductName", "Category", "Description", "Price", "Stock", "SKU", "Manufacturer", "Weight", "Dimensions", "Color", "Material", "ReleaseDate", "WarrantyPeriod", "CountryOfOrigin", "Rating", "NumberOfReviews", "Discount", "StockStatus", "Supplier", "CreatedAt", "UpdatedAt"}
-		orderCSVChan <- []string{"ID", "OrderNumber", "UserID", "ProductID", "OrderDate", "Quantity", "TotalAmount", "PaymentMethod", "ShippingAddress", "BillingAddress", "OrderStatus", "DiscountAmount", "TaxAmount", "ShippingCost", "TrackingNumber", "DeliveryDate", "ReturnStatus", "CustomerNote", "InternalNote", "IsGift", "GiftMessage", "ExtraInfo", "CreatedAt", "UpdatedAt"}
-	*/
+	// 从已有数据接续行序号，重复运行时由序号生成的订单号、SKU、邮箱与电话不与上次写入的行冲突
+	userBase, productBase, orderBase := lastID(db, &models.User{}), lastID(db, &models.Product{}), lastID(db, &models.Order{})
+
+	// 规范化模式下先写入预置的维度行，各阶段结束后再补写生成过程中新出现的维度行
+	dims.flush(db)
 
 	// 使用并发批量插入，每个批次使用一个 goroutine。限制并发数防止过多 goroutine
 	maxWorkers := runtime.NumCPU() * 2
@@ -97,65 +79,37 @@ func GenerateData(db *gorm.DB) error {
 		go func(start int) {
 			defer wg.Done()
 			var users []models.User
+			var userEdges [][]edgecase.Injection
+			var userAnomalies [][]anomaly.Anomaly
+			duplicateOf := make(map[int]int) // 近似重复用户在批次中的下标 -> 原用户下标
 			for j := 0; j < batchSize && (start+j) < numUsers; j++ {
-				index := start + j + 1 // 保证唯一性
+				index := userBase + start + j + 1 // 保证唯一性
 				now := time.Now()
-				user := models.User{
-					Username:          fmt.Sprintf("用户%d", index),
-					Gender:            genders[rand.Intn(len(genders))],
-					Age:               rand.Intn(63) + 18,
-					Email:             fmt.Sprintf("user%d@example.com", index),
-					Phone:             fmt.Sprintf("138%08d", index),
-					Address:           fmt.Sprintf("地址%d", index),
-					Nationality:       "中国",
-					Occupation:        occupations[rand.Intn(len(occupations))],
-					MaritalStatus:     maritalStatus[rand.Intn(len(maritalStatus))],
-					Education:         educationList[rand.Intn(len(educationList))],
-					Hobby:             "阅读,旅行",
-					Income:            rand.Float64()*10000 + 3000,
-					RegistrationDate:  now.Add(-time.Hour * time.Duration(rand.Intn(10000))),
-					LastLogin:         now.Add(-time.Minute * time.Duration(rand.Intn(10000))),
-					LoyaltyPoints:     rand.Intn(1000),
-					PreferredLanguage: "中文",
-					Currency:          "CNY",
-					Timezone:          "CST",
-					Status:            "活跃",
-					CreatedAt:         now,
-					UpdatedAt:         now,
+				var user models.User
+				var found []anomaly.Anomaly
+				if src := rand.Intn(len(users) + 1); src < len(users) && !isDuplicate(duplicateOf, src) && anomalies.WantDuplicate() {
+					// 每个原用户最多被复制一次，保证派生的邮箱与电话唯一
+					user = anomaly.NearDuplicate(users[src], now)
+					duplicateOf[len(users)] = src
+					found = append(found, anomaly.Anomaly{Kind: anomaly.DuplicateUser})
+				} else {
+					ctx := fieldgen.NewContext(now, int64(index), nil)
+					user = newUser(ctx, false, now.Add(-randDuration(0, registrationSpan)))
+					found = anomalies.User(&user)
 				}
+				userAnomalies = append(userAnomalies, found)
+				userEdges = append(userEdges, edges.Apply("users", &user))
 				users = append(users, user)
-				/*
-					// CSV写入相关代码已暂时注释掉
-					csvRecord := []string{
-						strconv.Itoa(int(user.ID)),
-						user.Username,
-						user.Gender,
-						strconv.Itoa(user.Age),
-						user.Email,
-						user.Phone,
-						user.Address,
-						user.Nationality,
-						user.Occupation,
-						user.MaritalStatus,
-						user.Education,
-						user.Hobby,
-						fmt.Sprintf("%.2f", user.Income),
-						user.RegistrationDate.Format(time.RFC3339),
-						user.LastLogin.Format(time.RFC3339),
-						strconv.Itoa(user.LoyaltyPoints),
-						user.PreferredLanguage,
-						user.Currency,
-						user.Timezone,
-						user.Status,
-						user.CreatedAt.Format(time.RFC3339),
-						user.UpdatedAt.Format(time.RFC3339),
-					}
-					userCSVChan <- csvRecord
-				*/
 			}
 			if err := db.Create(&users).Error; err != nil {
 				log.Printf("批量插入用户数据失败: %v", err)
 			}
+			recordEdgeCases("users", userEdges, func(i int) uint { return users[i].ID })
+			for dup, src := range duplicateOf {
+				userAnomalies[dup][0].Detail = fmt.Sprintf("duplicate_of=%d", users[src].ID)
+			}
+			recordAnomalies("users", userAnomalies, func(i int) uint { return users[i].ID })
+			exporter.writeUsers(users)
 			mutexUsers.Lock()
 			allUsers = append(allUsers, users...)
 			mutexUsers.Unlock()
@@ -164,6 +118,7 @@ func GenerateData(db *gorm.DB) error {
 		}(i)
 	}
 	wg.Wait()
+	dims.flush(db)
 	log.Println("用户数据生成完毕.")
 
 	// 生成产品数据
@@ -175,65 +130,27 @@ func GenerateData(db *gorm.DB) error {
 		go func(start int) {
 			defer wg.Done()
 			var products []models.Product
+			var productEdges [][]edgecase.Injection
 			for j := 0; j < batchSize && (start+j) < numProducts; j++ {
-				index := start + j + 1
+				index := productBase + start + j + 1
 				now := time.Now()
-				product := models.Product{
-					ProductName:     productNames[rand.Intn(len(productNames))] + fmt.Sprintf(" %d", index),
-					Category:        categories[rand.Intn(len(categories))],
-					Description:     fmt.Sprintf("这是%s的描述", productNames[rand.Intn(len(productNames))]),
-					Price:           rand.Float64() * 1000,
-					Stock:           rand.Intn(5000),
-					SKU:             fmt.Sprintf("SKU%06d", index),
-					Manufacturer:    fmt.Sprintf("制造商%d", rand.Intn(100)),
-					Weight:          rand.Float64() * 10,
-					Dimensions:      fmt.Sprintf("%dx%dx%d", rand.Intn(100), rand.Intn(100), rand.Intn(100)),
-					Color:           []string{"红", "蓝", "绿", "黑", "白"}[rand.Intn(5)],
-					Material:        "塑料",
-					ReleaseDate:     now.AddDate(-rand.Intn(10), 0, 0),
-					WarrantyPeriod:  fmt.Sprintf("%d个月", rand.Intn(24)+1),
-					CountryOfOrigin: "中国",
-					Rating:          rand.Float64() * 5,
-					NumberOfReviews: rand.Intn(1000),
-					Discount:        rand.Float64() * 0.5,
-					StockStatus:     stockStatuses[rand.Intn(len(stockStatuses))],
-					Supplier:        fmt.Sprintf("供应商%d", rand.Intn(50)),
-					CreatedAt:       now,
-					UpdatedAt:       now,
-				}
+				product := newProduct(fieldgen.NewContext(now, int64(index), nil), fmt.Sprintf("%08d", index))
+				productEdges = append(productEdges, edges.Apply("products", &product))
 				products = append(products, product)
-				/*
-					// CSV写入相关代码已暂时注释掉
-					csvRecord := []string{
-						strconv.Itoa(int(product.ID)),
-						product.ProductName,
-						product.Category,
-						product.Description,
-						fmt.Sprintf("%.2f", product.Price),
-						strconv.Itoa(product.Stock),
-						product.SKU,
-						product.Manufacturer,
-						fmt.Sprintf("%.2f", product.Weight),
-						product.Dimensions,
-						product.Color,
-						product.Material,
-						product.ReleaseDate.Format(time.RFC3339),
-						product.WarrantyPeriod,
-						product.CountryOfOrigin,
-						fmt.Sprintf("%.2f", product.Rating),
-						strconv.Itoa(product.NumberOfReviews),
-						fmt.Sprintf("%.2f", product.Discount),
-						product.StockStatus,
-						product.Supplier,
-						product.CreatedAt.Format(time.RFC3339),
-						product.UpdatedAt.Format(time.RFC3339),
-					}
-					productCSVChan <- csvRecord
-				*/
 			}
 			if err := db.Create(&products).Error; err != nil {
 				log.Printf("批量插入产品数据失败: %v", err)
 			}
+			recordEdgeCases("products", productEdges, func(i int) uint { return products[i].ID })
+			// 产品入库后建立库存账本，初始库存记为一条入库流水，流水入库后才计入余额
+			if movements := inventory.open(products); len(movements) > 0 {
+				if err := db.CreateInBatches(&movements, batchSize).Error; err != nil {
+					log.Printf("批量插入库存流水失败: %v", err)
+				} else {
+					inventory.post(movements)
+					exporter.writeInventoryMovements(movements)
+				}
+			}
 			mutexProducts.Lock()
 			allProducts = append(allProducts, products...)
 			mutexProducts.Unlock()
@@ -242,94 +159,122 @@ func GenerateData(db *gorm.DB) error {
 		}(i)
 	}
 	wg.Wait()
+	dims.flush(db)
 	log.Println("产品数据生成完毕.")
 
 	// 生成订单数据
 	log.Println("开始生成订单数据...")
 	orderBatchSize := 1000
-	var countOrders int
+	// 用户与产品已全部入库，ref 生成器从中只读选取关联数据
+	refs := newRefs(allUsers, allProducts)
+	stats := newReviewStats()
+	var countOrders, countViolations int
 	for i := 0; i < numOrders; i += orderBatchSize {
 		wg.Add(1)
 		sem <- struct{}{}
 		go func(start int) {
 			defer wg.Done()
 			var orders []models.Order
+			var orderItems [][]models.OrderItem
+			var orderPays []orderPayments
+			var orderEvents [][]models.ShipmentEvent
+			var orderReviews [][]models.Review
+			var orderMoves [][]models.InventoryMovement
+			var orderViews [][]models.PageView
+			var orderEdges [][]edgecase.Injection
+			var orderAnomalies [][]anomaly.Anomaly
 			for j := 0; j < orderBatchSize && (start+j) < numOrders; j++ {
-				now := time.Now()
-				// 随机选择已生成的用户和产品作为关联数据
-				var user models.User
-				var product models.Product
-				mutexUsers.Lock()
-				if len(allUsers) > 0 {
-					user = allUsers[rand.Intn(len(allUsers))]
-				}
-				mutexUsers.Unlock()
-				mutexProducts.Lock()
-				if len(allProducts) > 0 {
-					product = allProducts[rand.Intn(len(allProducts))]
-				}
-				mutexProducts.Unlock()
-
-				order := models.Order{
-					OrderNumber:     fmt.Sprintf("ORD%010d", start+j+1),
-					UserID:          user.ID,
-					ProductID:       product.ID,
-					OrderDate:       now.Add(-time.Duration(rand.Intn(1000)) * time.Minute),
-					Quantity:        rand.Intn(10) + 1,
-					TotalAmount:     product.Price * float64(rand.Intn(10)+1),
-					PaymentMethod:   paymentMethods[rand.Intn(len(paymentMethods))],
-					ShippingAddress: fmt.Sprintf("收货地址%d", start+j+1),
-					BillingAddress:  fmt.Sprintf("账单地址%d", start+j+1),
-					OrderStatus:     orderStatuses[rand.Intn(len(orderStatuses))],
-					DiscountAmount:  rand.Float64() * 50,
-					TaxAmount:       rand.Float64() * 20,
-					ShippingCost:    rand.Float64() * 10,
-					TrackingNumber:  fmt.Sprintf("TRK%08d", rand.Intn(100000000)),
-					DeliveryDate:    now.Add(time.Duration(rand.Intn(1000)) * time.Minute),
-					ReturnStatus:    "无",
-					CustomerNote:    "请尽快发货",
-					InternalNote:    "内部备注信息",
-					IsGift:          rand.Intn(2) == 0,
-					GiftMessage:     "祝您购物愉快",
-					ExtraInfo:       "额外信息",
-					CreatedAt:       now,
-					UpdatedAt:       now,
+				ctx := fieldgen.NewContext(time.Now(), int64(orderBase+start+j+1), refs)
+				order, items, products := newOrder(ctx)
+				if vs := validate.Order(&order, items, products); len(vs) > 0 {
+					mutexOrders.Lock()
+					countViolations += len(vs)
+					mutexOrders.Unlock()
+					log.Printf("生成的订单不满足不变量: %v", vs[0])
 				}
+				// 支付、退款与物流轨迹按注入缺陷之前的订单生成
+				orderPays = append(orderPays, newPayments(&order, ctx.Now))
+				orderEvents = append(orderEvents, newShipmentEvents(&order, ctx.Now))
+				orderReviews = append(orderReviews, newReviews(&order, items, ctx.Now))
+				orderMoves = append(orderMoves, inventory.orderMovements(&order, items, ctx.Now))
+				orderViews = append(orderViews, newConversionSession(ctx, &order, items))
+				orderEdges = append(orderEdges, edges.Apply("orders", &order))
+				orderAnomalies = append(orderAnomalies, anomalies.Order(&order))
 				orders = append(orders, order)
-				/*
-					// CSV写入相关代码已暂时注释掉
-					csvRecord := []string{
-						strconv.Itoa(int(order.ID)),
-						order.OrderNumber,
-						strconv.Itoa(int(order.UserID)),
-						strconv.Itoa(int(order.ProductID)),
-						order.OrderDate.Format(time.RFC3339),
-						strconv.Itoa(order.Quantity),
-						fmt.Sprintf("%.2f", order.TotalAmount),
-						order.PaymentMethod,
-						order.ShippingAddress,
-						order.BillingAddress,
-						order.OrderStatus,
-						fmt.Sprintf("%.2f", order.DiscountAmount),
-						fmt.Sprintf("%.2f", order.TaxAmount),
-						fmt.Sprintf("%.2f", order.ShippingCost),
-						order.TrackingNumber,
-						order.DeliveryDate.Format(time.RFC3339),
-						order.ReturnStatus,
-						order.CustomerNote,
-						order.InternalNote,
-						fmt.Sprintf("%t", order.IsGift),
-						order.GiftMessage,
-						order.ExtraInfo,
-						order.CreatedAt.Format(time.RFC3339),
-						order.UpdatedAt.Format(time.RFC3339),
-					}
-					orderCSVChan <- csvRecord
-				*/
+				orderItems = append(orderItems, items)
 			}
+			var items []models.OrderItem
+			var payments []models.Payment
+			var refunds []models.Refund
+			var events []models.ShipmentEvent
+			var reviews []models.Review
+			var movements []models.InventoryMovement
+			var views []models.PageView
 			if err := db.Create(&orders).Error; err != nil {
 				log.Printf("批量插入订单数据失败: %v", err)
+				for _, moves := range orderMoves {
+					inventory.discard(moves)
+				}
+			} else {
+				// 订单入库取得主键后再插入订单行与支付记录，支付记录入库后再插入退款
+				items = linkOrderItems(orders, orderItems)
+				if err := db.CreateInBatches(&items, batchSize).Error; err != nil {
+					log.Printf("批量插入订单行数据失败: %v", err)
+				}
+				payments = linkPayments(orders, orderPays)
+				if err := db.CreateInBatches(&payments, batchSize).Error; err != nil {
+					log.Printf("批量插入支付数据失败: %v", err)
+				} else if refunds = linkRefunds(orders, orderPays, payments); len(refunds) > 0 {
+					if err := db.CreateInBatches(&refunds, batchSize).Error; err != nil {
+						log.Printf("批量插入退款数据失败: %v", err)
+					}
+				}
+				// 已发生的物流事件立即写入，未来的事件由定时任务到点后追加
+				if events = linkShipmentEvents(orders, orderEvents, time.Now()); len(events) > 0 {
+					if err := db.CreateInBatches(&events, batchSize).Error; err != nil {
+						log.Printf("批量插入物流事件失败: %v", err)
+					}
+				}
+				// 只有写入成功的评论才计入产品评分
+				if reviews = linkReviews(orders, orderReviews); len(reviews) > 0 {
+					if err := db.CreateInBatches(&reviews, batchSize).Error; err != nil {
+						log.Printf("批量插入评论失败: %v", err)
+						reviews = nil
+					} else {
+						stats.add(reviews)
+					}
+				}
+				// 库存流水入库成功后才计入账本余额，失败时释放占用，使回写的库存与已入库的流水一致
+				if movements = linkMovements(orders, orderMoves); len(movements) > 0 {
+					if err := db.CreateInBatches(&movements, batchSize).Error; err != nil {
+						log.Printf("批量插入库存流水失败: %v", err)
+						movements = nil
+					}
+				}
+				for _, moves := range orderMoves {
+					if movements != nil {
+						inventory.post(moves)
+					} else {
+						inventory.discard(moves)
+					}
+				}
+				// 促成订单的会话，其下单事件关联订单
+				if views = linkPageViews(orders, orderViews); len(views) > 0 {
+					if err := db.CreateInBatches(&views, batchSize).Error; err != nil {
+						log.Printf("批量插入点击流事件失败: %v", err)
+					}
+				}
 			}
+			recordEdgeCases("orders", orderEdges, func(i int) uint { return orders[i].ID })
+			recordAnomalies("orders", orderAnomalies, func(i int) uint { return orders[i].ID })
+			exporter.writeOrders(orders)
+			exporter.writeOrderItems(items)
+			exporter.writePayments(payments)
+			exporter.writeRefunds(refunds)
+			exporter.writeShipmentEvents(events)
+			exporter.writeReviews(reviews)
+			exporter.writeInventoryMovements(movements)
+			exporter.writePageViews(views)
 			mutexOrders.Lock()
 			countOrders += len(orders)
 			mutexOrders.Unlock()
@@ -340,121 +285,205 @@ func GenerateData(db *gorm.DB) error {
 		}(i)
 	}
 	wg.Wait()
-	log.Println("订单数据生成完毕.")
+	dims.flush(db)
+	log.Printf("订单数据生成完毕. 不变量校验违规数: %d", countViolations)
 
-	/*
-		// 关闭所有 CSV 通道，等待 CSV 写入 goroutine 完成
-		close(userCSVChan)
-		close(productCSVChan)
-		close(orderCSVChan)
-		csvWg.Wait()
-		log.Println("CSV文件写入完毕.")
-	*/
+	// 生成浏览会话的点击流，数量与订单无关
+	log.Println("开始生成点击流数据...")
+	sessionBatchSize := 1000
+	var countSessions, countViews int
+	for i := 0; i < numSessions; i += sessionBatchSize {
+		wg.Add(1)
+		sem <- struct{}{}
+		go func(start int) {
+			defer wg.Done()
+			now := time.Now()
+			var views []models.PageView
+			n := 0
+			for ; n < sessionBatchSize && (start+n) < numSessions; n++ {
+				ctx := fieldgen.NewContext(now, int64(start+n+1), refs)
+				views = append(views, newBrowseSession(ctx, now.Add(-randDuration(time.Hour, sessionSpan)), now)...)
+			}
+			if err := db.CreateInBatches(&views, batchSize).Error; err != nil {
+				log.Printf("批量插入点击流事件失败: %v", err)
+			}
+			exporter.writePageViews(views)
+			mutexOrders.Lock()
+			countSessions += n
+			countViews += len(views)
+			if countSessions%(sessionBatchSize*10) == 0 {
+				log.Printf("已插入浏览会话：%d/%d，事件 %d 条", countSessions, numSessions, countViews)
+			}
+			mutexOrders.Unlock()
+			<-sem
+		}(i)
+	}
+	wg.Wait()
+	log.Printf("点击流数据生成完毕. 浏览会话 %d 个，事件 %d 条", countSessions, countViews)
+
+	// 覆盖各种数据类型边界值的 type_zoo，供 CDC 类型映射测试
+	generateTypeZoo(db)
+
+	// 表结构说明（SCHEMA_SPEC）中的表在内置表之后生成，可以引用已写入的用户、产品与订单
+	generateSpecTables(db, exporter, batchSize)
+
+	// 产品的评分与评论数由评论汇总得出，库存为库存流水的余额，回写后再导出产品 CSV
+	writeBackProducts(db, allProducts, stats, inventory, batchSize)
+	exporter.writeProducts(allProducts)
+	exporter.writeDimensions(dims)
+	if err := edgeManifest.Flush(); err != nil {
+		log.Printf("写入边界字符串清单失败: %v", err)
+	}
+	if n := edgeManifest.Count(); n > 0 {
+		log.Printf("共注入边界字符串 %d 处，清单见 %s", n, conf.EdgeCaseManifest)
+	}
+	if err := anomalyManifest.Flush(); err != nil {
+		log.Printf("写入缺陷清单失败: %v", err)
+	}
+	if n := anomalyManifest.Count(); n > 0 {
+		log.Printf("共注入数据质量缺陷 %d 处，清单见 %s", n, conf.AnomalyManifest)
+	}
 
 	return nil
 }
 
+// lastID 表中已有的最大主键（含软删除的行），作为本次批量生成的行序号起点；主键不小于已写入的行数，
+// 清理任务删除的行也不会使序号回退
+func lastID(db *gorm.DB, model interface{}) int {
+	var n int64
+	if err := db.Unscoped().Model(model).Select("COALESCE(MAX(id), 0)").Scan(&n).Error; err != nil {
+		log.Fatalf("读取已有数据的最大主键失败: %v", err)
+	}
+	return int(n)
+}
+
+// streamProductPool 定时插入订单时可选产品的数量（按主键倒序取最近入库的产品）
+const streamProductPool = 100
+
 // StartTimer 启动定时器，每30秒向三个表中分别插入一条新数据，并执行 JOIN 查询打印结果及当前运行时长
+// 配置了 CLICKSTREAM_EVENTS_PER_SECOND 时另起一个每秒追加点击流事件的任务
 func StartTimer(db *gorm.DB, startTime time.Time) {
+	openEdgeManifest()
+	openAnomalyManifest()
+	installEvolution(db)
+	timerStart := time.Now()
+	if conf.ClickstreamRate > 0 {
+		go streamClickstream(db)
+	}
 	ticker := time.NewTicker(30 * time.Second)
 	go func() {
 		for range ticker.C {
 			now := time.Now()
+			maintainPartitions(db, now)
+			streamEvolution(db, now, timerStart)
 			// 插入一条用户数据，确保手机号唯一
-			user := models.User{
-				Username:          fmt.Sprintf("定时用户%d", now.UnixNano()),
-				Gender:            genders[rand.Intn(len(genders))],
-				Age:               rand.Intn(63) + 18,
-				Email:             fmt.Sprintf("timed_user%d@example.com", now.UnixNano()),
-				Phone:             fmt.Sprintf("139%08d", now.UnixNano()%100000000),
-				Address:           "定时地址",
-				Nationality:       "中国",
-				Occupation:        occupations[rand.Intn(len(occupations))],
-				MaritalStatus:     maritalStatus[rand.Intn(len(maritalStatus))],
-				Education:         educationList[rand.Intn(len(educationList))],
-				Hobby:             "运动,音乐",
-				Income:            rand.Float64()*10000 + 3000,
-				RegistrationDate:  now,
-				LastLogin:         now,
-				LoyaltyPoints:     rand.Intn(1000),
-				PreferredLanguage: "中文",
-				Currency:          "CNY",
-				Timezone:          "CST",
-				Status:            "活跃",
-				CreatedAt:         now,
-				UpdatedAt:         now,
-			}
+			user := newUser(fieldgen.NewContext(now, now.UnixNano(), nil), true, now)
+			userAnomalies := anomalies.User(&user)
+			userEdges := edges.Apply("users", &user)
 			if err := db.Create(&user).Error; err != nil {
 				log.Printf("定时插入用户失败: %v", err)
 				continue
 			}
+			recordEdgeCases("users", [][]edgecase.Injection{userEdges}, func(int) uint { return user.ID })
+			recordAnomalies("users", [][]anomaly.Anomaly{userAnomalies}, func(int) uint { return user.ID })
 
 			// 插入一条产品数据
-			product := models.Product{
-				ProductName:     fmt.Sprintf("定时产品%d", now.UnixNano()),
-				Category:        categories[rand.Intn(len(categories))],
-				Description:     "定时生成的产品描述",
-				Price:           rand.Float64() * 1000,
-				Stock:           rand.Intn(5000),
-				SKU:             fmt.Sprintf("TSKU%06d", rand.Intn(1000000)),
-				Manufacturer:    "定时制造商",
-				Weight:          rand.Float64() * 10,
-				Dimensions:      fmt.Sprintf("%dx%dx%d", rand.Intn(100), rand.Intn(100), rand.Intn(100)),
-				Color:           []string{"红", "蓝", "绿", "黑", "白"}[rand.Intn(5)],
-				Material:        "定时材质",
-				ReleaseDate:     now,
-				WarrantyPeriod:  "12个月",
-				CountryOfOrigin: "中国",
-				Rating:          rand.Float64() * 5,
-				NumberOfReviews: rand.Intn(1000),
-				Discount:        rand.Float64() * 0.5,
-				StockStatus:     stockStatuses[rand.Intn(len(stockStatuses))],
-				Supplier:        "定时供应商",
-				CreatedAt:       now,
-				UpdatedAt:       now,
-			}
+			product := newProduct(fieldgen.NewContext(now, now.UnixNano(), nil), fmt.Sprintf("T%d", now.UnixNano()))
+			productEdges := edges.Apply("products", &product)
 			if err := db.Create(&product).Error; err != nil {
 				log.Printf("定时插入产品失败: %v", err)
 				continue
 			}
+			recordEdgeCases("products", [][]edgecase.Injection{productEdges}, func(int) uint { return product.ID })
+			if movements := inventory.open([]models.Product{product}); len(movements) > 0 {
+				if err := db.Create(&movements).Error; err != nil {
+					log.Printf("定时插入库存流水失败: %v", err)
+				} else {
+					inventory.post(movements)
+				}
+			}
 
-			// 插入一条订单数据，关联上述用户与产品
-			order := models.Order{
-				OrderNumber:     fmt.Sprintf("TORD%v", now.UnixNano()),
-				UserID:          user.ID,
-				ProductID:       product.ID,
-				OrderDate:       now,
-				Quantity:        rand.Intn(10) + 1,
-				TotalAmount:     product.Price * float64(rand.Intn(10)+1),
-				PaymentMethod:   paymentMethods[rand.Intn(len(paymentMethods))],
-				ShippingAddress: "定时收货地址",
-				BillingAddress:  "定时账单地址",
-				OrderStatus:     "待付款",
-				DiscountAmount:  rand.Float64() * 50,
-				TaxAmount:       rand.Float64() * 20,
-				ShippingCost:    rand.Float64() * 10,
-				TrackingNumber:  fmt.Sprintf("TTRK%v", rand.Intn(1000000)),
-				DeliveryDate:    now.Add(24 * time.Hour),
-				ReturnStatus:    "无",
-				CustomerNote:    "定时订单, 请尽快处理",
-				InternalNote:    "定时内部备注",
-				IsGift:          false,
-				GiftMessage:     "",
-				ExtraInfo:       "定时额外信息",
-				CreatedAt:       now,
-				UpdatedAt:       now,
+			// 插入一条订单数据，关联上述用户；主产品优先选中上述产品，其余订单行从最近入库的产品中选取
+			recent := []models.Product{product}
+			if err := db.Order("id DESC").Limit(streamProductPool).Find(&recent).Error; err != nil || len(recent) == 0 {
+				recent = []models.Product{product}
 			}
+			dims.nameProducts(recent)
+			// 上次运行留下的产品按当前库存建账
+			inventory.track(recent)
+			ctx := fieldgen.NewContext(now, now.UnixNano(), newRefs([]models.User{user}, recent))
+			order, items, _ := newOrder(ctx)
+			// 新下的订单处于待付款状态，其余生命周期字段按状态推导
+			order.OrderStatus = models.OrderStatusPendingPayment
+			order.OrderDate = now
+			applyLifecycle(&order, now)
+			pays := newPayments(&order, now)
+			moves := inventory.orderMovements(&order, items, now)
+			session := newConversionSession(ctx, &order, items)
+			orderEdges := edges.Apply("orders", &order)
+			orderAnomalies := anomalies.Order(&order)
 			if err := db.Create(&order).Error; err != nil {
 				log.Printf("定时插入订单失败: %v", err)
+				inventory.discard(moves)
 				continue
 			}
+			items = linkOrderItems([]models.Order{order}, [][]models.OrderItem{items})
+			if err := db.Create(&items).Error; err != nil {
+				log.Printf("定时插入订单行失败: %v", err)
+			}
+			// 新下的订单尚未支付，可能有失败或待支付的尝试，不会有退款
+			if payments := linkPayments([]models.Order{order}, []orderPayments{pays}); len(payments) > 0 {
+				if err := db.Create(&payments).Error; err != nil {
+					log.Printf("定时插入支付记录失败: %v", err)
+				}
+			}
+			// 下单即扣减库存，库存不足时的补货流水一并写入，随后回写涉及产品的库存余额
+			if movements := linkMovements([]models.Order{order}, [][]models.InventoryMovement{moves}); len(movements) > 0 {
+				if err := db.Create(&movements).Error; err != nil {
+					log.Printf("定时插入库存流水失败: %v", err)
+					inventory.discard(moves)
+				} else {
+					inventory.post(moves)
+				}
+			}
+			for _, it := range items {
+				if err := updateStock(db, inventory, it.ProductID, now); err != nil {
+					log.Printf("定时更新产品库存失败: %v", err)
+				}
+			}
+			if views := linkPageViews([]models.Order{order}, [][]models.PageView{session}); len(views) > 0 {
+				if err := db.Create(&views).Error; err != nil {
+					log.Printf("定时插入点击流事件失败: %v", err)
+				}
+			}
+			recordEdgeCases("orders", [][]edgecase.Injection{orderEdges}, func(int) uint { return order.ID })
+			recordAnomalies("orders", [][]anomaly.Anomaly{orderAnomalies}, func(int) uint { return order.ID })
+			if err := edgeManifest.Flush(); err != nil {
+				log.Printf("写入边界字符串清单失败: %v", err)
+			}
+			if err := anomalyManifest.Flush(); err != nil {
+				log.Printf("写入缺陷清单失败: %v", err)
+			}
+			dims.flush(db)
+			streamSpecTables(db, now)
+			streamTypeZoo(db, now)
+			streamMutations(db, now)
+
+			// 追加到点的物流事件，形成持续的只追加变更流
+			if due := dueShipmentEvents(now); len(due) > 0 {
+				if err := db.CreateInBatches(&due, 1000).Error; err != nil {
+					log.Printf("追加物流事件失败: %v", err)
+				} else {
+					log.Printf("追加物流事件 %d 条", len(due))
+				}
+			}
 
 			// 使用 JOIN 查询刚刚插入的定时订单数据
 			var joinedResult struct {
 				OrderNumber string
 				Username    string
 				ProductName string
-				TotalAmount float64
+				TotalAmount money.Amount
 			}
 			err := db.Table("orders").
 				Select("orders.order_number, users.username, products.product_name, orders.total_amount").
@@ -467,7 +496,7 @@ func StartTimer(db *gorm.DB, startTime time.Time) {
 				continue
 			}
 			elapsed := time.Since(startTime)
-			log.Printf("定时任务插入并查询成功：订单号：%s，用户：%s，产品：%s，金额：%.2f，运行时间：%s",
+			log.Printf("定时任务插入并查询成功：订单号：%s，用户：%s，产品：%s，金额：%s，运行时间：%s",
 				joinedResult.OrderNumber, joinedResult.Username, joinedResult.ProductName, joinedResult.TotalAmount, elapsed)
 		}
 	}()
@@ -0,0 +1,62 @@
+package generator
+
+import (
+	"fmt"
+	"strings"
+	"sync"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/db"
+)
+
+// 历史表记录的变更类型
+const (
+	historyUpdate = "update"
+	historyDelete = "delete"
+)
+
+var (
+	historyMu      sync.Mutex
+	historyColumns = map[string]string{} // 表 -> 以逗号连接的源表列名
+)
+
+// sourceColumns 源表的全部列名，首次使用时从数据库读取并缓存
+func sourceColumns(tx *gorm.DB, table string) (string, error) {
+	historyMu.Lock()
+	defer historyMu.Unlock()
+	if cols, ok := historyColumns[table]; ok {
+		return cols, nil
+	}
+	types, err := tx.Migrator().ColumnTypes(table)
+	if err != nil {
+		return "", err
+	}
+	names := make([]string, len(types))
+	for i, c := range types {
+		names[i] = "`" + c.Name() + "`"
+	}
+	cols := strings.Join(names, ", ")
+	historyColumns[table] = cols
+	return cols, nil
+}
+
+// recordHistory 在更新或删除 table 中主键为 ids 的行之前，将这些行的当前版本写入历史表：
+// 版本自行的 updated_at 起有效，到本次变更的时刻 at 为止。ROW_HISTORY 未开启时不做任何事。
+// 应与随后的变更在同一事务中执行，且变更须将 updated_at 设为 at，使相邻版本的有效期首尾相接
+func recordHistory(tx *gorm.DB, table, operation string, at time.Time, ids ...uint) error {
+	if !conf.RowHistory || len(ids) == 0 {
+		return nil
+	}
+	cols, err := sourceColumns(tx, table)
+	if err != nil {
+		return err
+	}
+	return tx.Exec(fmt.Sprintf("INSERT INTO %s%s (%s, valid_from, valid_to, operation) SELECT %s, updated_at, ?, ? FROM %s WHERE id IN ?",
+		table, db.HistorySuffix, cols, cols, table), at, operation, ids).Error
+}
+
+// asOf 返回以 t 为当前时间的会话，gorm 自动写入的 updated_at 与软删除的 deleted_at 都取该时刻
+func asOf(tx *gorm.DB, t time.Time) *gorm.DB {
+	return tx.Session(&gorm.Session{NowFunc: func() time.Time { return t }})
+}
@@ -0,0 +1,194 @@
+package generator
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"my-go-data-generator/internal/models"
+)
+
+const (
+	minRestock = 20  // 补货时在缺口之外至少多入库的数量
+	maxRestock = 500 // 补货时在缺口之外最多多入库的数量
+	// sellOutRate 补货只补足缺口、出库后即售罄的比例，使缺货状态在持续下单中仍会出现
+	sellOutRate = 0.3
+)
+
+// inventoryLedger 库存账本：所有库存流水都经由它生成，流水入库成功后再以 post 计入余额，
+// 保证产品的 Stock 始终等于其已入库流水数量之和且不为负
+type inventoryLedger struct {
+	mu       sync.Mutex
+	balance  map[uint]int  // 已入库流水的余额
+	reserved map[uint]int  // 已生成但尚未入库的订单流水中净出库的数量，生成新流水时视为已被占用
+	preorder map[uint]bool // 尚未发布的预订产品只按缺口备货，售完后仍为预订
+}
+
+func newInventoryLedger() *inventoryLedger {
+	return &inventoryLedger{balance: make(map[uint]int), reserved: make(map[uint]int), preorder: make(map[uint]bool)}
+}
+
+// inventory 全局库存账本，批量生成与定时任务共用
+var inventory = newInventoryLedger()
+
+// open 为已入库（已有主键）的产品建账，有初始库存的产品生成一条初始入库流水；
+// 余额从 0 起，初始入库流水写入成功后以 post 计入
+func (l *inventoryLedger) open(products []models.Product) []models.InventoryMovement {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	var out []models.InventoryMovement
+	for _, p := range products {
+		l.balance[p.ID] = 0
+		l.preorder[p.ID] = p.StockStatus == models.StockStatusPreOrder
+		if p.Stock > 0 {
+			out = append(out, models.InventoryMovement{
+				ProductID:    p.ID,
+				MovementType: models.MovementReceipt,
+				Quantity:     p.Stock,
+				Note:         ptr("初始入库"),
+				MovementAt:   p.CreatedAt,
+				CreatedAt:    p.CreatedAt,
+			})
+		}
+	}
+	return out
+}
+
+// track 为账本中还没有的产品（如上次运行留下的产品）按数据库中的库存建账，不生成流水
+func (l *inventoryLedger) track(products []models.Product) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, p := range products {
+		if _, ok := l.balance[p.ID]; !ok {
+			l.balance[p.ID] = p.Stock
+			l.preorder[p.ID] = p.StockStatus == models.StockStatusPreOrder
+		}
+	}
+}
+
+// orderMovements 生成订单引起的库存流水：每个订单行在下单时出库，库存不足时先补货；
+// 已取消订单在取消时释放库存，退款完成的退货订单在退货入库时加回库存。
+// 可用库存为余额减去其他未入库订单占用的数量，本订单的净出库随之占用；余额不变，
+// 订单与流水入库成功后以 post 计入，失败时以 discard 释放占用。
+// 关联订单的流水的 OrderID 需在订单入库后由 linkMovements 回填
+func (l *inventoryLedger) orderMovements(o *models.Order, items []models.OrderItem, now time.Time) []models.InventoryMovement {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	returned := o.ReturnStatus != nil && refundStatuses[*o.ReturnStatus] == models.RefundStatusRefunded
+	var out []models.InventoryMovement
+	add := func(productID uint, movementType string, quantity int, note string, at time.Time) {
+		if at.After(now) {
+			at = now
+		}
+		m := models.InventoryMovement{ProductID: productID, MovementType: movementType, Quantity: quantity, MovementAt: at, CreatedAt: now}
+		if note != "" {
+			m.Note = ptr(note)
+		}
+		out = append(out, m)
+	}
+	available := make(map[uint]int) // 本订单已处理的订单行之后各产品的可用库存
+	for _, it := range items {
+		balance, ok := available[it.ProductID]
+		if !ok {
+			balance = l.balance[it.ProductID] - l.reserved[it.ProductID]
+		}
+		if balance < it.Quantity {
+			restock := it.Quantity - balance
+			note := "按缺口备货"
+			if !l.preorder[it.ProductID] && rand.Float64() >= sellOutRate {
+				restock += minRestock + rand.Intn(maxRestock-minRestock+1)
+				note = "补货入库"
+			}
+			add(it.ProductID, models.MovementReceipt, restock, note, o.OrderDate.Add(-randDuration(time.Hour, 72*time.Hour)))
+			balance += restock
+		}
+		add(it.ProductID, models.MovementSale, -it.Quantity, "", o.OrderDate)
+		balance -= it.Quantity
+		switch {
+		case o.OrderStatus == models.OrderStatusCancelled:
+			add(it.ProductID, models.MovementAdjustment, it.Quantity, "订单取消，释放库存", o.OrderDate.Add(randDuration(5*time.Minute, 30*time.Minute)))
+			balance += it.Quantity
+		case returned && o.DeliveryDate != nil:
+			add(it.ProductID, models.MovementReturn, it.Quantity, "退货入库", o.DeliveryDate.Add(randDuration(24*time.Hour, 7*24*time.Hour)))
+			balance += it.Quantity
+		}
+		available[it.ProductID] = balance
+	}
+	for id, q := range netOutflow(out) {
+		l.reserved[id] += q
+	}
+	return out
+}
+
+// netOutflow 一组流水中各产品的净出库数量，净入库的产品不计入
+func netOutflow(moves []models.InventoryMovement) map[uint]int {
+	net := make(map[uint]int)
+	for _, m := range moves {
+		net[m.ProductID] += m.Quantity
+	}
+	out := make(map[uint]int)
+	for id, q := range net {
+		if q < 0 {
+			out[id] = -q
+		}
+	}
+	return out
+}
+
+// release 已入库的待付款订单被取消时释放库存：每个订单行一条调整流水。
+// 只生成流水而不改动余额，流水入库成功后再以 post 计入账本；调用前需以 track 为订单涉及的产品建账
+func (l *inventoryLedger) release(o *models.Order, items []models.OrderItem, now time.Time) []models.InventoryMovement {
+	out := make([]models.InventoryMovement, 0, len(items))
+	for _, it := range items {
+		id := o.ID
+		out = append(out, models.InventoryMovement{ProductID: it.ProductID, OrderID: &id, MovementType: models.MovementAdjustment,
+			Quantity: it.Quantity, Note: ptr("订单取消，释放库存"), MovementAt: now, CreatedAt: now})
+	}
+	return out
+}
+
+// post 将一笔订单（或一批初始入库、释放）已入库的流水计入账本余额，并释放 orderMovements 占用的数量
+func (l *inventoryLedger) post(moves []models.InventoryMovement) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for _, m := range moves {
+		l.balance[m.ProductID] += m.Quantity
+	}
+	for id, q := range netOutflow(moves) {
+		l.reserved[id] -= q
+	}
+}
+
+// discard 订单或流水未能入库时丢弃 orderMovements 生成的流水，只释放占用的数量，余额不变
+func (l *inventoryLedger) discard(moves []models.InventoryMovement) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	for id, q := range netOutflow(moves) {
+		l.reserved[id] -= q
+	}
+}
+
+// apply 按账本余额设置产品的 Stock 与 StockStatus
+func (l *inventoryLedger) apply(p *models.Product) {
+	l.mu.Lock()
+	defer l.mu.Unlock()
+	if balance, ok := l.balance[p.ID]; ok {
+		p.Stock = balance
+		p.StockStatus = stockStatus(balance, l.preorder[p.ID])
+	}
+}
+
+// linkMovements 订单入库后回填关联订单的流水（销售、退货、调整）的 OrderID，并展开为一个切片以便批量插入
+func linkMovements(orders []models.Order, movements [][]models.InventoryMovement) []models.InventoryMovement {
+	var out []models.InventoryMovement
+	for i := range orders {
+		id := orders[i].ID
+		for j := range movements[i] {
+			if movements[i][j].MovementType != models.MovementReceipt {
+				movements[i][j].OrderID = &id
+			}
+		}
+		out = append(out, movements[i]...)
+	}
+	return out
+}
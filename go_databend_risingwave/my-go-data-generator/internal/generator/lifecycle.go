@@ -0,0 +1,110 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"math/rand"
+	"slices"
+	"time"
+
+	"my-go-data-generator/internal/models"
+)
+
+var (
+	cancelNotes = []string{"超时未支付，系统自动取消", "用户主动取消", "库存不足，客服取消"}
+	returnNotes = []string{"商品与描述不符", "尺寸不合适", "质量问题", "七天无理由退货"}
+)
+
+// pendingPaymentTimeout 待付款订单的支付时限，超时后由定时任务自动取消
+const pendingPaymentTimeout = 30 * time.Minute
+
+// randDuration 返回 [min, max) 区间内的随机时长
+func randDuration(min, max time.Duration) time.Duration {
+	if max <= min {
+		return min
+	}
+	return min + time.Duration(rand.Int63n(int64(max-min)))
+}
+
+// orderAge 不同状态的订单距今的时间范围：待付款的订单只可能是最近下的，已完成的订单则更早
+func orderAge(status string) (time.Duration, time.Duration) {
+	const day = 24 * time.Hour
+	switch status {
+	case models.OrderStatusPendingPayment:
+		return 0, pendingPaymentTimeout
+	case models.OrderStatusPaid, models.OrderStatusPendingShip:
+		return 0, 2 * day
+	case models.OrderStatusShipped:
+		return 2 * day, 7 * day
+	case models.OrderStatusCompleted:
+		return 10 * day, 180 * day
+	default:
+		return 0, 180 * day
+	}
+}
+
+// checkOrderStatuses ORDER_STATUS_WEIGHTS 中只能出现已知的订单状态，否则生成的订单既不属于任何生命周期阶段，也无法通过校验
+func checkOrderStatuses() {
+	for _, w := range conf.OrderStatusWeights {
+		if !slices.Contains(models.OrderStatuses, w.Value) {
+			log.Fatalf("ORDER_STATUS_WEIGHTS 中的订单状态 %q 未知，可用的状态为 %v", w.Value, models.OrderStatuses)
+		}
+	}
+}
+
+// newOrderLifecycle 按配置的状态分布为订单选择状态，并据此填充生命周期字段
+func newOrderLifecycle(o *models.Order, now time.Time) {
+	o.OrderStatus = conf.OrderStatusWeights.Pick(rand.Float64())
+	min, max := orderAge(o.OrderStatus)
+	o.OrderDate = now.Add(-randDuration(min, max))
+	applyLifecycle(o, now)
+}
+
+// applyLifecycle 根据 OrderStatus 与 OrderDate 推导其余生命周期字段：
+// 发货前没有物流单号与送达日期，只有已完成的订单才可能有退货状态，只有礼物订单才有礼物留言
+func applyLifecycle(o *models.Order, now time.Time) {
+	o.TrackingNumber = nil
+	o.ShippedAt = nil
+	o.DeliveryDate = nil
+	o.ReturnStatus = nil
+	internalNote := ""
+
+	if o.IsShipped() {
+		shipped := o.OrderDate.Add(randDuration(2*time.Hour, 48*time.Hour))
+		delivery := shipped.Add(randDuration(24*time.Hour, 5*24*time.Hour))
+		if o.OrderStatus == models.OrderStatusCompleted && delivery.After(now) {
+			// 已完成订单的送达日期是实际送达时间，不能晚于当前时间
+			delivery = now
+		}
+		if shipped.After(delivery) {
+			shipped = delivery
+		}
+		o.ShippedAt = &shipped
+		o.DeliveryDate = &delivery
+		o.TrackingNumber = ptr(fmt.Sprintf("TRK%d%06d", shipped.Unix(), rand.Intn(1000000)))
+	}
+
+	switch o.OrderStatus {
+	case models.OrderStatusCompleted:
+		if rand.Float64() < conf.ReturnRate {
+			o.ReturnStatus = ptr(conf.ReturnStatusWeights.Pick(rand.Float64()))
+			internalNote = returnNotes[rand.Intn(len(returnNotes))]
+		}
+	case models.OrderStatusCancelled:
+		internalNote = cancelNotes[rand.Intn(len(cancelNotes))]
+	}
+	if internalNote != "" {
+		internalNote = textFor("orders", "internal_note", internalNote)
+	}
+	o.InternalNote = optional("orders", "internal_note", internalNote)
+
+	o.IsGift = rand.Float64() < conf.GiftRate
+	o.GiftMessage = nil
+	if o.IsGift {
+		o.GiftMessage = optional("orders", "gift_message", textFor("orders", "gift_message", ""))
+	}
+	o.CustomerNote = nil
+	if rand.Float64() < conf.CustomerNoteRate {
+		o.CustomerNote = optional("orders", "customer_note", textFor("orders", "customer_note", ""))
+	}
+}
@@ -0,0 +1,170 @@
+package generator
+
+import (
+	"log"
+	"math/rand"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/models"
+)
+
+// expireOrdersPerTick 定时任务每次最多自动取消的超时订单数
+const expireOrdersPerTick = 5
+
+// purgeOrderAge 已取消订单下单超过该时长后会被清理
+const purgeOrderAge = 90 * 24 * time.Hour
+
+// streamMutations 定时任务对存量数据的更新与删除，使变更流中除插入外还有 UPDATE 与 DELETE：
+// 随机一名活跃用户登录、超时未付款的订单自动取消、清理一名已注销用户与一笔早已取消的订单。
+// 删除在 SOFT_DELETE 时为写入 deleted_at 的软删除，否则为物理删除；开启 ROW_HISTORY 时每次变更前先记录原版本
+func streamMutations(db *gorm.DB, now time.Time) {
+	touchUser(db, now)
+	expireOrders(db, now)
+	purgeUser(db, now)
+	purgeOrder(db, now)
+}
+
+// pickID 在满足条件的行中按主键区间的随机位置选取一行，没有满足条件的行时返回 false；已软删除的行不会被选中
+func pickID(db *gorm.DB, model interface{}, query string, args ...interface{}) (uint, bool) {
+	var r struct{ Min, Max uint }
+	if err := db.Model(model).Select("MIN(id) AS min, MAX(id) AS max").Where(query, args...).Scan(&r).Error; err != nil || r.Max == 0 {
+		return 0, false
+	}
+	from := r.Min + uint(rand.Int63n(int64(r.Max-r.Min)+1))
+	var id uint
+	err := db.Model(model).Select("id").Where(query, args...).Where("id >= ?", from).Order("id").Limit(1).Scan(&id).Error
+	return id, err == nil && id > 0
+}
+
+// remove 执行删除的会话：SOFT_DELETE 时由 gorm 写入 deleted_at，否则跳过软删除直接物理删除
+func remove(tx *gorm.DB, now time.Time) *gorm.DB {
+	tx = asOf(tx, now)
+	if conf.SoftDelete {
+		return tx
+	}
+	return tx.Unscoped()
+}
+
+// touchUser 随机一名活跃用户登录，更新其最后登录时间
+func touchUser(db *gorm.DB, now time.Time) {
+	id, ok := pickID(db, &models.User{}, "status = ?", models.UserStatusActive)
+	if !ok {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := recordHistory(tx, "users", historyUpdate, now, id); err != nil {
+			return err
+		}
+		return asOf(tx, now).Model(&models.User{ID: id}).Update("last_login", now).Error
+	})
+	if err != nil {
+		log.Printf("定时更新用户登录时间失败: %v", err)
+	}
+}
+
+// expireOrders 待付款超过支付时限的订单由系统自动取消：订单改为已取消，待支付的支付关闭，并释放所占库存
+func expireOrders(db *gorm.DB, now time.Time) {
+	var orders []models.Order
+	err := db.Where("order_status = ? AND order_date < ?", models.OrderStatusPendingPayment, now.Add(-pendingPaymentTimeout)).
+		Order("id").Limit(expireOrdersPerTick).Find(&orders).Error
+	if err != nil {
+		log.Printf("查询超时未付款订单失败: %v", err)
+		return
+	}
+	for i := range orders {
+		o := &orders[i]
+		var items []models.OrderItem
+		if err := db.Where("order_id = ?", o.ID).Find(&items).Error; err != nil {
+			log.Printf("查询订单 %d 的订单行失败: %v", o.ID, err)
+			continue
+		}
+		ids := make([]uint, len(items))
+		for j, it := range items {
+			ids[j] = it.ProductID
+		}
+		var products []models.Product
+		if len(ids) > 0 {
+			if err := db.Find(&products, ids).Error; err != nil {
+				log.Printf("查询订单 %d 的产品失败: %v", o.ID, err)
+				continue
+			}
+		}
+		dims.nameProducts(products)
+		inventory.track(products)
+		note := optional("orders", "internal_note", textFor("orders", "internal_note", cancelNotes[0]))
+		var moves []models.InventoryMovement
+		err := db.Transaction(func(tx *gorm.DB) error {
+			if err := recordHistory(tx, "orders", historyUpdate, now, o.ID); err != nil {
+				return err
+			}
+			err := asOf(tx, now).Model(o).Updates(map[string]interface{}{"order_status": models.OrderStatusCancelled, "internal_note": note}).Error
+			if err != nil {
+				return err
+			}
+			err = tx.Model(&models.Payment{}).Where("order_id = ? AND status = ?", o.ID, models.PaymentStatusPending).
+				Update("status", models.PaymentStatusClosed).Error
+			if err != nil {
+				return err
+			}
+			if moves = inventory.release(o, items, now); len(moves) == 0 {
+				return nil
+			}
+			return tx.Create(&moves).Error
+		})
+		if err != nil {
+			log.Printf("自动取消订单 %d 失败: %v", o.ID, err)
+			continue
+		}
+		// 事务提交后才将释放的库存计入账本，失败时账本余额仍与已入库的流水一致
+		inventory.post(moves)
+		for _, it := range items {
+			if err := updateStock(db, inventory, it.ProductID, now); err != nil {
+				log.Printf("定时更新产品库存失败: %v", err)
+			}
+		}
+	}
+	if len(orders) > 0 {
+		log.Printf("自动取消超时未付款订单 %d 笔", len(orders))
+	}
+}
+
+// purgeUser 清理一名已注销的用户，其订单等关联数据保留
+func purgeUser(db *gorm.DB, now time.Time) {
+	id, ok := pickID(db, &models.User{}, "status = ?", models.UserStatusClosed)
+	if !ok {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := recordHistory(tx, "users", historyDelete, now, id); err != nil {
+			return err
+		}
+		return remove(tx, now).Delete(&models.User{}, id).Error
+	})
+	if err != nil {
+		log.Printf("清理已注销用户 %d 失败: %v", id, err)
+	}
+}
+
+// purgeOrder 清理一笔下单超过 purgeOrderAge 的已取消订单；物理删除时一并删除其订单行，
+// 支付记录与库存流水作为资金与库存凭证保留
+func purgeOrder(db *gorm.DB, now time.Time) {
+	id, ok := pickID(db, &models.Order{}, "order_status = ? AND order_date < ?", models.OrderStatusCancelled, now.Add(-purgeOrderAge))
+	if !ok {
+		return
+	}
+	err := db.Transaction(func(tx *gorm.DB) error {
+		if err := recordHistory(tx, "orders", historyDelete, now, id); err != nil {
+			return err
+		}
+		if !conf.SoftDelete {
+			if err := tx.Where("order_id = ?", id).Delete(&models.OrderItem{}).Error; err != nil {
+				return err
+			}
+		}
+		return remove(tx, now).Delete(&models.Order{}, id).Error
+	})
+	if err != nil {
+		log.Printf("清理已取消订单 %d 失败: %v", id, err)
+	}
+}
@@ -0,0 +1,52 @@
+package generator
+
+import (
+	"math/rand"
+	"unicode/utf8"
+
+	"my-go-data-generator/internal/text"
+)
+
+// optional 为可空列生成取值：value 为空表示该行业务上没有值，写入 NULL；
+// 否则按 conf 中 table.column 的 NULL/空字符串比例决定写入 NULL、"" 还是原值
+func optional(table, column, value string) *string {
+	if value == "" {
+		return nil
+	}
+	nullRate := conf.NullRates.Of(table, column)
+	emptyRate := conf.EmptyRates.Of(table, column)
+	switch r := rand.Float64(); {
+	case r < nullRate:
+		return nil
+	case r < nullRate+emptyRate:
+		empty := ""
+		return &empty
+	}
+	return &value
+}
+
+// ptr 返回 s 的指针，用于业务规则要求必须有值的可空列
+func ptr(s string) *string {
+	return &s
+}
+
+// textFor 按 table.column 配置的长度区间生成中文或英文文本；prefix 为业务相关的开头，计入总长度
+func textFor(table, column, prefix string) string {
+	r, ok := conf.TextLengths.Of(table, column)
+	if !ok {
+		return prefix
+	}
+	lang := text.Chinese
+	if rand.Float64() < conf.EnglishTextRate {
+		lang = text.English
+	}
+	n := text.Length(r.Min, r.Max)
+	if prefix == "" {
+		return text.Paragraph(lang, n)
+	}
+	rest := n - utf8.RuneCountInString(prefix) - 1
+	if rest <= 0 {
+		return prefix
+	}
+	return prefix + " " + text.Paragraph(lang, rest)
+}
@@ -0,0 +1,115 @@
+package generator
+
+import (
+	"math/rand"
+	"strconv"
+
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/pricing"
+)
+
+// maxItemPicks 为一个订单行挑选与同单其他行不重复的产品时的最大尝试次数
+const maxItemPicks = 5
+
+// lineAmounts 按 单价×数量 - 折扣 + 税费 计算订单行金额，金额使用订单的币种
+// 约一半订单行享受产品促销折扣，折扣率不超过 Product.Discount
+func lineAmounts(product models.Product, quantity int, currency string) pricing.Line {
+	discountRate := 0.0
+	if rand.Intn(2) == 0 {
+		discountRate = product.Discount
+	}
+	return pricing.ComputeLine(product.Price, quantity, discountRate, product.Category, currency)
+}
+
+// itemCount 按配置的分布选取订单行数，配置无效时为 1
+func itemCount() int {
+	n, err := strconv.Atoi(conf.ItemCountWeights.Pick(rand.Float64()))
+	if err != nil || n < 1 {
+		return 1
+	}
+	return n
+}
+
+// newOrderItems 为订单生成订单行，产品来自 gen 标签中的 ref(products)，同一订单内尽量不重复
+// 返回的 products 为各订单行所购产品，供金额校验使用
+func newOrderItems(ctx *fieldgen.Context, currency string) ([]models.OrderItem, map[uint]models.Product) {
+	n := itemCount()
+	items := make([]models.OrderItem, 0, n)
+	products := make(map[uint]models.Product, n)
+	for line := 1; line <= n; line++ {
+		var item models.OrderItem
+		for try := 0; try < maxItemPicks; try++ {
+			item = models.OrderItem{}
+			fill(&item, ctx)
+			if _, dup := products[item.ProductID]; !dup {
+				break
+			}
+		}
+		if _, dup := products[item.ProductID]; dup && line > 1 {
+			// 可选产品太少时不再追加订单行
+			break
+		}
+		product := pickedProduct(ctx)
+		amounts := lineAmounts(product, item.Quantity, currency)
+		item.LineNumber = line
+		item.Currency = currency
+		item.UnitPrice = amounts.UnitPrice
+		item.Subtotal = amounts.Subtotal
+		item.DiscountAmount = amounts.Discount
+		item.TaxAmount = amounts.Tax
+		item.LineTotal = amounts.Total
+		item.CreatedAt = ctx.Now
+		applyOverrides(&item, ctx)
+		items = append(items, item)
+		products[item.ProductID] = product
+	}
+	return items, products
+}
+
+// newOrder 生成一笔订单及其订单行：订单号、用户与支付方式来自 gen 标签，
+// 订单头金额为各订单行之和，运费按整单计算，状态与生命周期字段按配置的分布生成
+// 订单行的 OrderID 需在订单入库后由 linkOrderItems 回填
+func newOrder(ctx *fieldgen.Context) (models.Order, []models.OrderItem, map[uint]models.Product) {
+	var order models.Order
+	fill(&order, ctx)
+	user := pickedUser(ctx)
+	items, products := newOrderItems(ctx, user.Currency)
+
+	lines := make([]pricing.Line, len(items))
+	quantity := 0
+	for i, item := range items {
+		lines[i] = pricing.Line{Subtotal: item.Subtotal, Discount: item.DiscountAmount, Tax: item.TaxAmount}
+		quantity += item.Quantity
+	}
+	amounts := pricing.Sum(lines, quantity, user.Currency)
+	order.ProductID = items[0].ProductID
+	order.Quantity = quantity
+	order.Currency = user.Currency
+	order.Subtotal = amounts.Subtotal
+	order.TotalAmount = amounts.Total
+	order.DiscountAmount = amounts.Discount
+	order.TaxAmount = amounts.Tax
+	order.ShippingCost = amounts.Shipping
+	order.ShippingAddress = user.Address
+	order.BillingAddress = user.Address
+	order.ExtraInfo = optional("orders", "extra_info", textFor("orders", "extra_info", ""))
+	order.CreatedAt = ctx.Now
+	order.UpdatedAt = ctx.Now
+	newOrderLifecycle(&order, ctx.Now)
+	applyOverrides(&order, ctx)
+	dims.order(&order)
+	return order, items, products
+}
+
+// linkOrderItems 订单入库取得主键后回填各订单行的 OrderID，并展开为一个切片以便批量插入
+func linkOrderItems(orders []models.Order, items [][]models.OrderItem) []models.OrderItem {
+	var out []models.OrderItem
+	for i := range orders {
+		for j := range items[i] {
+			items[i][j].OrderID = orders[i].ID
+		}
+		out = append(out, items[i]...)
+	}
+	return out
+}
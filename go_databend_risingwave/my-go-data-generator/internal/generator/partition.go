@@ -0,0 +1,27 @@
+package generator
+
+import (
+	"log"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/db"
+)
+
+// partitionCheckInterval 定时任务维护分区的间隔
+const partitionCheckInterval = time.Hour
+
+// lastPartitionCheck 定时任务上次维护分区的时间
+var lastPartitionCheck time.Time
+
+// maintainPartitions 设置了 PARTITION_BY 时，定时任务每小时补齐未来分区并删除过期分区，
+// 使长时间运行的追加写入始终有可用的分区，分区的增删也混入 CDC 变更流
+func maintainPartitions(conn *gorm.DB, now time.Time) {
+	if conf.Partitioning.Unit == "" || now.Sub(lastPartitionCheck) < partitionCheckInterval {
+		return
+	}
+	lastPartitionCheck = now
+	if err := db.MaintainPartitions(conn, conf.Partitioning, now); err != nil {
+		log.Printf("定时维护分区失败: %v", err)
+	}
+}
@@ -0,0 +1,230 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"time"
+
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+	"my-go-data-generator/internal/pricing"
+)
+
+// maxFailedAttempts 一笔订单最多的失败支付尝试次数
+const maxFailedAttempts = 3
+
+var (
+	// paymentProviders 各支付方式可用的支付渠道
+	paymentProviders = map[string][]string{
+		"信用卡":  {"银联", "Visa", "Mastercard"},
+		"支付宝":  {"支付宝"},
+		"微信支付": {"财付通"},
+		"现金":   {"线下收款"},
+	}
+	paymentFailures = []string{"余额不足", "银行卡已过期", "风控拦截", "网络超时", "用户取消支付", "密码错误次数过多"}
+
+	// refundStatuses 订单退货状态对应的退款状态
+	refundStatuses = map[string]string{
+		"退货申请中": models.RefundStatusPending,
+		"退货中":   models.RefundStatusProcessing,
+		"已退货":   models.RefundStatusRefunded,
+		"退货被拒":  models.RefundStatusRejected,
+	}
+)
+
+// orderPayments 一笔订单的支付尝试与退款
+// refundOf[k] 为 refunds[k] 所退款的支付在 payments 中的下标，入库后由 linkRefunds 换成主键
+type orderPayments struct {
+	payments []models.Payment
+	refunds  []models.Refund
+	refundOf []int
+}
+
+// transactionID 按渠道生成交易号，现金支付没有交易号
+func transactionID(provider string, at time.Time) *string {
+	switch provider {
+	case "线下收款":
+		return nil
+	case "支付宝":
+		return ptr(fmt.Sprintf("%s2200%016d", at.Format("20060102"), rand.Int63n(1e16)))
+	case "财付通":
+		return ptr(fmt.Sprintf("4200%s%014d", at.Format("20060102"), rand.Int63n(1e14)))
+	default:
+		return ptr(fmt.Sprintf("CARD%s%012d", at.Format("060102150405"), rand.Int63n(1e12)))
+	}
+}
+
+// splitAmount 将订单总额拆成一笔或两笔部分支付，拆分后之和仍等于总额
+func splitAmount(total money.Amount, currency string) []money.Amount {
+	if total <= 0 || rand.Float64() >= conf.PartialPaymentRate {
+		return []money.Amount{total}
+	}
+	first := total.Mul(0.3+rand.Float64()*0.4, pricing.Decimals(currency))
+	if first <= 0 || first >= total {
+		return []money.Amount{total}
+	}
+	return []money.Amount{first, total - first}
+}
+
+// newPayments 按订单的支付方式、状态与生命周期生成支付尝试与退款：
+// 已支付订单的成功支付之和等于订单总额，且支付时间在下单之后、发货之前；
+// 未支付订单只有失败、待支付或已关闭的尝试；有退货状态的订单按支付逐笔生成退款
+func newPayments(o *models.Order, now time.Time) orderPayments {
+	var op orderPayments
+	providers, ok := paymentProviders[o.PaymentMethod]
+	if !ok {
+		providers = []string{o.PaymentMethod}
+	}
+	provider := providers[rand.Intn(len(providers))]
+
+	// 所有支付尝试都发生在下单之后、发货（或当前时间）之前
+	deadline := now
+	if o.ShippedAt != nil && o.ShippedAt.Before(deadline) {
+		deadline = *o.ShippedAt
+	}
+	at := o.OrderDate
+	next := func() time.Time {
+		at = at.Add(randDuration(10*time.Second, 5*time.Minute))
+		if at.After(deadline) {
+			at = deadline
+		}
+		return at
+	}
+	add := func(status string, amount money.Amount) *models.Payment {
+		created := next()
+		op.payments = append(op.payments, models.Payment{
+			AttemptNumber: len(op.payments) + 1,
+			Method:        o.PaymentMethod,
+			Provider:      provider,
+			Status:        status,
+			Amount:        amount,
+			Currency:      o.Currency,
+			CreatedAt:     created,
+		})
+		return &op.payments[len(op.payments)-1]
+	}
+
+	for i := 0; i < maxFailedAttempts && rand.Float64() < conf.PaymentFailureRate; i++ {
+		p := add(models.PaymentStatusFailed, o.TotalAmount)
+		p.FailureReason = ptr(paymentFailures[rand.Intn(len(paymentFailures))])
+		if o.PaymentMethod != models.PaymentMethodCash {
+			p.TransactionID = transactionID(provider, p.CreatedAt)
+		}
+	}
+	switch {
+	case o.IsPaid():
+		for _, amount := range splitAmount(o.TotalAmount, o.Currency) {
+			p := add(models.PaymentStatusSucceeded, amount)
+			paid := p.CreatedAt
+			p.PaidAt = &paid
+			p.TransactionID = transactionID(provider, paid)
+		}
+	case o.OrderStatus == models.OrderStatusPendingPayment:
+		if rand.Intn(2) == 0 {
+			add(models.PaymentStatusPending, o.TotalAmount)
+		}
+	case o.OrderStatus == models.OrderStatusCancelled:
+		if rand.Intn(2) == 0 {
+			add(models.PaymentStatusClosed, o.TotalAmount)
+		}
+	}
+
+	if o.ReturnStatus != nil {
+		newRefunds(&op, o, now)
+	}
+	for i := range op.payments {
+		dims.payment(&op.payments[i])
+	}
+	return op
+}
+
+// newRefunds 为有退货状态的订单生成退款：整单退款或只退商品金额（不退运费），
+// 按各笔成功支付的金额比例拆分，每笔退款不超过对应支付的金额
+func newRefunds(op *orderPayments, o *models.Order, now time.Time) {
+	status, ok := refundStatuses[*o.ReturnStatus]
+	if !ok {
+		status = models.RefundStatusPending
+	}
+	refundable := o.TotalAmount
+	if rand.Intn(2) == 0 {
+		refundable = o.TotalAmount - o.ShippingCost
+	}
+	if refundable <= 0 || o.TotalAmount <= 0 {
+		return
+	}
+
+	requested := o.OrderDate
+	if o.DeliveryDate != nil {
+		requested = *o.DeliveryDate
+	}
+	requested = requested.Add(randDuration(time.Hour, 7*24*time.Hour))
+	if requested.After(now) {
+		requested = now
+	}
+	var refunded *time.Time
+	if status == models.RefundStatusRefunded {
+		t := requested.Add(randDuration(time.Hour, 3*24*time.Hour))
+		if t.After(now) {
+			t = now
+		}
+		refunded = &t
+	}
+
+	var succeeded []int
+	for i := range op.payments {
+		if op.payments[i].Status == models.PaymentStatusSucceeded {
+			succeeded = append(succeeded, i)
+		}
+	}
+	remaining := refundable
+	for k, i := range succeeded {
+		p := op.payments[i]
+		amount := remaining
+		if k < len(succeeded)-1 {
+			amount = refundable.MulDiv(int64(p.Amount), int64(o.TotalAmount), pricing.Decimals(o.Currency))
+		}
+		if amount > p.Amount {
+			amount = p.Amount
+		}
+		if amount <= 0 {
+			continue
+		}
+		remaining -= amount
+		op.refunds = append(op.refunds, models.Refund{
+			Amount:      amount,
+			Currency:    o.Currency,
+			Status:      status,
+			RequestedAt: requested,
+			RefundedAt:  refunded,
+			CreatedAt:   requested,
+		})
+		op.refundOf = append(op.refundOf, i)
+	}
+}
+
+// linkPayments 订单入库取得主键后回填支付记录的 OrderID，并展开为一个切片以便批量插入
+func linkPayments(orders []models.Order, pays []orderPayments) []models.Payment {
+	var out []models.Payment
+	for i := range orders {
+		for j := range pays[i].payments {
+			pays[i].payments[j].OrderID = orders[i].ID
+		}
+		out = append(out, pays[i].payments...)
+	}
+	return out
+}
+
+// linkRefunds 支付记录入库后回填退款的 OrderID 与 PaymentID，payments 为 linkPayments 的返回值
+func linkRefunds(orders []models.Order, pays []orderPayments, payments []models.Payment) []models.Refund {
+	var out []models.Refund
+	offset := 0
+	for i := range orders {
+		for k := range pays[i].refunds {
+			pays[i].refunds[k].OrderID = orders[i].ID
+			pays[i].refunds[k].PaymentID = payments[offset+pays[i].refundOf[k]].ID
+		}
+		offset += len(pays[i].payments)
+		out = append(out, pays[i].refunds...)
+	}
+	return out
+}
@@ -0,0 +1,118 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"time"
+
+	"my-go-data-generator/internal/csv"
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/models"
+)
+
+const (
+	// sampleRows 估算行大小时每个表抽样生成的记录数
+	sampleRows = 2000
+	// rowOverhead 每行在 InnoDB 中的固定开销（行头、事务字段、主键与二级索引条目）的粗略估计
+	rowOverhead = 120.0
+)
+
+// rowBytes 记录按 CSV 编码后的字节数，近似该行数据本身的大小
+func rowBytes(record []string) int {
+	n := 0
+	for _, f := range record {
+		n += len(f)
+	}
+	return n
+}
+
+// EstimateRowSizes 使用实际的生成逻辑抽样生成记录，估算用户、产品、订单的平均行大小（字节）
+// 产品的大小包含初始入库流水，订单的大小包含其全部订单行、支付记录、退款、物流事件、评论、库存流水与促成订单的会话
+// 文本列的长度分布、NULL 比例等配置都会反映到估算结果中
+func EstimateRowSizes() (user, product, order float64) {
+	now := time.Now()
+	var userBytes, productBytes, orderBytes int
+	// 抽样使用独立的库存账本，不影响实际生成时的库存余额
+	ledger := newInventoryLedger()
+	for i := 0; i < sampleRows; i++ {
+		u := newUser(fieldgen.NewContext(now, int64(i), nil), false, now.Add(-randDuration(0, registrationSpan)))
+		userBytes += rowBytes(project("users", csv.UserHeader, csv.UserRecord(&u)))
+
+		p := newProduct(fieldgen.NewContext(now, int64(i), nil), fmt.Sprintf("%08d", i))
+		productBytes += rowBytes(project("products", csv.ProductHeader, csv.ProductRecord(&p)))
+		for _, m := range ledger.open([]models.Product{p}) {
+			productBytes += rowBytes(csv.InventoryMovementRecord(&m)) + rowOverhead
+		}
+
+		ctx := fieldgen.NewContext(now, int64(i), newRefs([]models.User{u}, []models.Product{p}))
+		o, items, _ := newOrder(ctx)
+		orderBytes += rowBytes(project("orders", csv.OrderHeader, csv.OrderRecord(&o)))
+		for j := range items {
+			// 订单行计入所属订单的大小，每行另计固定开销
+			orderBytes += rowBytes(csv.OrderItemRecord(&items[j])) + rowOverhead
+		}
+		pays := newPayments(&o, now)
+		for j := range pays.payments {
+			orderBytes += rowBytes(project("payments", csv.PaymentHeader, csv.PaymentRecord(&pays.payments[j]))) + rowOverhead
+		}
+		for j := range pays.refunds {
+			orderBytes += rowBytes(csv.RefundRecord(&pays.refunds[j])) + rowOverhead
+		}
+		events := newShipmentEvents(&o, now)
+		for j := range events {
+			orderBytes += rowBytes(csv.ShipmentEventRecord(&events[j])) + rowOverhead
+		}
+		reviews := newReviews(&o, items, now)
+		for j := range reviews {
+			orderBytes += rowBytes(csv.ReviewRecord(&reviews[j])) + rowOverhead
+		}
+		movements := ledger.orderMovements(&o, items, now)
+		for j := range movements {
+			orderBytes += rowBytes(csv.InventoryMovementRecord(&movements[j])) + rowOverhead
+		}
+		views := newConversionSession(ctx, &o, items)
+		for j := range views {
+			orderBytes += rowBytes(csv.PageViewRecord(&views[j])) + rowOverhead
+		}
+	}
+	avg := func(total int) float64 {
+		return float64(total)/sampleRows + rowOverhead
+	}
+	return avg(userBytes), avg(productBytes), avg(orderBytes)
+}
+
+// EstimateSessionSize 抽样生成浏览会话，估算一次会话全部点击流事件的平均大小（字节）
+func EstimateSessionSize() float64 {
+	now := time.Now()
+	refs := newRefs([]models.User{{ID: 1}}, []models.Product{{ID: 1, Category: categories[0]}})
+	total := 0
+	for i := 0; i < sampleRows; i++ {
+		views := newBrowseSession(fieldgen.NewContext(now, int64(i), refs), now.Add(-sessionSpan), now)
+		for j := range views {
+			total += rowBytes(csv.PageViewRecord(&views[j])) + rowOverhead
+		}
+	}
+	return float64(total) / sampleRows
+}
+
+// CalculateRecordCounts 根据目标 GB 数据量及各表的平均行大小计算记录数
+// 假设比例：产品数量 = 用户数量/10，订单数量 = 用户数量*10
+func CalculateRecordCounts(totalGB, userRowSize, productRowSize, orderRowSize float64) (int, int, int) {
+	// 总体数据量 = 用户记录总字节 + 产品记录总字节 + 订单记录总字节
+	//               = u*userRowSize + (u/10)*productRowSize + (u*10)*orderRowSize
+	factor := userRowSize + productRowSize/10 + 10*orderRowSize
+	totalBytes := totalGB * 1024 * 1024 * 1024
+	u := totalBytes / factor
+	return int(u), int(u) / 10, int(u) * 10
+}
+
+// planRecordCounts 按当前配置的目标数据量与抽样估算的行大小计算各表记录数
+func planRecordCounts() {
+	userSize, productSize, orderSize := EstimateRowSizes()
+	numUsers, numProducts, numOrders = CalculateRecordCounts(conf.TargetGB, userSize, productSize, orderSize)
+	log.Printf("预估行大小：用户=%.0fB, 产品=%.0fB, 订单=%.0fB", userSize, productSize, orderSize)
+	log.Printf("目标数据量设置：%.1fGB，用户=%d, 产品=%d, 订单=%d", conf.TargetGB, numUsers, numProducts, numOrders)
+	sessionSize := EstimateSessionSize()
+	numSessions = int(conf.ClickstreamGB * 1024 * 1024 * 1024 / sessionSize)
+	log.Printf("点击流数据量设置：%.1fGB，每个浏览会话约 %.0fB，浏览会话=%d", conf.ClickstreamGB, sessionSize, numSessions)
+}
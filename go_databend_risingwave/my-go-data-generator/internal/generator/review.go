@@ -0,0 +1,103 @@
+package generator
+
+import (
+	"math/rand"
+	"sync"
+	"time"
+
+	"my-go-data-generator/internal/models"
+)
+
+var (
+	// starWeights 正常订单的星级分布（J 形：好评居多，差评略多于中评）
+	starWeights = []int{12, 6, 12, 25, 45}
+	// reviewLabels 评论内容按星级使用的前缀
+	reviewLabels = []string{"【差评】", "【差评】", "【中评】", "【好评】", "【好评】"}
+)
+
+// reviewStats 各产品评论星级的汇总，订单生成完毕后回写产品的 Rating 与 NumberOfReviews
+type reviewStats struct {
+	mu    sync.Mutex
+	count map[uint]int
+	stars map[uint]int
+}
+
+func newReviewStats() *reviewStats {
+	return &reviewStats{count: make(map[uint]int), stars: make(map[uint]int)}
+}
+
+// add 汇总一批评论
+func (s *reviewStats) add(reviews []models.Review) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	for _, r := range reviews {
+		s.count[r.ProductID]++
+		s.stars[r.ProductID] += r.Stars
+	}
+}
+
+// randStars 按星级分布选取星级，发生退货的订单只给 1-2 星
+func randStars(returned bool) int {
+	if returned {
+		return rand.Intn(2) + 1
+	}
+	total := 0
+	for _, w := range starWeights {
+		total += w
+	}
+	r := rand.Intn(total)
+	for i, w := range starWeights {
+		if r < w {
+			return i + 1
+		}
+		r -= w
+	}
+	return len(starWeights)
+}
+
+// newReviews 为已完成订单的商品生成评论：评论者即下单用户，评论时间在送达之后、当前时间之前
+func newReviews(o *models.Order, items []models.OrderItem, now time.Time) []models.Review {
+	if o.OrderStatus != models.OrderStatusCompleted || o.DeliveryDate == nil {
+		return nil
+	}
+	var reviews []models.Review
+	for _, item := range items {
+		if rand.Float64() >= conf.ReviewRate {
+			continue
+		}
+		stars := randStars(o.ReturnStatus != nil)
+		created := o.DeliveryDate.Add(randDuration(time.Hour, 14*24*time.Hour))
+		if created.After(now) {
+			created = now
+		}
+		reviews = append(reviews, models.Review{
+			ProductID: item.ProductID,
+			UserID:    o.UserID,
+			Stars:     stars,
+			Content:   optional("reviews", "content", textFor("reviews", "content", reviewLabels[stars-1])),
+			CreatedAt: created,
+		})
+	}
+	return reviews
+}
+
+// linkReviews 订单入库后回填评论的 OrderID，并展开为一个切片以便批量插入
+func linkReviews(orders []models.Order, reviews [][]models.Review) []models.Review {
+	var out []models.Review
+	for i := range orders {
+		for j := range reviews[i] {
+			reviews[i][j].OrderID = orders[i].ID
+		}
+		out = append(out, reviews[i]...)
+	}
+	return out
+}
+
+// apply 按评论汇总结果设置产品的 Rating 与 NumberOfReviews
+func (s *reviewStats) apply(p *models.Product) {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	n := s.count[p.ID]
+	p.NumberOfReviews = n
+	p.Rating = models.RatingOf(s.stars[p.ID], n)
+}
@@ -0,0 +1,136 @@
+package generator
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"sync"
+	"time"
+
+	"my-go-data-generator/internal/locale"
+	"my-go-data-generator/internal/models"
+)
+
+// maxPendingEvents 内存中等待追加的未来物流事件上限，超出后不再缓存，对应订单的轨迹停在当前位置
+const maxPendingEvents = 200000
+
+var (
+	carriers = []string{"顺丰速运", "中通快递", "圆通速递", "韵达快递", "京东物流", "EMS"}
+	// hubs 转运中心所在城市，仓库均在国内，揽收与中转都发生在这些城市
+	hubs       = []string{"上海", "广州", "深圳", "北京", "武汉", "成都", "杭州", "郑州", "西安", "南京"}
+	exceptions = []string{"收件人电话无人接听，改约派送", "天气原因导致延误", "包裹外包装破损，已重新包装", "地址信息不详，联系收件人确认中", "海关查验中"}
+)
+
+var (
+	pendingMu     sync.Mutex
+	pendingEvents []models.ShipmentEvent // 尚未发生的物流事件，定时任务到点后追加写入
+)
+
+// newShipmentEvents 为已发货订单生成物流轨迹：揽收、途经转运中心的运输事件、可能的异常、派送，
+// 已完成订单最后在送达日期签收；已发货订单尚未签收，轨迹中晚于当前时间的事件由定时任务稍后追加
+func newShipmentEvents(o *models.Order, now time.Time) []models.ShipmentEvent {
+	if !o.IsShipped() || o.ShippedAt == nil || o.DeliveryDate == nil || o.TrackingNumber == nil {
+		return nil
+	}
+	carrier := carriers[rand.Intn(len(carriers))]
+	destination := "目的地"
+	if c, ok := locale.CityOf(o.ShippingAddress); ok {
+		destination = c.Name
+	}
+	start, end := *o.ShippedAt, *o.DeliveryDate
+
+	// 派送在送达前几个小时开始，中转事件均匀落在揽收与派送之间
+	outForDelivery := end.Add(-randDuration(time.Hour, 6*time.Hour))
+	if outForDelivery.Before(start) {
+		outForDelivery = start
+	}
+	route := rand.Perm(len(hubs))[:rand.Intn(3)+2]
+	times := make([]time.Time, len(route)-1)
+	for i := range times {
+		times[i] = start.Add(randDuration(0, outForDelivery.Sub(start)))
+	}
+	sort.Slice(times, func(i, j int) bool { return times[i].Before(times[j]) })
+
+	var events []models.ShipmentEvent
+	add := func(eventType, location, description string, at time.Time) {
+		events = append(events, models.ShipmentEvent{
+			Sequence:       len(events) + 1,
+			TrackingNumber: *o.TrackingNumber,
+			Carrier:        carrier,
+			EventType:      eventType,
+			Location:       location,
+			Description:    description,
+			EventTime:      at,
+		})
+	}
+	origin := hubs[route[0]]
+	add(models.ShipmentEventPickedUp, origin, fmt.Sprintf("【%s】%s已揽收", origin, carrier), start)
+	exceptionAt := -1
+	if rand.Float64() < conf.ShipmentExceptionRate {
+		exceptionAt = rand.Intn(len(times))
+	}
+	for i, at := range times {
+		hub := hubs[route[i+1]]
+		add(models.ShipmentEventInTransit, hub, fmt.Sprintf("快件已到达【%s转运中心】", hub), at)
+		if i == exceptionAt {
+			// 异常发生在到达该转运中心之后、下一个事件之前
+			next := outForDelivery
+			if i+1 < len(times) {
+				next = times[i+1]
+			}
+			add(models.ShipmentEventException, hub, exceptions[rand.Intn(len(exceptions))], at.Add(randDuration(0, next.Sub(at))))
+		}
+	}
+	add(models.ShipmentEventOutForDelivery, destination, fmt.Sprintf("快件已到达【%s】，快递员正在派送", destination), outForDelivery)
+	if o.OrderStatus == models.OrderStatusCompleted {
+		signed := end
+		if signed.Before(outForDelivery) {
+			signed = outForDelivery
+		}
+		add(models.ShipmentEventDelivered, destination, "快件已签收", signed)
+	}
+	return events
+}
+
+// linkShipmentEvents 订单入库后回填物流事件的 OrderID，返回已发生的事件；
+// 晚于 now 的事件放入待追加队列，由定时任务到点后写入
+func linkShipmentEvents(orders []models.Order, events [][]models.ShipmentEvent, now time.Time) []models.ShipmentEvent {
+	var due, future []models.ShipmentEvent
+	for i := range orders {
+		for _, e := range events[i] {
+			e.OrderID = orders[i].ID
+			if e.EventTime.After(now) {
+				future = append(future, e)
+			} else {
+				e.CreatedAt = now
+				due = append(due, e)
+			}
+		}
+	}
+	if len(future) > 0 {
+		pendingMu.Lock()
+		if len(pendingEvents)+len(future) <= maxPendingEvents {
+			pendingEvents = append(pendingEvents, future...)
+		}
+		pendingMu.Unlock()
+	}
+	return due
+}
+
+// dueShipmentEvents 取出待追加队列中到 now 为止已经发生的事件
+func dueShipmentEvents(now time.Time) []models.ShipmentEvent {
+	pendingMu.Lock()
+	defer pendingMu.Unlock()
+	var due []models.ShipmentEvent
+	kept := pendingEvents[:0]
+	for _, e := range pendingEvents {
+		if e.EventTime.After(now) {
+			kept = append(kept, e)
+		} else {
+			e.CreatedAt = now
+			due = append(due, e)
+		}
+	}
+	pendingEvents = kept
+	return due
+}
@@ -0,0 +1,336 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"regexp"
+	"runtime"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/csv"
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/schemaspec"
+)
+
+// specTable 表结构说明中的一张表及其各列编译后的生成器
+type specTable struct {
+	*schemaspec.Table
+	columns []specColumn
+	refs    []string     // 需要引用上下文的表
+	seq     atomic.Int64 // 已使用的行序号，主键与 seq 生成器由此得到唯一值
+}
+
+// specColumn 需要生成取值的一列；自增列与有默认值且未指定生成器的列交给数据库
+type specColumn struct {
+	name     string
+	gen      fieldgen.Generator
+	nullRate float64
+}
+
+// specTables 表结构说明中的表，按引用关系排序；未配置 SCHEMA_SPEC 时为空
+var specTables []*specTable
+
+// refArgRe 从生成器描述中找出 ref 引用的表
+var refArgRe = regexp.MustCompile(`\bref\(\s*(\w+)`)
+
+// configureSpec 解析 SCHEMA_SPEC 并编译各列的生成器，说明有误时直接退出
+func configureSpec() {
+	specTables = nil
+	if conf.SchemaSpec == "" {
+		return
+	}
+	spec, err := schemaspec.ParseFile(conf.SchemaSpec)
+	if err != nil {
+		log.Fatalf("表结构说明 %s 有误: %v", conf.SchemaSpec, err)
+	}
+	for _, t := range spec.Tables {
+		st, err := compileSpecTable(t)
+		if err != nil {
+			log.Fatalf("表结构说明 %s 有误: %v", conf.SchemaSpec, err)
+		}
+		specTables = append(specTables, st)
+	}
+}
+
+// compileSpecTable 为各列选定生成器，自增列与有默认值且未指定生成器的列交给数据库
+func compileSpecTable(t *schemaspec.Table) (*specTable, error) {
+	st := &specTable{Table: t}
+	seen := make(map[string]bool)
+	for _, c := range t.Columns {
+		g, desc, err := columnGenerator(t.Name, c)
+		if err != nil {
+			return nil, err
+		}
+		if g == nil {
+			continue
+		}
+		for _, m := range refArgRe.FindAllStringSubmatch(desc, -1) {
+			if !seen[m[1]] {
+				seen[m[1]] = true
+				st.refs = append(st.refs, m[1])
+			}
+		}
+		st.columns = append(st.columns, newSpecColumn(t.Name, c, g))
+	}
+	if len(st.columns) == 0 {
+		return nil, fmt.Errorf("表 %s 没有需要生成的列", t.Name)
+	}
+	return st, nil
+}
+
+// columnGenerator 按 FIELD_GENERATORS 覆盖项、gen 注释、列约束与列类型的优先级为一列选定生成器，
+// 同时返回生成器描述以便找出 ref 引用；列交给数据库生成时返回 nil
+func columnGenerator(table string, c *schemaspec.Column) (fieldgen.Generator, string, error) {
+	if g, ok := fields.Override(table, c.Name); ok {
+		return g, conf.FieldGenerators[table+"."+c.Name], nil
+	}
+	var (
+		g    fieldgen.Generator
+		desc = c.Gen
+		err  error
+	)
+	switch {
+	case c.Gen != "":
+		g, err = fieldgen.Build(c.Gen)
+	case c.AutoIncrement || c.HasDefault:
+		return nil, "", nil
+	case c.References != "":
+		desc = "ref(" + c.References + ")"
+		g, err = fieldgen.Build(desc)
+	case c.PrimaryKey || c.Unique:
+		g = uniqueGenerator(c)
+	default:
+		g, err = defaultGenerator(c)
+	}
+	if err != nil {
+		return nil, "", fmt.Errorf("%s.%s: %w", table, c.Name, err)
+	}
+	return g, desc, nil
+}
+
+// newSpecColumn 可空列按 COLUMN_NULL_RATES 写入 NULL
+func newSpecColumn(table string, c *schemaspec.Column, g fieldgen.Generator) specColumn {
+	nullRate := 0.0
+	if !c.NotNull {
+		nullRate = conf.NullRates.Of(table, c.Name)
+	}
+	return specColumn{name: c.Name, gen: g, nullRate: nullRate}
+}
+
+// integerTypes 整数列类型
+var integerTypes = map[string]int{"TINYINT": 127, "SMALLINT": 32767, "MEDIUMINT": 8388607, "INT": 1000000, "INTEGER": 1000000, "BIGINT": 1000000}
+
+// uniqueGenerator 主键与唯一列：整数列取行序号，其余列为 列名-序号
+func uniqueGenerator(c *schemaspec.Column) fieldgen.Generator {
+	if _, ok := integerTypes[c.Type]; ok {
+		return fieldgen.GeneratorFunc(func(ctx *fieldgen.Context) interface{} { return ctx.Seq })
+	}
+	return fieldgen.GeneratorFunc(func(ctx *fieldgen.Context) interface{} {
+		return fmt.Sprintf("%s-%d", c.Name, ctx.Seq)
+	})
+}
+
+// defaultGenerator 按列类型推导生成器：数值在类型范围内均匀分布，时间为近一年，字符串为随机字母
+func defaultGenerator(c *schemaspec.Column) (fieldgen.Generator, error) {
+	arg := func(i, def int) int {
+		if i < len(c.Args) {
+			return c.Args[i]
+		}
+		return def
+	}
+	var desc string
+	switch c.Type {
+	case "BOOL", "BOOLEAN", "BIT":
+		desc = "bool(0.5)"
+	case "TINYINT", "SMALLINT", "MEDIUMINT", "INT", "INTEGER", "BIGINT":
+		if c.Type == "TINYINT" && arg(0, 0) == 1 {
+			desc = "bool(0.5)"
+		} else {
+			desc = fmt.Sprintf("intrange(0,%d)", integerTypes[c.Type])
+		}
+	case "DECIMAL", "NUMERIC":
+		precision, scale := arg(0, 10), arg(1, 0)
+		max := math.Min(math.Pow(10, float64(precision-scale))-1, 1000000)
+		desc = fmt.Sprintf("floatrange(0,%g,%d)", max, scale)
+	case "FLOAT", "DOUBLE", "REAL":
+		desc = "floatrange(0,1000,2)"
+	case "DATE", "DATETIME", "TIMESTAMP":
+		desc = "daterange(-365d,0)"
+	case "CHAR", "VARCHAR":
+		n := arg(0, 16)
+		desc = fmt.Sprintf("regex([a-z]{%d,%d})", min(n, 4), min(n, 16))
+	case "TINYTEXT", "TEXT", "MEDIUMTEXT", "LONGTEXT":
+		desc = "regex([a-z]{3,10}( [a-z]{2,10}){5,30})"
+	case "ENUM", "SET":
+		if len(c.Values) == 0 {
+			return nil, fmt.Errorf("%s 没有取值", c.Type)
+		}
+		values := c.Values
+		return fieldgen.GeneratorFunc(func(*fieldgen.Context) interface{} { return pickString(values) }), nil
+	default:
+		return nil, fmt.Errorf("类型 %s 没有默认生成器，请用 gen 注释指定", c.Type)
+	}
+	return fieldgen.Build(desc)
+}
+
+// rangePool 按主键区间引用一张表，假定主键连续（自增主键或 seq 生成的主键）
+type rangePool struct {
+	min, max uint
+}
+
+func (p rangePool) Len() int {
+	if p.max < p.min {
+		return 0
+	}
+	return int(p.max-p.min) + 1
+}
+
+func (p rangePool) ID(i int) uint { return p.min + uint(i) }
+
+// specRefs 查询被引用各表当前的主键区间；说明之外的表（如 users、products）以 id 为主键
+func specRefs(db *gorm.DB, t *specTable) map[string]fieldgen.Pool {
+	refs := make(map[string]fieldgen.Pool, len(t.refs))
+	for _, table := range t.refs {
+		pk := "id"
+		for _, other := range specTables {
+			if other.Name == table && other.PrimaryKey() != nil {
+				pk = other.PrimaryKey().Name
+			}
+		}
+		var r struct{ Min, Max uint }
+		err := db.Table(table).Select(fmt.Sprintf("COALESCE(MIN(%s),0) AS min, COALESCE(MAX(%s),0) AS max", pk, pk)).Scan(&r).Error
+		if err != nil {
+			log.Printf("查询被引用表 %s 的主键区间失败: %v", table, err)
+			continue
+		}
+		if r.Max > 0 {
+			refs[table] = rangePool{min: r.Min, max: r.Max}
+		}
+	}
+	return refs
+}
+
+// resumeSeq 从表中已有数据接续行序号，重复运行时主键与唯一值不冲突
+func resumeSeq(db *gorm.DB, t *specTable) {
+	var n int64
+	if pk := t.PrimaryKey(); pk != nil && !pk.AutoIncrement && integerTypes[pk.Type] > 0 {
+		db.Table(t.Name).Select(fmt.Sprintf("COALESCE(MAX(%s),0)", pk.Name)).Scan(&n)
+	} else {
+		db.Table(t.Name).Count(&n)
+	}
+	t.seq.Store(n)
+}
+
+// newSpecRow 生成一行，列名到取值
+func newSpecRow(t *specTable, now time.Time, refs map[string]fieldgen.Pool) map[string]interface{} {
+	ctx := fieldgen.NewContext(now, t.seq.Add(1), refs)
+	row := make(map[string]interface{}, len(t.columns))
+	for _, c := range t.columns {
+		row[c.name] = c.value(ctx)
+	}
+	return row
+}
+
+// value 生成该列的一个取值，按 nullRate 的比例为 NULL
+func (c specColumn) value(ctx *fieldgen.Context) interface{} {
+	if c.nullRate > 0 && rand.Float64() < c.nullRate {
+		return nil
+	}
+	return c.gen.Generate(ctx)
+}
+
+// header CSV 表头：需要生成取值的各列
+func (t *specTable) header() []string {
+	header := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		header[i] = c.name
+	}
+	return header
+}
+
+// record 一行的 CSV 记录
+func (t *specTable) record(row map[string]interface{}) []string {
+	record := make([]string, len(t.columns))
+	for i, c := range t.columns {
+		record[i] = csv.Value(row[c.name])
+	}
+	return record
+}
+
+// MigrateSpecTables 按表结构说明中的 DDL 创建尚不存在的表；已存在的表不做修改
+func MigrateSpecTables(db *gorm.DB) {
+	for _, t := range specTables {
+		if db.Migrator().HasTable(t.Name) {
+			continue
+		}
+		if err := db.Exec(t.DDL).Error; err != nil {
+			log.Fatalf("按表结构说明创建表 %s 失败: %v", t.Name, err)
+		}
+		log.Printf("已按表结构说明创建表 %s", t.Name)
+	}
+}
+
+// generateSpecTables 按引用顺序批量生成说明中的各表，被引用的表写完后再生成引用它的表
+func generateSpecTables(db *gorm.DB, exporter *csvExporter, batchSize int) {
+	maxWorkers := runtime.NumCPU() * 2
+	for _, t := range specTables {
+		if t.Rows == 0 {
+			continue
+		}
+		resumeSeq(db, t)
+		refs := specRefs(db, t)
+		var (
+			wg    sync.WaitGroup
+			mu    sync.Mutex
+			count int
+			sem   = make(chan struct{}, maxWorkers)
+		)
+		for i := 0; i < t.Rows; i += batchSize {
+			wg.Add(1)
+			sem <- struct{}{}
+			go func(n int) {
+				defer wg.Done()
+				now := time.Now()
+				rows := make([]map[string]interface{}, n)
+				for j := range rows {
+					rows[j] = newSpecRow(t, now, refs)
+				}
+				if err := db.Table(t.Name).CreateInBatches(&rows, batchSize).Error; err != nil {
+					log.Printf("批量插入 %s 失败: %v", t.Name, err)
+				}
+				exporter.writeSpecRows(t, rows)
+				mu.Lock()
+				count += n
+				mu.Unlock()
+				<-sem
+			}(min(batchSize, t.Rows-i))
+		}
+		wg.Wait()
+		log.Printf("表 %s 生成完毕，共 %d 条", t.Name, count)
+	}
+}
+
+// streamSpecTables 定时任务每次为配置了 @stream 的表追加若干行
+func streamSpecTables(db *gorm.DB, now time.Time) {
+	for _, t := range specTables {
+		if t.StreamRows == 0 {
+			continue
+		}
+		if t.seq.Load() == 0 {
+			resumeSeq(db, t)
+		}
+		refs := specRefs(db, t)
+		rows := make([]map[string]interface{}, t.StreamRows)
+		for i := range rows {
+			rows[i] = newSpecRow(t, now, refs)
+		}
+		if err := db.Table(t.Name).Create(&rows).Error; err != nil {
+			log.Printf("定时插入 %s 失败: %v", t.Name, err)
+		}
+	}
+}
@@ -0,0 +1,206 @@
+package generator
+
+import (
+	"fmt"
+	"log"
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/models"
+)
+
+// typeZooNullRate type_zoo 各列取 NULL 的比例；typeZooBoundaryRate 取边界值的比例，其余为类型范围内的常规值
+const (
+	typeZooNullRate     = 0.05
+	typeZooBoundaryRate = 0.5
+)
+
+// boundary 按比例返回 NULL、values 中的某个边界值或 random 生成的常规值
+func boundary[T any](values []T, random func() T) *T {
+	var v T
+	switch r := rand.Float64(); {
+	case r < typeZooNullRate:
+		return nil
+	case r < typeZooNullRate+typeZooBoundaryRate:
+		v = values[rand.Intn(len(values))]
+	default:
+		v = random()
+	}
+	return &v
+}
+
+// boundaryBytes 二进制列的边界值与常规值，NULL 为 nil
+func boundaryBytes(values [][]byte, maxLen int) []byte {
+	v := boundary(values, func() []byte {
+		b := make([]byte, 1+rand.Intn(maxLen))
+		for i := range b {
+			b[i] = byte(rand.Intn(256))
+		}
+		return b
+	})
+	if v == nil {
+		return nil
+	}
+	return *v
+}
+
+// boundarySpatial 空间列的边界值与常规值，NULL 为空字符串
+func boundarySpatial(values []models.Spatial, random func() models.Spatial) models.Spatial {
+	if v := boundary(values, random); v != nil {
+		return *v
+	}
+	return ""
+}
+
+// randPoint 经纬度范围内的随机点坐标
+func randPoint() string {
+	return fmt.Sprintf("%.6f %.6f", rand.Float64()*360-180, rand.Float64()*180-90)
+}
+
+// 日期时间的边界值，按本地时区构造，与连接串 loc=Local 写入的字面值一致。
+// TIMESTAMP 两端各留一天，避免数据库会话时区换算后越界
+var (
+	minDatetime  = time.Date(1000, 1, 1, 0, 0, 0, 0, time.Local)
+	maxDatetime  = time.Date(9999, 12, 31, 23, 59, 59, 999999000, time.Local)
+	epoch        = time.Date(1970, 1, 1, 0, 0, 0, 0, time.Local)
+	minTimestamp = time.Date(1970, 1, 2, 0, 0, 1, 0, time.Local)
+	maxTimestamp = time.Date(2038, 1, 18, 3, 14, 7, 999999000, time.Local)
+)
+
+// dateOf 去掉时间部分
+func dateOf(t time.Time) time.Time {
+	return time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+}
+
+// randTime from 与 to 之间均匀分布的时间，精确到微秒
+func randTime(from, to time.Time) time.Time {
+	d := to.Sub(from)
+	if d <= 0 {
+		return from
+	}
+	return from.Add(time.Duration(rand.Int63n(int64(d)))).Truncate(time.Microsecond)
+}
+
+// newTypeZoo 生成 type_zoo 的一行，各列独立地取 NULL、边界值或常规值
+func newTypeZoo(now time.Time) models.TypeZoo {
+	return models.TypeZoo{
+		DecimalSmall: boundary([]string{"0.00", "0.01", "-0.01", "999.99", "-999.99"}, func() string {
+			return fmt.Sprintf("%.2f", float64(rand.Intn(199999)-99999)/100)
+		}),
+		DecimalWide: boundary([]string{
+			"0",
+			strings.Repeat("9", 35) + "." + strings.Repeat("9", 30),
+			"-" + strings.Repeat("9", 35) + "." + strings.Repeat("9", 30),
+			"0." + strings.Repeat("0", 29) + "1",
+			"12345678901234567890123456789.123456789012345678901234567890", // 超过 RisingWave 28 位精度
+		}, func() string {
+			return fmt.Sprintf("%d.%09d", rand.Int63(), rand.Intn(1000000000))
+		}),
+		TinyInt:         boundary([]int8{math.MinInt8, math.MaxInt8, 0, -1}, func() int8 { return int8(rand.Intn(256) - 128) }),
+		TinyIntUnsigned: boundary([]uint8{0, math.MaxUint8}, func() uint8 { return uint8(rand.Intn(256)) }),
+		SmallInt:        boundary([]int16{math.MinInt16, math.MaxInt16, 0}, func() int16 { return int16(rand.Intn(65536) - 32768) }),
+		MediumInt:       boundary([]int32{-8388608, 8388607, 0}, func() int32 { return int32(rand.Intn(16777216) - 8388608) }),
+		IntUnsigned:     boundary([]uint32{0, math.MaxUint32}, rand.Uint32),
+		BigInt:          boundary([]int64{math.MinInt64, math.MaxInt64, 0, 1 << 53, -(1 << 53) - 1}, func() int64 { return rand.Int63() - rand.Int63() }),
+		BigIntUnsigned:  boundary([]uint64{0, math.MaxUint64, 1 << 63}, rand.Uint64),
+		FloatCol: boundary([]float32{0, 3.4e38, -3.4e38, 1.2e-38, -1.2e-38}, func() float32 {
+			return float32(rand.NormFloat64() * 1e6)
+		}),
+		DoubleCol: boundary([]float64{0, math.MaxFloat64, -math.MaxFloat64, 2.2250738585072014e-308, 0.30000000000000004}, func() float64 {
+			return rand.NormFloat64() * 1e12
+		}),
+		BitCol:     boundary([]uint64{0, 1, 1 << 63, math.MaxUint64}, rand.Uint64),
+		CharCol:    boundary([]string{"", "a", "尾部空格  ", "中文字符恰好十个字符"}, func() string { return randHex(5) }),
+		VarcharCol: boundary([]string{"", " ", "O'Reilly \"引号\" \\ 反斜杠", "表情😀🇨🇳", "\t制表\n换行\r", strings.Repeat("长", 255)}, func() string { return randHex(16) }),
+		TextCol:    boundary([]string{"", strings.Repeat("大文本", 20000), "NULL", "\\N"}, func() string { return randHex(64) }),
+		EnumCol:    boundary([]string{"", "小", "中", "大"}, func() string { return pickString([]string{"小", "中", "大"}) }),
+		SetCol:     boundary([]string{"", "a", "a,b,c,d", "b,d"}, func() string { return pickString([]string{"a", "b", "c", "d"}) }),
+		JSONCol: boundary([]string{
+			`{}`, `[]`, `null`, `"字符串"`, `0`, `true`,
+			`{"key": "值😀", "n": 1.7976931348623157e308, "big": 18446744073709551615, "nested": {"a": [1, {"b": null}]}}`,
+		}, func() string {
+			return fmt.Sprintf(`{"key": "%s", "n": %d, "list": [%d, %d]}`, randHex(4), rand.Intn(1000), rand.Intn(10), rand.Intn(10))
+		}),
+		BinaryCol:    boundaryBytes([][]byte{{}, {0x00}, []byte(strings.Repeat("\xff", 16))}, 16),
+		VarbinaryCol: boundaryBytes([][]byte{{}, {0x00, 0x00}, {0xff, 0xfe, 0x00, 0x80}, []byte("文本字节")}, 255),
+		BlobCol:      boundaryBytes([][]byte{{}, make([]byte, 65535)}, 2048),
+		DateCol: boundary([]time.Time{minDatetime, dateOf(maxDatetime), epoch, time.Date(2000, 2, 29, 0, 0, 0, 0, time.Local)}, func() time.Time {
+			return dateOf(randTime(minDatetime, maxDatetime))
+		}),
+		TimeCol: boundary([]string{"-838:59:59.000000", "838:59:59.000000", "00:00:00", "23:59:59.999999"}, func() string {
+			return fmt.Sprintf("%02d:%02d:%02d.%06d", rand.Intn(24), rand.Intn(60), rand.Intn(60), rand.Intn(1000000))
+		}),
+		DatetimeCol:  boundary([]time.Time{minDatetime, maxDatetime, epoch}, func() time.Time { return randTime(now.AddDate(-10, 0, 0), now) }),
+		TimestampCol: boundary([]time.Time{minTimestamp, maxTimestamp}, func() time.Time { return randTime(now.AddDate(-10, 0, 0), now) }),
+		YearCol:      boundary([]int16{0, 1901, 2155}, func() int16 { return int16(1901 + rand.Intn(255)) }),
+		PointCol: boundarySpatial([]models.Spatial{"POINT(0 0)", "POINT(-180 -90)", "POINT(180 90)"}, func() models.Spatial {
+			return models.Spatial("POINT(" + randPoint() + ")")
+		}),
+		LineCol: boundarySpatial([]models.Spatial{"LINESTRING(0 0,0 0)", "LINESTRING(-180 -90,180 90)"}, func() models.Spatial {
+			return models.Spatial("LINESTRING(" + randPoint() + "," + randPoint() + ")")
+		}),
+		PolygonCol: boundarySpatial([]models.Spatial{"POLYGON((0 0,10 0,10 10,0 10,0 0))", "POLYGON((0 0,10 0,10 10,0 10,0 0),(2 2,4 2,4 4,2 4,2 2))"}, func() models.Spatial {
+			x, y := rand.Float64()*100, rand.Float64()*100
+			return models.Spatial(fmt.Sprintf("POLYGON((%[1]g %[2]g,%[3]g %[2]g,%[3]g %[4]g,%[1]g %[2]g))", x, y, x+1, y+1))
+		}),
+		GeometryCol: boundarySpatial([]models.Spatial{"GEOMETRYCOLLECTION(POINT(1 1),LINESTRING(0 0,1 1))", "MULTIPOINT((0 0),(1 1))"}, func() models.Spatial {
+			return models.Spatial("POINT(" + randPoint() + ")")
+		}),
+	}
+}
+
+// generateTypeZoo 批量写入 type_zoo，TYPE_ZOO_ROWS 为 0 时不做任何事
+func generateTypeZoo(db *gorm.DB) {
+	if conf.TypeZooRows <= 0 {
+		return
+	}
+	now := time.Now()
+	const batchSize = 100
+	for i := 0; i < conf.TypeZooRows; i += batchSize {
+		rows := make([]models.TypeZoo, min(batchSize, conf.TypeZooRows-i))
+		for j := range rows {
+			rows[j] = newTypeZoo(now)
+		}
+		if err := db.Create(&rows).Error; err != nil {
+			log.Printf("批量插入 type_zoo 失败: %v", err)
+		}
+	}
+	log.Printf("type_zoo 生成完毕，共 %d 条", conf.TypeZooRows)
+}
+
+// streamTypeZoo 定时任务每次对 type_zoo 插入一行、整行更新一行并删除一行，覆盖 CDC 的三种变更
+func streamTypeZoo(db *gorm.DB, now time.Time) {
+	if conf.TypeZooRows <= 0 {
+		return
+	}
+	row := newTypeZoo(now)
+	if err := db.Create(&row).Error; err != nil {
+		log.Printf("定时插入 type_zoo 失败: %v", err)
+		return
+	}
+	var r struct{ Min, Max uint }
+	if err := db.Model(&models.TypeZoo{}).Select("MIN(id) AS min, MAX(id) AS max").Scan(&r).Error; err != nil || r.Max <= r.Min {
+		return
+	}
+	// 更新与删除的目标取主键区间内的随机位置之后的第一行，跳过刚插入的行
+	pick := func() (uint, bool) {
+		var id uint
+		err := db.Model(&models.TypeZoo{}).Select("id").Where("id >= ? AND id <> ?", r.Min+uint(rand.Intn(int(r.Max-r.Min))), row.ID).Order("id").Limit(1).Scan(&id).Error
+		return id, err == nil && id > 0
+	}
+	if id, ok := pick(); ok {
+		update := newTypeZoo(now)
+		update.ID = id
+		if err := db.Model(&update).Select("*").Omit("id", "created_at").Updates(&update).Error; err != nil {
+			log.Printf("定时更新 type_zoo 失败: %v", err)
+		}
+	}
+	if id, ok := pick(); ok {
+		if err := db.Delete(&models.TypeZoo{}, id).Error; err != nil {
+			log.Printf("定时删除 type_zoo 失败: %v", err)
+		}
+	}
+}
@@ -0,0 +1,207 @@
+package generator
+
+import (
+	"math"
+	"math/rand"
+	"strings"
+	"time"
+
+	"my-go-data-generator/internal/fieldgen"
+	"my-go-data-generator/internal/locale"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+)
+
+// occupationSpec 职业的基础月薪与任职条件
+type occupationSpec struct {
+	Name   string
+	Salary float64 // 基础月薪（元）
+	MinAge int
+	MaxAge int
+	MinEdu int // educationList 中的最低学历下标
+	Weight float64
+}
+
+var occupationSpecs = []occupationSpec{
+	{"工程师", 15000, 22, 65, 2, 20},
+	{"医生", 16000, 25, 70, 2, 6},
+	{"教师", 9000, 22, 65, 2, 10},
+	{"艺术家", 8000, 18, 80, 0, 4},
+	{"律师", 18000, 24, 70, 2, 4},
+	{"销售", 8000, 18, 60, 0, 18},
+	{"公务员", 10000, 22, 60, 2, 10},
+	{"自由职业", 7000, 18, 80, 0, 10},
+	{models.OccupationStudent, 1500, 18, 30, 0, 10},
+	{models.OccupationRetired, 4500, 55, 100, 0, 8},
+}
+
+// 学历与达到该学历所需的最小年龄、收入系数
+var (
+	educationList    = []string{"初中", "高中", "本科", "硕士", "博士"}
+	educationMinAge  = []int{18, 18, 21, 24, 27}
+	educationFactor  = []float64{0.7, 0.85, 1.0, 1.3, 1.6}
+	educationWeights = []float64{10, 25, 45, 15, 5}
+	registrationSpan = 5 * 365 * 24 * time.Hour // 存量用户的注册时间跨度
+)
+
+var hobbies = []string{"阅读", "旅行", "摄影", "跑步", "游泳", "烹饪", "音乐", "电影", "游戏", "书法", "钓鱼", "园艺"}
+
+// randHobby 随机选取 0~3 个爱好，以逗号分隔；返回空字符串表示没有填写
+func randHobby() string {
+	n := rand.Intn(4)
+	picked := make([]string, 0, n)
+	for _, i := range rand.Perm(len(hobbies))[:n] {
+		picked = append(picked, hobbies[i])
+	}
+	return strings.Join(picked, ",")
+}
+
+// randAge 生成 18~80 岁的年龄，集中在 25~45 岁
+func randAge() int {
+	age := int(math.Round(rand.NormFloat64()*12 + 35))
+	if age < 18 {
+		age = 18 + rand.Intn(8)
+	}
+	if age > 80 {
+		age = 80
+	}
+	return age
+}
+
+// randEducation 在年龄允许的范围内按权重选取学历下标
+func randEducation(age int) int {
+	var total float64
+	for i, w := range educationWeights {
+		if age >= educationMinAge[i] {
+			total += w
+		}
+	}
+	target := rand.Float64() * total
+	edu := 0
+	for i, w := range educationWeights {
+		if age < educationMinAge[i] {
+			continue
+		}
+		edu = i
+		if target < w {
+			break
+		}
+		target -= w
+	}
+	return edu
+}
+
+// randOccupation 选取与年龄、学历匹配的职业
+func randOccupation(age, edu int) occupationSpec {
+	var candidates []occupationSpec
+	var total float64
+	for _, o := range occupationSpecs {
+		if age >= o.MinAge && age <= o.MaxAge && edu >= o.MinEdu {
+			candidates = append(candidates, o)
+			total += o.Weight
+		}
+	}
+	target := rand.Float64() * total
+	for _, o := range candidates {
+		if target < o.Weight {
+			return o
+		}
+		target -= o.Weight
+	}
+	return candidates[len(candidates)-1]
+}
+
+// randMaritalStatus 按年龄选取婚姻状况：已婚比例随年龄上升，丧偶只出现在高龄段
+func randMaritalStatus(age int) string {
+	if age < models.MinMarriageAge {
+		return models.MaritalSingle
+	}
+	married := math.Min(0.9, float64(age-models.MinMarriageAge)/20)
+	divorced := math.Min(0.12, float64(age-models.MinMarriageAge)/200)
+	widowed := 0.0
+	if age >= 55 {
+		widowed = math.Min(0.3, float64(age-55)/80)
+	}
+	switch r := rand.Float64(); {
+	case r < widowed:
+		return models.MaritalWidowed
+	case r < widowed+divorced:
+		return models.MaritalDivorced
+	case r < widowed+divorced+married:
+		return models.MaritalMarried
+	default:
+		return models.MaritalSingle
+	}
+}
+
+// monthlyIncome 以职业基础月薪为基准，叠加学历系数、工龄曲线和对数正态噪声
+func monthlyIncome(o occupationSpec, edu, age int) money.Amount {
+	experience := 1.0
+	if o.Name != models.OccupationStudent && o.Name != models.OccupationRetired {
+		// 收入在 45 岁左右达到峰值
+		years := float64(age - 22)
+		experience = 0.8 + 0.03*years - 0.0004*years*years
+		if experience < 0.7 {
+			experience = 0.7
+		}
+	}
+	noise := math.Exp(rand.NormFloat64() * 0.25)
+	return money.FromFloat(o.Salary * educationFactor[edu] * experience * noise)
+}
+
+// randLocale 按配置的地区分布选取数据包
+func randLocale() *locale.Pack {
+	if p, ok := locale.Get(conf.LocaleWeights.Pick(rand.Float64())); ok {
+		return p
+	}
+	return locale.Default()
+}
+
+// newUser 生成属性相互关联的用户画像，registered 为注册时间
+// ctx.Seq 保证电话与邮箱唯一，stream 表示定时插入的用户（使用独立号段）
+// 保证 RegistrationDate <= LastLogin <= now 且 CreatedAt 等于 RegistrationDate；
+// 姓名、电话、地址、国籍、币种、时区与语言来自同一个地区数据包
+func newUser(ctx *fieldgen.Context, stream bool, registered time.Time) models.User {
+	var user models.User
+	fill(&user, ctx)
+	now := ctx.Now
+	person := randLocale().NewPerson(user.Gender, ctx.Seq, stream)
+	age := randAge()
+	edu := randEducation(age)
+	occupation := randOccupation(age, edu)
+
+	tenure := now.Sub(registered)
+	lastLogin := registered.Add(time.Duration(rand.Float64() * float64(tenure)))
+	if rand.Intn(2) == 0 {
+		// 活跃用户最近一周内登录过
+		recent := now.Add(-randDuration(0, 7*24*time.Hour))
+		if recent.After(lastLogin) {
+			lastLogin = recent
+		}
+	}
+	tenureDays := tenure.Hours() / 24
+	points := int(tenureDays * models.MaxLoyaltyPointsPerDay * rand.Float64())
+
+	user.Username = person.Name
+	user.Age = age
+	user.Email = person.Email
+	user.Phone = person.Phone
+	user.Address = person.Address
+	user.Nationality = person.Pack.Nationality
+	user.Occupation = occupation.Name
+	user.MaritalStatus = randMaritalStatus(age)
+	user.Education = educationList[edu]
+	user.Hobby = optional("users", "hobby", randHobby())
+	user.Income = monthlyIncome(occupation, edu, age)
+	user.RegistrationDate = registered
+	user.LastLogin = lastLogin
+	user.LoyaltyPoints = points
+	user.PreferredLanguage = person.Pack.Language
+	user.Currency = person.Pack.Currency
+	user.Timezone = person.Timezone
+	user.CreatedAt = registered
+	user.UpdatedAt = lastLogin
+	applyOverrides(&user, ctx)
+	dims.user(&user)
+	return user
+}
@@ -0,0 +1,136 @@
+package locale
+
+import (
+	"fmt"
+	"math/rand"
+	"sort"
+	"strings"
+)
+
+// City 城市及其所在时区，地址与时区由同一个城市推导，保证两者一致
+type City struct {
+	Name     string
+	Region   string // 省、州或都道府县
+	Postcode string
+	Timezone string // IANA 时区名
+}
+
+// Pack 一个地区的数据包：姓名、电话、地址、国籍、币种、语言与时区相互一致
+type Pack struct {
+	Code        string   // 区域代码，如 zh_CN
+	Nationality string   // 国籍
+	Currency    string   // ISO 4217 币种
+	Language    string   // 首选语言
+	Surnames    []string // 姓
+	MaleNames   []string // 男性名
+	FemaleNames []string // 女性名
+	Cities      []City
+	Streets     []string
+	EmailDomain []string
+
+	// fullName 按当地习惯组合姓与名
+	fullName func(surname, given string) string
+	// phone 根据 8 位数字串生成当地格式的电话，stream 为 true 时使用与存量数据不同的号段以避免冲突
+	phone func(digits string, stream bool) string
+	// address 按当地习惯拼接地址
+	address func(c City, street string, number int) string
+}
+
+// Person 一个地区下相互一致的身份信息
+type Person struct {
+	Pack     *Pack
+	Name     string
+	Phone    string
+	Email    string
+	Address  string
+	Timezone string
+}
+
+// NewPerson 在该数据包下生成一个人，seq 用于保证电话与邮箱唯一
+func (p *Pack) NewPerson(gender string, seq int64, stream bool) Person {
+	given := p.MaleNames
+	switch gender {
+	case "女":
+		given = p.FemaleNames
+	case "男":
+	default:
+		if rand.Intn(2) == 0 {
+			given = p.FemaleNames
+		}
+	}
+	city := p.Cities[rand.Intn(len(p.Cities))]
+	digits := fmt.Sprintf("%08d", seq%100000000)
+	local := "user"
+	if stream {
+		local = "timed_user"
+	}
+	return Person{
+		Pack:     p,
+		Name:     p.fullName(p.Surnames[rand.Intn(len(p.Surnames))], given[rand.Intn(len(given))]),
+		Phone:    p.phone(digits, stream),
+		Email:    fmt.Sprintf("%s%d@%s", local, seq, p.EmailDomain[rand.Intn(len(p.EmailDomain))]),
+		Address:  p.address(city, p.Streets[rand.Intn(len(p.Streets))], rand.Intn(300)+1),
+		Timezone: city.Timezone,
+	}
+}
+
+// packs 已注册的数据包
+var packs = map[string]*Pack{}
+
+// register 注册数据包，新增地区时在对应文件的 init 中调用
+func register(p *Pack) {
+	packs[p.Code] = p
+}
+
+// Get 按区域代码获取数据包
+func Get(code string) (*Pack, bool) {
+	p, ok := packs[code]
+	return p, ok
+}
+
+// Codes 返回所有已注册的区域代码（已排序）
+func Codes() []string {
+	codes := make([]string, 0, len(packs))
+	for code := range packs {
+		codes = append(codes, code)
+	}
+	sort.Strings(codes)
+	return codes
+}
+
+// Default 默认数据包
+func Default() *Pack {
+	return packs["zh_CN"]
+}
+
+// ByNationality 按国籍查找数据包
+func ByNationality(nationality string) (*Pack, bool) {
+	for _, code := range Codes() {
+		if p := packs[code]; p.Nationality == nationality {
+			return p, true
+		}
+	}
+	return nil, false
+}
+
+// HasTimezone 该数据包的城市中是否使用给定时区
+func (p *Pack) HasTimezone(tz string) bool {
+	for _, c := range p.Cities {
+		if c.Timezone == tz {
+			return true
+		}
+	}
+	return false
+}
+
+// CityOf 根据地址查找其所在城市，地址由 NewPerson 生成时总包含城市名
+func CityOf(address string) (City, bool) {
+	for _, code := range Codes() {
+		for _, c := range packs[code].Cities {
+			if strings.Contains(address, c.Name) {
+				return c, true
+			}
+		}
+	}
+	return City{}, false
+}
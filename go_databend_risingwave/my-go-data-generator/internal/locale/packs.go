@@ -0,0 +1,163 @@
+package locale
+
+import (
+	"fmt"
+	"strings"
+)
+
+func init() {
+	register(&Pack{
+		Code:        "zh_CN",
+		Nationality: "中国",
+		Currency:    "CNY",
+		Language:    "中文",
+		Surnames:    []string{"王", "李", "张", "刘", "陈", "杨", "黄", "赵", "吴", "周", "徐", "孙", "马", "朱", "胡", "郭", "何", "林"},
+		MaleNames:   []string{"伟", "强", "磊", "军", "洋", "勇", "杰", "浩然", "子轩", "宇航", "俊杰", "建国", "志强"},
+		FemaleNames: []string{"芳", "娜", "敏", "静", "丽", "婷", "雪", "欣怡", "梓涵", "雨桐", "思琪", "美玲", "晓燕"},
+		Cities: []City{
+			{"北京市", "北京", "100000", "Asia/Shanghai"},
+			{"上海市", "上海", "200000", "Asia/Shanghai"},
+			{"广州市", "广东省", "510000", "Asia/Shanghai"},
+			{"深圳市", "广东省", "518000", "Asia/Shanghai"},
+			{"杭州市", "浙江省", "310000", "Asia/Shanghai"},
+			{"成都市", "四川省", "610000", "Asia/Shanghai"},
+			{"乌鲁木齐市", "新疆维吾尔自治区", "830000", "Asia/Urumqi"},
+		},
+		Streets:     []string{"建国路", "人民路", "中山路", "解放大道", "长安街", "南京路", "科技园路", "文化路"},
+		EmailDomain: []string{"qq.com", "163.com", "126.com", "sina.com"},
+		fullName:    func(s, g string) string { return s + g },
+		phone: func(d string, stream bool) string {
+			if stream {
+				return "139" + d
+			}
+			return "138" + d
+		},
+		address: func(c City, street string, n int) string {
+			if c.Region == strings.TrimSuffix(c.Name, "市") {
+				return fmt.Sprintf("%s%s%d号", c.Name, street, n)
+			}
+			return fmt.Sprintf("%s%s%s%d号", c.Region, c.Name, street, n)
+		},
+	})
+
+	register(&Pack{
+		Code:        "en_US",
+		Nationality: "美国",
+		Currency:    "USD",
+		Language:    "英语",
+		Surnames:    []string{"Smith", "Johnson", "Williams", "Brown", "Jones", "Garcia", "Miller", "Davis", "Wilson", "Taylor"},
+		MaleNames:   []string{"James", "John", "Robert", "Michael", "David", "Daniel", "Matthew", "Ethan"},
+		FemaleNames: []string{"Mary", "Patricia", "Jennifer", "Linda", "Emily", "Sarah", "Olivia", "Emma"},
+		Cities: []City{
+			{"New York", "NY", "10001", "America/New_York"},
+			{"Boston", "MA", "02108", "America/New_York"},
+			{"Chicago", "IL", "60601", "America/Chicago"},
+			{"Houston", "TX", "77001", "America/Chicago"},
+			{"Denver", "CO", "80202", "America/Denver"},
+			{"Los Angeles", "CA", "90001", "America/Los_Angeles"},
+			{"Seattle", "WA", "98101", "America/Los_Angeles"},
+		},
+		Streets:     []string{"Main St", "Oak Ave", "Maple Dr", "Park Ave", "Cedar Ln", "Elm St", "Washington Blvd"},
+		EmailDomain: []string{"gmail.com", "yahoo.com", "outlook.com"},
+		fullName:    func(s, g string) string { return g + " " + s },
+		phone: func(d string, stream bool) string {
+			area := "2"
+			if stream {
+				area = "3"
+			}
+			return fmt.Sprintf("+1-%s%s-%s-%s0", area, d[:2], d[2:5], d[5:])
+		},
+		address: func(c City, street string, n int) string {
+			return fmt.Sprintf("%d %s, %s, %s %s", n, street, c.Name, c.Region, c.Postcode)
+		},
+	})
+
+	register(&Pack{
+		Code:        "en_GB",
+		Nationality: "英国",
+		Currency:    "GBP",
+		Language:    "英语",
+		Surnames:    []string{"Smith", "Jones", "Taylor", "Brown", "Williams", "Wilson", "Evans", "Thomas", "Roberts", "Walker"},
+		MaleNames:   []string{"Oliver", "George", "Harry", "Jack", "Charlie", "Thomas", "William", "Noah"},
+		FemaleNames: []string{"Olivia", "Amelia", "Isla", "Ava", "Emily", "Sophie", "Grace", "Lily"},
+		Cities: []City{
+			{"London", "Greater London", "SW1A 1AA", "Europe/London"},
+			{"Manchester", "Greater Manchester", "M1 1AE", "Europe/London"},
+			{"Edinburgh", "Scotland", "EH1 1YZ", "Europe/London"},
+			{"Bristol", "England", "BS1 4DJ", "Europe/London"},
+		},
+		Streets:     []string{"High Street", "Station Road", "Church Lane", "Victoria Road", "Green Lane", "Park Road"},
+		EmailDomain: []string{"gmail.com", "btinternet.com", "outlook.com"},
+		fullName:    func(s, g string) string { return g + " " + s },
+		phone: func(d string, stream bool) string {
+			prefix := "7700"
+			if stream {
+				prefix = "7900"
+			}
+			return fmt.Sprintf("+44-%s-%s", prefix, d)
+		},
+		address: func(c City, street string, n int) string {
+			return fmt.Sprintf("%d %s, %s %s", n, street, c.Name, c.Postcode)
+		},
+	})
+
+	register(&Pack{
+		Code:        "ja_JP",
+		Nationality: "日本",
+		Currency:    "JPY",
+		Language:    "日语",
+		Surnames:    []string{"佐藤", "鈴木", "高橋", "田中", "伊藤", "渡辺", "山本", "中村", "小林", "加藤"},
+		MaleNames:   []string{"翔太", "大輔", "拓也", "健太", "蓮", "悠真", "湊", "陽翔"},
+		FemaleNames: []string{"陽菜", "結衣", "美咲", "さくら", "葵", "凛", "芽依", "愛子"},
+		Cities: []City{
+			{"新宿区", "東京都", "160-0022", "Asia/Tokyo"},
+			{"渋谷区", "東京都", "150-0002", "Asia/Tokyo"},
+			{"大阪市北区", "大阪府", "530-0001", "Asia/Tokyo"},
+			{"名古屋市中区", "愛知県", "460-0008", "Asia/Tokyo"},
+			{"札幌市中央区", "北海道", "060-0001", "Asia/Tokyo"},
+		},
+		Streets:     []string{"西新宿", "神南", "梅田", "栄", "大通西"},
+		EmailDomain: []string{"yahoo.co.jp", "docomo.ne.jp", "gmail.com"},
+		fullName:    func(s, g string) string { return s + " " + g },
+		phone: func(d string, stream bool) string {
+			prefix := "90"
+			if stream {
+				prefix = "80"
+			}
+			return fmt.Sprintf("+81-%s-%s-%s", prefix, d[:4], d[4:])
+		},
+		address: func(c City, street string, n int) string {
+			return fmt.Sprintf("〒%s %s%s%s%d-%d", c.Postcode, c.Region, c.Name, street, n%9+1, n)
+		},
+	})
+
+	register(&Pack{
+		Code:        "de_DE",
+		Nationality: "德国",
+		Currency:    "EUR",
+		Language:    "德语",
+		Surnames:    []string{"Müller", "Schmidt", "Schneider", "Fischer", "Weber", "Meyer", "Wagner", "Becker", "Schulz", "Hoffmann"},
+		MaleNames:   []string{"Lukas", "Leon", "Finn", "Jonas", "Paul", "Felix", "Maximilian", "Elias"},
+		FemaleNames: []string{"Anna", "Lena", "Lea", "Hannah", "Mia", "Emma", "Sophie", "Marie"},
+		Cities: []City{
+			{"Berlin", "Berlin", "10115", "Europe/Berlin"},
+			{"München", "Bayern", "80331", "Europe/Berlin"},
+			{"Hamburg", "Hamburg", "20095", "Europe/Berlin"},
+			{"Köln", "Nordrhein-Westfalen", "50667", "Europe/Berlin"},
+			{"Frankfurt am Main", "Hessen", "60311", "Europe/Berlin"},
+		},
+		Streets:     []string{"Hauptstraße", "Bahnhofstraße", "Gartenstraße", "Schillerstraße", "Goethestraße", "Lindenstraße"},
+		EmailDomain: []string{"web.de", "gmx.de", "t-online.de"},
+		fullName:    func(s, g string) string { return g + " " + s },
+		phone: func(d string, stream bool) string {
+			prefix := "151"
+			if stream {
+				prefix = "152"
+			}
+			return fmt.Sprintf("+49-%s-%s", prefix, d)
+		},
+		address: func(c City, street string, n int) string {
+			return fmt.Sprintf("%s %d, %s %s", street, n, c.Postcode, c.Name)
+		},
+	})
+}
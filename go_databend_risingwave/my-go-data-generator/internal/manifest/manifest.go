@@ -0,0 +1,99 @@
+package manifest
+
+import (
+	"encoding/csv"
+	"os"
+	"strconv"
+	"sync"
+)
+
+// Entry 清单中的一条记录：哪张表的哪一行（主键）被注入了什么
+type Entry struct {
+	Table  string // 表名
+	ID     uint   // 主键
+	Column string // 涉及的列，整行级别的注入为空
+	Kind   string // 注入类型
+	Detail string // 补充说明
+}
+
+// Header 清单文件的表头
+var Header = []string{"table", "id", "column", "kind", "detail"}
+
+// Writer 并发安全的清单写入器，nil 值表示未启用，所有方法均不做任何事
+type Writer struct {
+	mu     sync.Mutex
+	file   *os.File
+	writer *csv.Writer
+	count  int
+}
+
+// Open 以追加方式打开清单文件，文件为空时先写入表头；path 为空时返回 nil（不记录）。
+// 多次运行的记录累积在同一文件中，与库中仍然存在的行一一对应
+func Open(path string) (*Writer, error) {
+	if path == "" {
+		return nil, nil
+	}
+	file, err := os.OpenFile(path, os.O_CREATE|os.O_WRONLY|os.O_APPEND, 0o644)
+	if err != nil {
+		return nil, err
+	}
+	info, err := file.Stat()
+	if err != nil {
+		file.Close()
+		return nil, err
+	}
+	w := &Writer{file: file, writer: csv.NewWriter(file)}
+	if info.Size() == 0 {
+		w.writer.Write(Header)
+		w.writer.Flush()
+		if err := w.writer.Error(); err != nil {
+			file.Close()
+			return nil, err
+		}
+	}
+	return w, nil
+}
+
+// Record 追加一条记录
+func (w *Writer) Record(e Entry) error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.count++
+	return w.writer.Write([]string{e.Table, strconv.FormatUint(uint64(e.ID), 10), e.Column, e.Kind, e.Detail})
+}
+
+// Count 已记录的条数
+func (w *Writer) Count() int {
+	if w == nil {
+		return 0
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	return w.count
+}
+
+// Flush 将缓冲写入文件，定时任务每次写入后调用以便实时查看
+func (w *Writer) Flush() error {
+	if w == nil {
+		return nil
+	}
+	w.mu.Lock()
+	defer w.mu.Unlock()
+	w.writer.Flush()
+	return w.writer.Error()
+}
+
+// Close 刷新缓冲并关闭文件
+func (w *Writer) Close() error {
+	if w == nil {
+		return nil
+	}
+	if err := w.Flush(); err != nil {
+		w.file.Close()
+		return err
+	}
+	return w.file.Close()
+}
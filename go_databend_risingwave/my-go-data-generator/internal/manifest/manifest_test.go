@@ -0,0 +1,34 @@
+package manifest
+
+import (
+	"os"
+	"path/filepath"
+	"testing"
+)
+
+func TestOpenAppends(t *testing.T) {
+	path := filepath.Join(t.TempDir(), "m.csv")
+	for _, id := range []uint{1, 2} {
+		w, err := Open(path)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Record(Entry{Table: "users", ID: id, Kind: "k"}); err != nil {
+			t.Fatal(err)
+		}
+		if err := w.Close(); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, err := os.ReadFile(path)
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := "table,id,column,kind,detail\nusers,1,,k,\nusers,2,,k,\n"
+	if string(got) != want {
+		t.Errorf("清单内容 = %q, want %q", got, want)
+	}
+	if w, err := Open(""); w != nil || err != nil {
+		t.Errorf("Open(\"\") = %v, %v, want nil, nil", w, err)
+	}
+}
@@ -0,0 +1,74 @@
+package models
+
+// 维度表：规范化模式（SCHEMA_MODE=normalized）下，产品、用户、订单与支付以维度ID引用
+// 分类、制造商、供应商、地区与支付方式，替代各行上的文本列；反规范化模式下不创建这些表
+
+// Category 商品分类维度
+type Category struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:64;not null;uniqueIndex:idx_category_name"` // 分类名称
+}
+
+// TableName 指定数据库中的表名
+func (Category) TableName() string {
+	return "categories"
+}
+
+// Manufacturer 制造商维度，RegionID 为制造商所在地区（即产品产地）
+type Manufacturer struct {
+	ID       uint   `gorm:"primaryKey"`
+	Name     string `gorm:"size:128;not null;uniqueIndex:idx_manufacturer_name"` // 制造商名称
+	RegionID *uint  `gorm:"index:idx_manufacturer_regionid"`                     // 所在地区ID（逻辑关系）
+}
+
+// TableName 指定数据库中的表名
+func (Manufacturer) TableName() string {
+	return "manufacturers"
+}
+
+// Supplier 供应商维度
+type Supplier struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:128;not null;uniqueIndex:idx_supplier_name"` // 供应商名称
+}
+
+// TableName 指定数据库中的表名
+func (Supplier) TableName() string {
+	return "suppliers"
+}
+
+// Region 地区（国家）维度，产品产地与用户国籍共用
+type Region struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:64;not null;uniqueIndex:idx_region_name"` // 地区名称
+}
+
+// TableName 指定数据库中的表名
+func (Region) TableName() string {
+	return "regions"
+}
+
+// PaymentMethod 支付方式维度，订单与支付记录共用
+type PaymentMethod struct {
+	ID   uint   `gorm:"primaryKey"`
+	Name string `gorm:"size:32;not null;uniqueIndex:idx_payment_method_name"` // 支付方式名称
+}
+
+// TableName 指定数据库中的表名
+func (PaymentMethod) TableName() string {
+	return "payment_methods"
+}
+
+// NormalizedColumns 规范化模式下各表以维度ID列替代的文本列：表 -> 文本列 -> ID 列
+// ID 列在两种模式下都存在（反规范化模式下为 NULL），文本列只在反规范化模式下存在
+var NormalizedColumns = map[string]map[string]string{
+	"products": {
+		"category":          "category_id",
+		"manufacturer":      "manufacturer_id",
+		"supplier":          "supplier_id",
+		"country_of_origin": "origin_region_id",
+	},
+	"users":    {"nationality": "region_id"},
+	"orders":   {"payment_method": "payment_method_id"},
+	"payments": {"method": "payment_method_id"},
+}
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"time"
+)
+
+// InventoryMovement 库存流水模型：入库、销售、退货与调整
+// 数量带符号（入库、退货为正，销售为负），产品的 Stock 等于其全部流水数量之和
+// 注意：ProductID、OrderID 为逻辑依赖，不启用真正的外键约束
+type InventoryMovement struct {
+	ID           uint      `gorm:"primaryKey;autoIncrement"`
+	ProductID    uint      `gorm:"not null;index:idx_movement_productid"`    // 产品ID（逻辑关系）
+	OrderID      *uint     `gorm:"index:idx_movement_orderid"`               // 关联订单ID（入库为 NULL）
+	MovementType string    `gorm:"size:16;not null;index:idx_movement_type"` // 流水类型
+	Quantity     int       `gorm:"not null"`                                 // 变动数量（带符号）
+	Note         *string   `gorm:"size:128"`                                 // 备注
+	MovementAt   time.Time `gorm:"not null;index:idx_movement_at"`           // 发生时间
+	CreatedAt    time.Time // 写入时间
+}
+
+// TableName 指定数据库中的表名
+func (InventoryMovement) TableName() string {
+	return "inventory_movements"
+}
+
+// 库存流水类型
+const (
+	MovementReceipt    = "入库"
+	MovementSale       = "销售"
+	MovementReturn     = "退货"
+	MovementAdjustment = "调整"
+)
@@ -2,38 +2,77 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/money"
 )
 
-// Order 订单模型（超过20个字段）
+// Order 订单模型（超过20个字段），即订单头，商品明细见 OrderItem
 // 注意：UserID、ProductID 为逻辑依赖，不启用真正的外键约束
+// gen 标签声明相互独立字段的生成器，金额与生命周期字段由生成器推导
 type Order struct {
-	ID              uint      `gorm:"primaryKey;autoIncrement"`
-	OrderNumber     string    `gorm:"size:64;not null;uniqueIndex:idx_ordernumber"` // 订单编号
-	UserID          uint      `gorm:"not null;index:idx_userid"`                     // 用户ID（逻辑关系）
-	ProductID       uint      `gorm:"not null;index:idx_productid"`                  // 产品ID（逻辑关系）
-	OrderDate       time.Time `gorm:"not null;index:idx_order_date"`               // 订单日期
-	Quantity        int       `gorm:"not null"`                                    // 数量
-	TotalAmount     float64   `gorm:"not null"`                                    // 总金额
-	PaymentMethod   string    `gorm:"size:32;not null"`                            // 支付方式
-	ShippingAddress string    `gorm:"size:256;not null"`                           // 收货地址
-	BillingAddress  string    `gorm:"size:256;not null"`                           // 账单地址
-	OrderStatus     string    `gorm:"size:32;not null;index:idx_order_status"`     // 订单状态
-	DiscountAmount  float64   `gorm:"not null"`                                    // 折扣金额
-	TaxAmount       float64   `gorm:"not null"`                                    // 税费
-	ShippingCost    float64   `gorm:"not null"`                                    // 运费
-	TrackingNumber  string    `gorm:"size:64;index:idx_tracking_number"`           // 物流单号
-	DeliveryDate    time.Time `gorm:"index:idx_delivery_date"`                     // 预计送达日期
-	ReturnStatus    string    `gorm:"size:32;index:idx_return_status"`             // 退货状态
-	CustomerNote    string    `gorm:"type:text"`                                   // 客户备注
-	InternalNote    string    `gorm:"type:text"`                                   // 内部备注
-	IsGift          bool      `gorm:"not null"`                                    // 是否礼物
-	GiftMessage     string    `gorm:"type:text"`                                   // 礼物留言
-	ExtraInfo       string    `gorm:"type:text"`                                   // 额外信息
-	CreatedAt       time.Time // 创建时间
-	UpdatedAt       time.Time // 更新时间
+	ID              uint           `gorm:"primaryKey;autoIncrement"`
+	OrderNumber     string         `gorm:"size:64;not null;uniqueIndex:idx_ordernumber" gen:"seq(ORD%010d)"` // 订单编号
+	UserID          uint           `gorm:"not null;index:idx_userid" gen:"ref(users)"`                       // 用户ID（逻辑关系）
+	ProductID       uint           `gorm:"not null;index:idx_productid"`                                     // 主产品ID（逻辑关系），即第一个订单行的产品
+	OrderDate       time.Time      `gorm:"not null;index:idx_order_date"`                                    // 订单日期
+	Quantity        int            `gorm:"not null"`                                                         // 总件数（各订单行数量之和）
+	Currency        string         `gorm:"size:8;not null;default:CNY"`                                      // 币种（与下单用户一致）
+	Subtotal        money.Amount   `gorm:"not null"`                                                         // 商品小计（各订单行小计之和）
+	TotalAmount     money.Amount   `gorm:"not null"`                                                         // 总金额 = 小计 - 折扣 + 税费 + 运费
+	PaymentMethod   string         `gorm:"size:32;not null" gen:"enum(信用卡:25,支付宝:40,微信支付:32,现金:3)"`          // 支付方式
+	PaymentMethodID *uint          `gorm:"index:idx_payment_methodid"`                                       // 支付方式维度ID（仅规范化模式）
+	ShippingAddress string         `gorm:"size:256;not null"`                                                // 收货地址
+	BillingAddress  string         `gorm:"size:256;not null"`                                                // 账单地址
+	OrderStatus     string         `gorm:"size:32;not null;index:idx_order_status"`                          // 订单状态
+	DiscountAmount  money.Amount   `gorm:"not null"`                                                         // 折扣金额（各订单行折扣之和）
+	TaxAmount       money.Amount   `gorm:"not null"`                                                         // 税费（各订单行税费之和）
+	ShippingCost    money.Amount   `gorm:"not null"`                                                         // 运费
+	TrackingNumber  *string        `gorm:"size:64;index:idx_tracking_number"`                                // 物流单号（发货前为 NULL）
+	ShippedAt       *time.Time     // 发货时间（发货前为 NULL）
+	DeliveryDate    *time.Time     `gorm:"index:idx_delivery_date"`         // 送达日期：已发货为预计送达，已完成为实际送达
+	ReturnStatus    *string        `gorm:"size:32;index:idx_return_status"` // 退货状态（仅已完成订单可能有值）
+	CustomerNote    *string        `gorm:"type:text"`                       // 客户备注
+	InternalNote    *string        `gorm:"type:text"`                       // 内部备注
+	IsGift          bool           `gorm:"not null"`                        // 是否礼物
+	GiftMessage     *string        `gorm:"type:text"`                       // 礼物留言（仅礼物订单）
+	ExtraInfo       *string        `gorm:"type:text"`                       // 额外信息
+	CreatedAt       time.Time      // 创建时间
+	UpdatedAt       time.Time      // 更新时间
+	DeletedAt       gorm.DeletedAt `gorm:"index:idx_order_deleted_at"` // 删除时间：列始终存在，仅 SOFT_DELETE 时由定时任务写入，否则为 NULL
 }
 
 // TableName 指定数据库中的表名
 func (Order) TableName() string {
 	return "orders"
-}
\ No newline at end of file
+}
+
+// 订单状态，按生命周期先后排列
+const (
+	OrderStatusPendingPayment = "待付款"
+	OrderStatusPaid           = "已付款"
+	OrderStatusPendingShip    = "待发货"
+	OrderStatusShipped        = "已发货"
+	OrderStatusCompleted      = "已完成"
+	OrderStatusCancelled      = "已取消"
+)
+
+// OrderStatuses 全部订单状态，按生命周期先后排列
+var OrderStatuses = []string{
+	OrderStatusPendingPayment, OrderStatusPaid, OrderStatusPendingShip,
+	OrderStatusShipped, OrderStatusCompleted, OrderStatusCancelled,
+}
+
+// IsShipped 订单是否已经发货（已发货或已完成）
+func (o Order) IsShipped() bool {
+	return o.OrderStatus == OrderStatusShipped || o.OrderStatus == OrderStatusCompleted
+}
+
+// IsPaid 订单是否已经支付
+func (o Order) IsPaid() bool {
+	switch o.OrderStatus {
+	case OrderStatusPaid, OrderStatusPendingShip, OrderStatusShipped, OrderStatusCompleted:
+		return true
+	}
+	return false
+}
@@ -0,0 +1,30 @@
+package models
+
+import (
+	"time"
+
+	"my-go-data-generator/internal/money"
+)
+
+// OrderItem 订单行模型，一笔订单包含一个或多个订单行
+// 订单头的小计、折扣、税费与数量等于其所有订单行之和
+// 注意：OrderID、ProductID 为逻辑依赖，不启用真正的外键约束
+type OrderItem struct {
+	ID             uint         `gorm:"primaryKey;autoIncrement"`
+	OrderID        uint         `gorm:"not null;uniqueIndex:idx_order_line"`                        // 订单ID（逻辑关系）
+	LineNumber     int          `gorm:"not null;uniqueIndex:idx_order_line"`                        // 行号，从 1 开始
+	ProductID      uint         `gorm:"not null;index:idx_item_productid" gen:"ref(products,zipf)"` // 产品ID（逻辑关系）
+	Quantity       int          `gorm:"not null" gen:"intrange(1,5)"`                               // 数量
+	Currency       string       `gorm:"size:8;not null;default:CNY"`                                // 币种（与订单一致）
+	UnitPrice      money.Amount `gorm:"not null"`                                                   // 下单时的成交单价快照（订单币种）
+	Subtotal       money.Amount `gorm:"not null"`                                                   // 行小计（单价×数量）
+	DiscountAmount money.Amount `gorm:"not null"`                                                   // 行折扣金额
+	TaxAmount      money.Amount `gorm:"not null"`                                                   // 行税费
+	LineTotal      money.Amount `gorm:"not null"`                                                   // 行金额 = 小计 - 折扣 + 税费
+	CreatedAt      time.Time    // 创建时间
+}
+
+// TableName 指定数据库中的表名
+func (OrderItem) TableName() string {
+	return "order_items"
+}
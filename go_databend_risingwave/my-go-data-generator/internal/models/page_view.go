@@ -0,0 +1,39 @@
+package models
+
+import (
+	"time"
+)
+
+// PageView 点击流事件模型：一次访问会话中的页面浏览、搜索、加购、结算与下单
+// 登录用户的 UserID 非空，匿名访客只有设备标识 AnonymousID；只有下单事件关联订单
+// 注意：UserID、ProductID、OrderID 为逻辑依赖，不启用真正的外键约束
+type PageView struct {
+	ID          uint      `gorm:"primaryKey;autoIncrement"`
+	SessionID   string    `gorm:"size:32;not null;uniqueIndex:idx_pv_session_seq"`        // 会话ID
+	Sequence    int       `gorm:"not null;uniqueIndex:idx_pv_session_seq"`                // 会话内序号，从 1 开始
+	AnonymousID string    `gorm:"size:32;not null;index:idx_pv_anonymousid"`              // 设备标识（访客 cookie）
+	UserID      *uint     `gorm:"index:idx_pv_userid"`                                    // 登录用户ID（匿名访问为 NULL）
+	ProductID   *uint     `gorm:"index:idx_pv_productid"`                                 // 浏览或加购的产品ID（非商品页为 NULL）
+	OrderID     *uint     `gorm:"index:idx_pv_orderid"`                                   // 下单事件关联的订单ID
+	EventType   string    `gorm:"size:16;not null;index:idx_pv_eventtype"`                // 事件类型
+	PageURL     string    `gorm:"size:255;not null"`                                      // 页面地址
+	Referrer    *string   `gorm:"size:255"`                                               // 来源页（直接访问为 NULL）
+	Device      string    `gorm:"size:16;not null" gen:"enum(移动端:50,App:30,桌面端:15,平板:5)"` // 设备类型
+	EventTime   time.Time `gorm:"not null;index:idx_pv_eventtime"`                        // 事件时间
+	CreatedAt   time.Time // 写入时间
+}
+
+// TableName 指定数据库中的表名
+func (PageView) TableName() string {
+	return "page_views"
+}
+
+// 点击流事件类型
+const (
+	PageViewHome      = "浏览首页"
+	PageViewSearch    = "搜索"
+	PageViewProduct   = "浏览商品"
+	PageViewAddToCart = "加入购物车"
+	PageViewCheckout  = "结算"
+	PageViewPurchase  = "下单"
+)
@@ -0,0 +1,71 @@
+package models
+
+import (
+	"time"
+
+	"my-go-data-generator/internal/money"
+)
+
+// Payment 支付记录模型，一笔订单可能有多次支付尝试
+// 支付方式与订单的 PaymentMethod 一致；已支付订单的成功支付金额之和等于订单总额，
+// 可能分为多笔部分支付，成功之前可能有失败的尝试
+// 注意：OrderID 为逻辑依赖，不启用真正的外键约束
+type Payment struct {
+	ID            uint         `gorm:"primaryKey;autoIncrement"`
+	OrderID       uint         `gorm:"not null;uniqueIndex:idx_order_attempt"`    // 订单ID（逻辑关系）
+	AttemptNumber int          `gorm:"not null;uniqueIndex:idx_order_attempt"`    // 第几次支付尝试，从 1 开始
+	Method        string       `gorm:"size:32;not null"`                          // 支付方式（与订单一致）
+	MethodID      *uint        `gorm:"column:payment_method_id"`                  // 支付方式维度ID（仅规范化模式）
+	Provider      string       `gorm:"size:32;not null"`                          // 支付渠道
+	TransactionID *string      `gorm:"size:64;index:idx_transaction_id"`          // 渠道交易号（现金支付为 NULL）
+	Status        string       `gorm:"size:16;not null;index:idx_payment_status"` // 支付状态
+	Amount        money.Amount `gorm:"not null"`                                  // 本次支付金额
+	Currency      string       `gorm:"size:8;not null;default:CNY"`               // 币种（与订单一致）
+	FailureReason *string      `gorm:"size:128"`                                  // 失败原因（仅失败的尝试）
+	PaidAt        *time.Time   `gorm:"index:idx_paid_at"`                         // 支付成功时间（未成功为 NULL）
+	CreatedAt     time.Time    // 发起支付的时间
+}
+
+// TableName 指定数据库中的表名
+func (Payment) TableName() string {
+	return "payments"
+}
+
+// 支付状态
+const (
+	PaymentStatusPending   = "待支付" // 已发起、尚未完成
+	PaymentStatusSucceeded = "成功"
+	PaymentStatusFailed    = "失败"
+	PaymentStatusClosed    = "已关闭" // 订单取消或超时后关闭
+)
+
+// PaymentMethodCash 现金支付，没有渠道交易号
+const PaymentMethodCash = "现金"
+
+// Refund 退款记录模型，仅出现在有 ReturnStatus 的订单上
+// 每笔退款对应一笔成功的支付，金额不超过该笔支付的金额
+// 注意：OrderID、PaymentID 为逻辑依赖，不启用真正的外键约束
+type Refund struct {
+	ID          uint         `gorm:"primaryKey;autoIncrement"`
+	OrderID     uint         `gorm:"not null;index:idx_refund_orderid"`        // 订单ID（逻辑关系）
+	PaymentID   uint         `gorm:"not null;index:idx_refund_paymentid"`      // 被退款的支付ID（逻辑关系）
+	Amount      money.Amount `gorm:"not null"`                                 // 退款金额
+	Currency    string       `gorm:"size:8;not null;default:CNY"`              // 币种（与订单一致）
+	Status      string       `gorm:"size:16;not null;index:idx_refund_status"` // 退款状态，由订单的退货状态决定
+	RequestedAt time.Time    `gorm:"not null"`                                 // 申请时间（送达之后）
+	RefundedAt  *time.Time   // 退款到账时间（仅已退款）
+	CreatedAt   time.Time    // 创建时间
+}
+
+// TableName 指定数据库中的表名
+func (Refund) TableName() string {
+	return "refunds"
+}
+
+// 退款状态
+const (
+	RefundStatusPending    = "待审核"
+	RefundStatusProcessing = "退款中"
+	RefundStatusRefunded   = "已退款"
+	RefundStatusRejected   = "已拒绝"
+)
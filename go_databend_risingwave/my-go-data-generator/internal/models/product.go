@@ -2,34 +2,48 @@ package models
 
 import (
 	"time"
+
+	"my-go-data-generator/internal/money"
 )
 
 // Product 产品模型，包含超过20个字段
+// gen 标签声明相互独立字段的生成器，其余字段由分类模型推导
 type Product struct {
-	ID              uint      `gorm:"primaryKey;autoIncrement"`
-	ProductName     string    `gorm:"size:128;not null;index:idx_productname"` // 产品名称
-	Category        string    `gorm:"size:64;not null;index:idx_category"`     // 分类
-	Description     string    `gorm:"type:text"`                               // 产品描述
-	Price           float64   `gorm:"not null"`                                // 价格
-	Stock           int       `gorm:"not null"`                                // 库存数量
-	SKU             string    `gorm:"size:64;not null;uniqueIndex:idx_sku"`    // 库存单位编码
-	Manufacturer    string    `gorm:"size:128;not null"`                       // 制造商
-	Weight          float64   `gorm:"not null"`                                // 重量
-	Dimensions      string    `gorm:"size:64;not null"`                        // 规格尺寸
-	Color           string    `gorm:"size:32;not null"`                        // 颜色
-	Material        string    `gorm:"size:64;not null"`                        // 材质
-	ReleaseDate     time.Time `gorm:"not null"`                                // 发布日期
-	WarrantyPeriod  string    `gorm:"size:32"`                                 // 保修期
-	CountryOfOrigin string    `gorm:"size:64;not null"`                        // 产地
-	Rating          float64   `gorm:"not null;index:idx_rating"`               // 评分
-	NumberOfReviews int       `gorm:"not null"`                                // 评论数
-	Discount        float64   `gorm:"not null"`                                // 折扣信息
-	StockStatus     string    `gorm:"size:32;not null;index:idx_stock_status"` // 库存状态
-	Supplier        string    `gorm:"size:128;not null"`                       // 供应商
-	CreatedAt       time.Time // 创建时间
-	UpdatedAt       time.Time // 更新时间
+	ID              uint         `gorm:"primaryKey;autoIncrement"`
+	ProductName     string       `gorm:"size:128;not null;index:idx_productname"` // 产品名称
+	Category        string       `gorm:"size:64;not null;index:idx_category"`     // 分类
+	CategoryID      *uint        `gorm:"index:idx_categoryid"`                    // 分类维度ID（仅规范化模式）
+	Description     *string      `gorm:"type:text"`                               // 产品描述（可为 NULL）
+	Price           money.Amount `gorm:"not null"`                                // 价格
+	Stock           int          `gorm:"not null"`                                // 库存数量
+	SKU             string       `gorm:"size:64;not null;uniqueIndex:idx_sku"`    // 库存单位编码
+	Manufacturer    string       `gorm:"size:128;not null"`                       // 制造商
+	ManufacturerID  *uint        `gorm:"index:idx_manufacturerid"`                // 制造商维度ID（仅规范化模式）
+	Weight          float64      `gorm:"not null"`                                // 重量
+	Dimensions      string       `gorm:"size:64;not null"`                        // 规格尺寸
+	Color           string       `gorm:"size:32;not null"`                        // 颜色
+	Material        string       `gorm:"size:64;not null"`                        // 材质
+	ReleaseDate     time.Time    `gorm:"not null" gen:"daterange(-3650d,0)"`      // 发布日期
+	WarrantyPeriod  *string      `gorm:"size:32"`                                 // 保修期（无保修为 NULL）
+	CountryOfOrigin string       `gorm:"size:64;not null"`                        // 产地
+	OriginRegionID  *uint        `gorm:"index:idx_origin_regionid"`               // 产地地区维度ID（仅规范化模式）
+	Rating          float64      `gorm:"not null;index:idx_rating"`               // 评分（评论平均星级，见 RatingOf）
+	NumberOfReviews int          `gorm:"not null"`                                // 评论数（reviews 表中该产品的评论数）
+	Discount        float64      `gorm:"not null" gen:"floatrange(0,0.5)"`        // 折扣信息（最大折扣率）
+	StockStatus     string       `gorm:"size:32;not null;index:idx_stock_status"` // 库存状态
+	Supplier        string       `gorm:"size:128;not null"`                       // 供应商
+	SupplierID      *uint        `gorm:"index:idx_supplierid"`                    // 供应商维度ID（仅规范化模式）
+	CreatedAt       time.Time    // 创建时间
+	UpdatedAt       time.Time    // 更新时间
 }
 
 func (Product) TableName() string {
 	return "products"
 }
+
+// 库存状态：缺货与预订时库存均为 0
+const (
+	StockStatusInStock    = "有货"
+	StockStatusOutOfStock = "缺货"
+	StockStatusPreOrder   = "预订"
+)
@@ -0,0 +1,32 @@
+package models
+
+import (
+	"math"
+	"time"
+)
+
+// Review 商品评论模型，只有购买过该商品的用户才会评论，每个订单中的每个商品最多一条评论
+// 产品的 Rating 与 NumberOfReviews 由其全部评论汇总得出，见 RatingOf
+// 注意：ProductID、UserID、OrderID 为逻辑依赖，不启用真正的外键约束
+type Review struct {
+	ID        uint      `gorm:"primaryKey;autoIncrement"`
+	ProductID uint      `gorm:"not null;uniqueIndex:idx_review_order_product;index:idx_review_productid"` // 产品ID（逻辑关系）
+	UserID    uint      `gorm:"not null;index:idx_review_userid"`                                         // 用户ID（逻辑关系，即下单用户）
+	OrderID   uint      `gorm:"not null;uniqueIndex:idx_review_order_product"`                            // 订单ID（逻辑关系）
+	Stars     int       `gorm:"not null"`                                                                 // 星级 1-5
+	Content   *string   `gorm:"type:text"`                                                                // 评论内容（只打分不写评论为 NULL）
+	CreatedAt time.Time `gorm:"index:idx_review_created_at"`                                              // 评论时间（送达之后）
+}
+
+// TableName 指定数据库中的表名
+func (Review) TableName() string {
+	return "reviews"
+}
+
+// RatingOf 根据评论星级之和与评论数计算产品评分：平均星级保留两位小数，没有评论时为 0
+func RatingOf(totalStars, count int) float64 {
+	if count == 0 {
+		return 0
+	}
+	return math.Round(float64(totalStars)/float64(count)*100) / 100
+}
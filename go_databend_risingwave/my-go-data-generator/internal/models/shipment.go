@@ -0,0 +1,35 @@
+package models
+
+import (
+	"time"
+)
+
+// ShipmentEvent 物流轨迹事件模型，只有已发货的订单才有物流事件
+// 同一订单的事件按 Sequence 排列，时间不早于发货时间、不晚于送达日期
+// 注意：OrderID 为逻辑依赖，不启用真正的外键约束
+type ShipmentEvent struct {
+	ID             uint      `gorm:"primaryKey;autoIncrement"`
+	OrderID        uint      `gorm:"not null;uniqueIndex:idx_order_event"`      // 订单ID（逻辑关系）
+	Sequence       int       `gorm:"not null;uniqueIndex:idx_order_event"`      // 事件序号，从 1 开始
+	TrackingNumber string    `gorm:"size:64;not null;index:idx_event_tracking"` // 物流单号（与订单一致）
+	Carrier        string    `gorm:"size:32;not null"`                          // 承运商
+	EventType      string    `gorm:"size:16;not null;index:idx_event_type"`     // 事件类型
+	Location       string    `gorm:"size:64;not null"`                          // 事件发生地
+	Description    string    `gorm:"size:256;not null"`                         // 事件描述
+	EventTime      time.Time `gorm:"not null;index:idx_event_time"`             // 事件发生时间
+	CreatedAt      time.Time // 写入时间，定时任务追加的事件在发生后才写入
+}
+
+// TableName 指定数据库中的表名
+func (ShipmentEvent) TableName() string {
+	return "shipment_events"
+}
+
+// 物流事件类型，按轨迹先后排列
+const (
+	ShipmentEventPickedUp       = "已揽收"
+	ShipmentEventInTransit      = "运输中"
+	ShipmentEventException      = "异常"
+	ShipmentEventOutForDelivery = "派送中"
+	ShipmentEventDelivered      = "已签收"
+)
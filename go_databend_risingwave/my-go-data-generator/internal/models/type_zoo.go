@@ -0,0 +1,80 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/clause"
+)
+
+// TypeZoo 覆盖 MySQL 各种数据类型的测试表（由 0002_type_zoo 迁移创建，TYPE_ZOO_ROWS > 0 时写入），各列以较高比例取类型的边界值，
+// 用于检验 Debezium/Flink CDC 同步到 Databend、RisingWave 后的类型映射是否保真
+// 除主键与时间戳外的列均可为 NULL；Gen 开头的列为生成列，由数据库根据其他列计算
+type TypeZoo struct {
+	ID uint `gorm:"primaryKey;autoIncrement"`
+
+	// 定点数与整数
+	DecimalSmall    *string `gorm:"type:decimal(5,2)"`     // DECIMAL(5,2)，以字符串传递避免浮点误差
+	DecimalWide     *string `gorm:"type:decimal(65,30)"`   // MySQL 允许的最大精度与小数位
+	TinyInt         *int8   `gorm:"type:tinyint"`          // -128 ~ 127
+	TinyIntUnsigned *uint8  `gorm:"type:tinyint unsigned"` // 0 ~ 255
+	SmallInt        *int16  `gorm:"type:smallint"`
+	MediumInt       *int32  `gorm:"type:mediumint"` // -8388608 ~ 8388607
+	IntUnsigned     *uint32 `gorm:"type:int unsigned"`
+	BigInt          *int64  `gorm:"type:bigint"`
+	BigIntUnsigned  *uint64 `gorm:"type:bigint unsigned"` // 超过 int64 上限的取值
+
+	// 浮点数与位串
+	FloatCol  *float32 `gorm:"type:float"`
+	DoubleCol *float64 `gorm:"type:double"`
+	BitCol    *uint64  `gorm:"type:bit(64)"`
+
+	// 字符串与二进制
+	CharCol      *string `gorm:"type:char(10)"` // 尾部空格在 CHAR 中会被去掉
+	VarcharCol   *string `gorm:"type:varchar(255)"`
+	TextCol      *string `gorm:"type:mediumtext"`
+	EnumCol      *string `gorm:"type:enum('','小','中','大')"`
+	SetCol       *string `gorm:"type:set('a','b','c','d')"`
+	JSONCol      *string `gorm:"type:json"`
+	BinaryCol    []byte  `gorm:"type:binary(16)"` // 不足 16 字节时以 0x00 补齐
+	VarbinaryCol []byte  `gorm:"type:varbinary(255)"`
+	BlobCol      []byte  `gorm:"type:blob"`
+
+	// 日期与时间
+	DateCol      *time.Time `gorm:"type:date"`
+	TimeCol      *string    `gorm:"type:time(6)"` // -838:59:59 ~ 838:59:59，以字符串传递
+	DatetimeCol  *time.Time `gorm:"type:datetime(6)"`
+	TimestampCol *time.Time `gorm:"type:timestamp(6) NULL"` // 1970 ~ 2038
+	YearCol      *int16     `gorm:"type:year"`              // 1901 ~ 2155，以及 0000
+
+	// 空间类型
+	PointCol    Spatial `gorm:"type:point"`
+	LineCol     Spatial `gorm:"type:linestring"`
+	PolygonCol  Spatial `gorm:"type:polygon"`
+	GeometryCol Spatial `gorm:"type:geometry"`
+
+	// 生成列：只读，插入与更新时跳过
+	GenSum     *int64  `gorm:"->;type:bigint GENERATED ALWAYS AS (small_int + medium_int) STORED"`
+	GenLabel   *string `gorm:"->;type:varchar(64) GENERATED ALWAYS AS (concat_ws('-', enum_col, year_col)) VIRTUAL"`
+	GenJSONKey *string `gorm:"->;type:varchar(255) GENERATED ALWAYS AS (json_unquote(json_extract(json_col, '$.key'))) VIRTUAL"`
+
+	CreatedAt time.Time `gorm:"type:datetime(6)"`
+	UpdatedAt time.Time `gorm:"type:datetime(6)"`
+}
+
+// TableName 指定数据库中的表名
+func (TypeZoo) TableName() string {
+	return "type_zoo"
+}
+
+// Spatial 以 WKT 表示的空间值，如 POINT(1 2)；空字符串写入 NULL
+type Spatial string
+
+// GormValue 写入时以 ST_GeomFromText 将 WKT 转换为空间类型
+func (s Spatial) GormValue(ctx context.Context, db *gorm.DB) clause.Expr {
+	if s == "" {
+		return clause.Expr{SQL: "NULL"}
+	}
+	return clause.Expr{SQL: "ST_GeomFromText(?)", Vars: []interface{}{string(s)}}
+}
@@ -2,35 +2,66 @@ package models
 
 import (
 	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/money"
 )
 
 // User 用户模型（超过20个字段），增加了性别字段
+// gen 标签声明相互独立字段的生成器，其余字段由生成器根据这些字段推导
 type User struct {
-	ID                uint      `gorm:"primaryKey;autoIncrement"`
-	Username          string    `gorm:"size:64;not null;index:idx_username"`       // 用户名
-	Gender            string    `gorm:"size:10;not null;index:idx_gender"`         // 性别
-	Age               int       `gorm:"not null"`                                  // 年龄
-	Email             string    `gorm:"size:128;not null;uniqueIndex:idx_email"`   // 邮箱
-	Phone             string    `gorm:"size:20;not null;uniqueIndex:idx_phone"`    // 电话
-	Address           string    `gorm:"size:256;not null"`                         // 地址
-	Nationality       string    `gorm:"size:64;not null"`                          // 国籍
-	Occupation        string    `gorm:"size:64;not null"`                          // 职业
-	MaritalStatus     string    `gorm:"size:16;not null;index:idx_marital_status"` // 婚姻状况
-	Education         string    `gorm:"size:64;not null"`                          // 教育程度
-	Hobby             string    `gorm:"size:128"`                                  // 爱好
-	Income            float64   `gorm:"not null"`                                  // 收入
-	RegistrationDate  time.Time `gorm:"not null;index:idx_registration_date"`      // 注册日期
-	LastLogin         time.Time `gorm:"not null"`                                  // 最后登录时间
-	LoyaltyPoints     int       `gorm:"not null"`                                  // 忠诚积分
-	PreferredLanguage string    `gorm:"size:32;not null"`                          // 首选语言
-	Currency          string    `gorm:"size:8;not null"`                           // 币种
-	Timezone          string    `gorm:"size:32;not null"`                          // 时区
-	Status            string    `gorm:"size:16;not null;index:idx_status"`         // 用户状态
-	CreatedAt         time.Time // 创建时间
-	UpdatedAt         time.Time // 更新时间
+	ID                uint           `gorm:"primaryKey;autoIncrement"`
+	Username          string         `gorm:"size:64;not null;index:idx_username"`                           // 用户名
+	Gender            string         `gorm:"size:10;not null;index:idx_gender" gen:"enum(男:49,女:49,其他:2)"`  // 性别
+	Age               int            `gorm:"not null"`                                                      // 年龄
+	Email             string         `gorm:"size:128;not null;uniqueIndex:idx_email"`                       // 邮箱
+	Phone             string         `gorm:"size:20;not null;uniqueIndex:idx_phone"`                        // 电话
+	Address           string         `gorm:"size:256;not null"`                                             // 地址
+	Nationality       string         `gorm:"size:64;not null"`                                              // 国籍
+	RegionID          *uint          `gorm:"index:idx_user_regionid"`                                       // 国籍地区维度ID（仅规范化模式）
+	Occupation        string         `gorm:"size:64;not null"`                                              // 职业
+	MaritalStatus     string         `gorm:"size:16;not null;index:idx_marital_status"`                     // 婚姻状况
+	Education         string         `gorm:"size:64;not null"`                                              // 教育程度
+	Hobby             *string        `gorm:"size:128"`                                                      // 爱好（可为 NULL）
+	Income            money.Amount   `gorm:"not null"`                                                      // 收入
+	RegistrationDate  time.Time      `gorm:"not null;index:idx_registration_date"`                          // 注册日期
+	LastLogin         time.Time      `gorm:"not null"`                                                      // 最后登录时间
+	LoyaltyPoints     int            `gorm:"not null"`                                                      // 忠诚积分
+	PreferredLanguage string         `gorm:"size:32;not null"`                                              // 首选语言
+	Currency          string         `gorm:"size:8;not null"`                                               // 币种
+	Timezone          string         `gorm:"size:32;not null"`                                              // 时区
+	Status            string         `gorm:"size:16;not null;index:idx_status" gen:"enum(活跃:88,冻结:4,注销:8)"` // 用户状态
+	CreatedAt         time.Time      // 创建时间
+	UpdatedAt         time.Time      // 更新时间
+	DeletedAt         gorm.DeletedAt `gorm:"index:idx_user_deleted_at"` // 删除时间：列始终存在，仅 SOFT_DELETE 时由定时任务写入，否则为 NULL
 }
 
 // TableName 指定数据库中的表名
 func (User) TableName() string {
 	return "users"
 }
+
+// 用户状态
+const (
+	UserStatusActive = "活跃"
+	UserStatusFrozen = "冻结"
+	UserStatusClosed = "注销" // 已注销的用户会被定时任务清理（删除）
+)
+
+// 婚姻状况与职业中带有年龄约束的取值
+const (
+	MaritalSingle   = "未婚"
+	MaritalMarried  = "已婚"
+	MaritalDivorced = "离异"
+	MaritalWidowed  = "丧偶"
+
+	OccupationStudent = "学生"
+	OccupationRetired = "退休"
+)
+
+const (
+	// MinMarriageAge 法定最低结婚年龄，低于该年龄的用户只能是未婚
+	MinMarriageAge = 20
+	// MaxLoyaltyPointsPerDay 每在网一天最多累积的忠诚积分
+	MaxLoyaltyPointsPerDay = 2.0
+)
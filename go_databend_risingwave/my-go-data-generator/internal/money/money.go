@@ -0,0 +1,214 @@
+// Package money 以分为单位的定点金额：加减为整数运算，乘以比率时一次性四舍五入到指定小数位，
+// 汇总不会因浮点误差差几分钱。数据库列类型由 MONEY_TYPE 决定：double（默认）或 decimal(18,2)
+package money
+
+import (
+	"database/sql/driver"
+	"fmt"
+	"math"
+	"math/big"
+	"strconv"
+	"strings"
+
+	"gorm.io/gorm"
+	"gorm.io/gorm/schema"
+)
+
+// Amount 以分（0.01）为单位的金额
+type Amount int64
+
+// Scale 每单位金额的分数
+const Scale = 100
+
+// decimal 是否以 DECIMAL(18,2) 存储金额列，由 UseDecimal 在迁移前设置
+var decimal bool
+
+// UseDecimal 设置金额列的数据库类型：true 为 DECIMAL(18,2)，false 为 DOUBLE
+func UseDecimal(on bool) {
+	decimal = on
+}
+
+// Decimal 金额列是否以 DECIMAL(18,2) 存储
+func Decimal() bool {
+	return decimal
+}
+
+// ColumnType 金额列的数据库类型
+func ColumnType() string {
+	if decimal {
+		return "decimal(18,2)"
+	}
+	return "double"
+}
+
+// FromFloat 将浮点数按分四舍五入为金额，用于随机生成的价格与收入
+func FromFloat(f float64) Amount {
+	return Amount(math.Round(f * Scale))
+}
+
+// Parse 解析十进制字符串，如 "123.45"、"-0.5"、"12"；超过两位小数时按分四舍五入
+func Parse(s string) (Amount, error) {
+	s = strings.TrimSpace(s)
+	r, ok := new(big.Rat).SetString(s)
+	if !ok {
+		return 0, fmt.Errorf("金额 %q 格式错误", s)
+	}
+	r.Mul(r, big.NewRat(Scale, 1))
+	v, ok := roundRat(r)
+	if !ok {
+		return 0, fmt.Errorf("金额 %q 超出范围", s)
+	}
+	return Amount(v), nil
+}
+
+// parseFloat 将读取到的浮点数按分四舍五入为金额，NaN、无穷大与超出 int64 范围的值返回错误
+func parseFloat(f float64) (Amount, error) {
+	c := math.Round(f * Scale)
+	if math.IsNaN(c) || math.IsInf(c, 0) || c >= math.MaxInt64 || c < math.MinInt64 {
+		return 0, fmt.Errorf("金额 %v 无效或超出范围", f)
+	}
+	return Amount(c), nil
+}
+
+// parseInt 将读取到的整数（元）转为金额，超出范围时返回错误
+func parseInt(v int64) (Amount, error) {
+	if v > math.MaxInt64/Scale || v < math.MinInt64/Scale {
+		return 0, fmt.Errorf("金额 %d 超出范围", v)
+	}
+	return Amount(v * Scale), nil
+}
+
+// Float 以浮点数表示，仅用于比率计算与展示
+func (a Amount) Float() float64 {
+	return float64(a) / Scale
+}
+
+// String 两位小数的十进制表示，如 -123.45
+func (a Amount) String() string {
+	sign := ""
+	v := int64(a)
+	if v < 0 {
+		sign, v = "-", -v
+	}
+	return fmt.Sprintf("%s%d.%02d", sign, v/Scale, v%Scale)
+}
+
+// Times 乘以数量
+func (a Amount) Times(n int) Amount {
+	return a * Amount(n)
+}
+
+// Mul 乘以比率并四舍五入到 decimals 位小数（0~2），比率按百万分之一精度参与整数运算
+func (a Amount) Mul(rate float64, decimals int) Amount {
+	return a.MulDiv(int64(math.Round(rate*1e6)), 1e6, decimals)
+}
+
+// MulDiv 计算 a×num/den 并四舍五入到 decimals 位小数（0~2），用于按比例分摊；结果超出 int64 范围时 panic
+func (a Amount) MulDiv(num, den int64, decimals int) Amount {
+	unit := int64(math.Pow10(2 - decimals))
+	if abs(int64(a)) < 1<<31 && abs(num) < 1<<31 {
+		return Amount(divRound(int64(a)*num, den*unit) * unit)
+	}
+	r := new(big.Rat).SetFrac(new(big.Int).Mul(big.NewInt(int64(a)), big.NewInt(num)), big.NewInt(den*unit))
+	v, ok := roundRat(r)
+	if !ok || abs(v) > math.MaxInt64/unit {
+		panic(fmt.Sprintf("金额 %d×%d/%d 超出范围", a, num, den))
+	}
+	return Amount(v * unit)
+}
+
+func abs(v int64) int64 {
+	if v < 0 {
+		return -v
+	}
+	return v
+}
+
+// divRound 整数除法，四舍五入（远离零），d 为正数
+func divRound(n, d int64) int64 {
+	q, r := n/d, n%d
+	if 2*abs(r) >= d {
+		if n < 0 {
+			q--
+		} else {
+			q++
+		}
+	}
+	return q
+}
+
+// Round 四舍五入到 decimals 位小数（0~2），如日元取整到元
+func (a Amount) Round(decimals int) Amount {
+	return a.MulDiv(1, 1, decimals)
+}
+
+// roundRat 四舍五入（远离零）为整数，结果超出 int64 范围时 ok 为 false
+func roundRat(r *big.Rat) (v int64, ok bool) {
+	num, den := new(big.Int).Set(r.Num()), r.Denom()
+	neg := num.Sign() < 0
+	num.Abs(num)
+	q, m := new(big.Int).QuoRem(num, den, new(big.Int))
+	if m.Lsh(m, 1).Cmp(den) >= 0 {
+		q.Add(q, big.NewInt(1))
+	}
+	if neg {
+		q.Neg(q)
+	}
+	if !q.IsInt64() {
+		return 0, false
+	}
+	return q.Int64(), true
+}
+
+// Value 实现 driver.Valuer：DECIMAL 列写入十进制字符串，DOUBLE 列写入浮点数
+func (a Amount) Value() (driver.Value, error) {
+	if decimal {
+		return a.String(), nil
+	}
+	return a.Float(), nil
+}
+
+// Scan 实现 sql.Scanner，读取 DECIMAL（字符串）或 DOUBLE（浮点数）列；也用于 FIELD_GENERATORS 生成的数值
+func (a *Amount) Scan(src interface{}) error {
+	switch v := src.(type) {
+	case nil:
+		*a = 0
+	case float64:
+		return a.set(parseFloat(v))
+	case float32:
+		return a.set(parseFloat(float64(v)))
+	case int64:
+		return a.set(parseInt(v))
+	case int:
+		return a.set(parseInt(int64(v)))
+	case []byte:
+		return a.Scan(string(v))
+	case string:
+		p, err := Parse(v)
+		if err != nil {
+			f, ferr := strconv.ParseFloat(v, 64)
+			if ferr != nil {
+				return err
+			}
+			return a.set(parseFloat(f))
+		}
+		*a = p
+	default:
+		return fmt.Errorf("无法将 %T 读取为金额", src)
+	}
+	return nil
+}
+
+// set 读取成功时写入金额，失败时保留原值
+func (a *Amount) set(v Amount, err error) error {
+	if err != nil {
+		return err
+	}
+	*a = v
+	return nil
+}
+
+// GormDBDataType 迁移时按 MONEY_TYPE 决定列类型
+func (Amount) GormDBDataType(db *gorm.DB, field *schema.Field) string {
+	return ColumnType()
+}
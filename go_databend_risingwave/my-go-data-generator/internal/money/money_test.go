@@ -0,0 +1,253 @@
+package money
+
+import (
+	"math"
+	"testing"
+)
+
+func TestParse(t *testing.T) {
+	tests := []struct {
+		in      string
+		want    Amount
+		wantErr bool
+	}{
+		{"123.45", 12345, false},
+		{"-0.5", -50, false},
+		{"12", 1200, false},
+		{" 7.10 ", 710, false},
+		{"0", 0, false},
+		{"0.004", 0, false},
+		{"0.005", 1, false},   // 半分远离零进位
+		{"-0.005", -1, false}, // 负数同样远离零
+		{"-0.004", 0, false},
+		{"1.994999", 199, false},
+		{"1.995", 200, false},
+		{"92233720368547758.07", math.MaxInt64, false},
+		{"1e2", 10000, false},
+		{"92233720368547758.08", 0, true}, // 超出 int64 范围报错而不是回绕
+		{"-92233720368547758.09", 0, true},
+		{"1e30", 0, true},
+		{"", 0, true},
+		{"abc", 0, true},
+		{"1.2.3", 0, true},
+	}
+	for _, tt := range tests {
+		got, err := Parse(tt.in)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Parse(%q) err = %v, wantErr %v", tt.in, err, tt.wantErr)
+			continue
+		}
+		if got != tt.want {
+			t.Errorf("Parse(%q) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestString(t *testing.T) {
+	tests := []struct {
+		in   Amount
+		want string
+	}{
+		{0, "0.00"},
+		{5, "0.05"},
+		{-5, "-0.05"},
+		{100, "1.00"},
+		{12345, "123.45"},
+		{-12345, "-123.45"},
+		{-100, "-1.00"},
+	}
+	for _, tt := range tests {
+		if got := tt.in.String(); got != tt.want {
+			t.Errorf("Amount(%d).String() = %q, want %q", tt.in, got, tt.want)
+		}
+		// String 与 Parse 互为逆运算
+		if back, err := Parse(tt.want); err != nil || back != tt.in {
+			t.Errorf("Parse(%q) = %d, %v, want %d", tt.want, back, err, tt.in)
+		}
+	}
+}
+
+func TestFromFloat(t *testing.T) {
+	tests := []struct {
+		in   float64
+		want Amount
+	}{
+		{0, 0},
+		{12.34, 1234},
+		{-12.34, -1234},
+		{0.1 + 0.2, 30},
+		{19.999, 2000},
+		{-0.006, -1},
+	}
+	for _, tt := range tests {
+		if got := FromFloat(tt.in); got != tt.want {
+			t.Errorf("FromFloat(%v) = %d, want %d", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestMulDiv(t *testing.T) {
+	tests := []struct {
+		a        Amount
+		num, den int64
+		decimals int
+		want     Amount
+	}{
+		{1000, 1, 3, 2, 333},
+		{2000, 1, 3, 2, 667},
+		{-2000, 1, 3, 2, -667},
+		{1, 1, 2, 2, 1},   // 0.005 元进位到 0.01
+		{-1, 1, 2, 2, -1}, // 远离零
+		{3, 1, 2, 2, 2},   // 0.015 → 0.02
+		{1, 1, 3, 2, 0},
+		{10000, 7, 10, 2, 7000},
+		{12345, 1, 1, 1, 12350}, // 到角
+		{12344, 1, 1, 1, 12340},
+		{12350, 1, 1, 0, 12400}, // 到元
+		{12349, 1, 1, 0, 12300},
+		{-12350, 1, 1, 0, -12400},
+		{0, 5, 7, 2, 0},
+		{1<<31 - 1, 1<<31 - 1, 1 << 31, 2, 1<<31 - 2}, // int64 分支的上限
+		{1 << 40, 1, 3, 2, 366503875925},              // big.Rat 分支
+		{-(1 << 40), 1, 3, 2, -366503875925},
+		{1 << 40, 1, 2, 0, 549755813900},
+		{3, 1 << 40, 1 << 41, 2, 2}, // 大比例走 big.Rat 分支，仍远离零进位
+		{-3, 1 << 40, 1 << 41, 2, -2},
+		{math.MaxInt64 / 2, 2, 2, 2, math.MaxInt64 / 2}, // 中间结果超出 int64 也不溢出
+	}
+	for _, tt := range tests {
+		if got := tt.a.MulDiv(tt.num, tt.den, tt.decimals); got != tt.want {
+			t.Errorf("Amount(%d).MulDiv(%d, %d, %d) = %d, want %d", tt.a, tt.num, tt.den, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestMulDivOverflow(t *testing.T) {
+	defer func() {
+		if recover() == nil {
+			t.Error("MulDiv 结果超出 int64 范围时应 panic")
+		}
+	}()
+	Amount(math.MaxInt64).MulDiv(3, 2, 2)
+}
+
+func TestMul(t *testing.T) {
+	tests := []struct {
+		a        Amount
+		rate     float64
+		decimals int
+		want     Amount
+	}{
+		{1000, 0.13, 2, 130},
+		{999, 0.13, 2, 130},     // 129.87 分 → 130
+		{12345, 0.085, 2, 1049}, // 1049.325 分 → 1049
+		{-999, 0.13, 2, -130},
+		{50, 0.09, 2, 5},   // 4.5 分 → 5
+		{-50, 0.09, 2, -5}, // -4.5 分 → -5
+		{10000, 0.14, 2, 1400},
+		{12345, 21, 0, 259200}, // 2592.45 日元 → 2592
+		{12345, 1, 0, 12300},
+		{1, 0.1234564, 2, 0}, // 比率按百万分之一取整
+		{100, 1e-6, 2, 0},
+		{1 << 40, 0.5, 2, 1 << 39},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Mul(tt.rate, tt.decimals); got != tt.want {
+			t.Errorf("Amount(%d).Mul(%v, %d) = %d, want %d", tt.a, tt.rate, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		a        Amount
+		decimals int
+		want     Amount
+	}{
+		{12345, 2, 12345},
+		{12345, 1, 12350},
+		{-12345, 1, -12350},
+		{12345, 0, 12300},
+		{12350, 0, 12400},
+		{-12350, 0, -12400},
+		{49, 0, 0},
+		{50, 0, 100},
+		{-50, 0, -100},
+	}
+	for _, tt := range tests {
+		if got := tt.a.Round(tt.decimals); got != tt.want {
+			t.Errorf("Amount(%d).Round(%d) = %d, want %d", tt.a, tt.decimals, got, tt.want)
+		}
+	}
+}
+
+// TestDivRoundAgainstRat 用 big.Rat 分支交叉校验 int64 分支的四舍五入
+func TestDivRoundAgainstRat(t *testing.T) {
+	for n := int64(-1000); n <= 1000; n++ {
+		for _, d := range []int64{1, 2, 3, 7, 10, 100, 999} {
+			small := divRound(n, d)
+			big := Amount(n).MulDiv(1<<40, d<<40, 2)
+			if Amount(small) != big {
+				t.Fatalf("divRound(%d, %d) = %d, big.Rat 分支 = %d", n, d, small, big)
+			}
+		}
+	}
+}
+
+func TestScan(t *testing.T) {
+	tests := []struct {
+		src     interface{}
+		want    Amount
+		wantErr bool
+	}{
+		{nil, 0, false},
+		{12.34, 1234, false},
+		{float32(0.5), 50, false},
+		{int64(5), 500, false},
+		{-3, -300, false},
+		{"12.345", 1235, false},
+		{[]byte("0.1"), 10, false},
+		{"-0.005", -1, false},
+		{"NaN", 0, true},
+		{"1e300", 0, true},
+		{math.NaN(), 0, true},
+		{math.Inf(1), 0, true},
+		{float32(math.Inf(-1)), 0, true},
+		{1e30, 0, true},
+		{int64(math.MaxInt64), 0, true},
+		{"x", 0, true},
+		{true, 0, true},
+	}
+	for _, tt := range tests {
+		a := Amount(42)
+		err := a.Scan(tt.src)
+		if (err != nil) != tt.wantErr {
+			t.Errorf("Scan(%#v) err = %v, wantErr %v", tt.src, err, tt.wantErr)
+			continue
+		}
+		if !tt.wantErr && a != tt.want {
+			t.Errorf("Scan(%#v) = %d, want %d", tt.src, a, tt.want)
+		}
+	}
+}
+
+func TestValue(t *testing.T) {
+	defer UseDecimal(Decimal())
+	tests := []struct {
+		decimal bool
+		a       Amount
+		want    interface{}
+	}{
+		{true, 12345, "123.45"},
+		{true, -5, "-0.05"},
+		{false, 12345, 123.45},
+		{false, -5, -0.05},
+	}
+	for _, tt := range tests {
+		UseDecimal(tt.decimal)
+		got, err := tt.a.Value()
+		if err != nil || got != tt.want {
+			t.Errorf("UseDecimal(%v) Amount(%d).Value() = %#v, %v, want %#v", tt.decimal, tt.a, got, err, tt.want)
+		}
+	}
+}
@@ -0,0 +1,147 @@
+package pricing
+
+import (
+	"math"
+
+	"my-go-data-generator/internal/money"
+)
+
+// 订单金额公式：TotalAmount = Subtotal - DiscountAmount + TaxAmount + ShippingCost
+// 订单行：小计 = 单价 × 数量，折扣与税费按行计算；订单头的小计、折扣、税费为各行之和，
+// 运费按整单折后金额与总件数计算。产品价格以人民币存储，订单金额按用户币种换算，
+// 所有金额为以分为单位的定点数，乘以税率、汇率、折扣率时一次性按币种的最小单位取整（人民币到分，日元到元）
+
+// BaseCurrency 产品价格的计价币种
+const BaseCurrency = "CNY"
+
+// taxRates 各分类适用的增值税税率
+var taxRates = map[string]float64{
+	"电子产品": 0.13,
+	"家居用品": 0.13,
+	"服装":   0.13,
+	"运动器材": 0.13,
+	"食品":   0.09,
+}
+
+// exchangeRates 1 元人民币可兑换的外币数量
+var exchangeRates = map[string]float64{
+	"CNY": 1,
+	"USD": 0.14,
+	"GBP": 0.11,
+	"EUR": 0.13,
+	"JPY": 21,
+}
+
+// currencyDecimals 币种的小数位数，未列出的币种保留两位
+var currencyDecimals = map[string]int{
+	"JPY": 0,
+}
+
+const defaultTaxRate = 0.13 // 未知分类的默认税率
+
+const (
+	freeShippingThreshold money.Amount = 99_00 // 满额包邮门槛（人民币，按折后金额计算）
+	baseShippingCost      money.Amount = 8_00  // 基础运费（人民币）
+	perItemShippingCost   money.Amount = 2_00  // 每增加一件商品的附加运费（人民币）
+)
+
+// Line 一个订单行的金额拆分，单价为按订单币种换算后的成交价快照
+type Line struct {
+	UnitPrice money.Amount // 成交单价
+	Subtotal  money.Amount // 行小计
+	Discount  money.Amount // 行折扣
+	Tax       money.Amount // 行税费
+	Total     money.Amount // 行金额 = 小计 - 折扣 + 税费
+}
+
+// Amounts 一笔订单的金额拆分
+type Amounts struct {
+	Subtotal money.Amount // 商品小计
+	Discount money.Amount // 折扣金额
+	Tax      money.Amount // 税费
+	Shipping money.Amount // 运费
+	Total    money.Amount // 应付总额
+}
+
+// Round 四舍五入保留两位小数，用于重量等非金额的数值
+func Round(v float64) float64 {
+	return math.Round(v*100) / 100
+}
+
+// Decimals 币种的小数位数
+func Decimals(currency string) int {
+	if decimals, ok := currencyDecimals[currency]; ok {
+		return decimals
+	}
+	return 2
+}
+
+// RoundIn 按币种的最小单位四舍五入
+func RoundIn(v money.Amount, currency string) money.Amount {
+	return v.Round(Decimals(currency))
+}
+
+// Convert 将人民币金额换算为指定币种并取整，未知币种按人民币处理
+func Convert(amountCNY money.Amount, currency string) money.Amount {
+	rate, ok := exchangeRates[currency]
+	if !ok {
+		rate = 1
+	}
+	return amountCNY.Mul(rate, Decimals(currency))
+}
+
+// TaxRate 返回指定分类的税率
+func TaxRate(category string) float64 {
+	if rate, ok := taxRates[category]; ok {
+		return rate
+	}
+	return defaultTaxRate
+}
+
+// Shipping 根据折后金额和件数计算运费，满额包邮；门槛与运费均按币种换算
+func Shipping(discounted money.Amount, quantity int, currency string) money.Amount {
+	if discounted >= Convert(freeShippingThreshold, currency) || quantity <= 0 {
+		return 0
+	}
+	return Convert(baseShippingCost+perItemShippingCost.Times(quantity-1), currency)
+}
+
+// MaxDiscount 返回小计在给定折扣率下允许的最大折扣金额
+func MaxDiscount(subtotal money.Amount, discountRate float64, currency string) money.Amount {
+	return subtotal.Mul(discountRate, Decimals(currency))
+}
+
+// ComputeLine 按单价（人民币）、数量、实际折扣率、分类和订单币种计算一个订单行的金额
+// discountRate 不应超过产品自身的 Discount
+func ComputeLine(unitPriceCNY money.Amount, quantity int, discountRate float64, category, currency string) Line {
+	unitPrice := Convert(unitPriceCNY, currency)
+	subtotal := unitPrice.Times(quantity)
+	return LineFromSubtotal(unitPrice, subtotal, MaxDiscount(subtotal, discountRate, currency), category, currency)
+}
+
+// LineFromSubtotal 在已知单价、小计和折扣金额的情况下计算订单行的税费与行金额
+func LineFromSubtotal(unitPrice, subtotal, discount money.Amount, category, currency string) Line {
+	discounted := subtotal - discount
+	tax := discounted.Mul(TaxRate(category), Decimals(currency))
+	return Line{
+		UnitPrice: unitPrice,
+		Subtotal:  subtotal,
+		Discount:  discount,
+		Tax:       tax,
+		Total:     discounted + tax,
+	}
+}
+
+// Sum 汇总订单行得到整单金额，quantity 为总件数，运费按整单折后金额计算；定点金额的加减没有误差，无需再取整
+func Sum(lines []Line, quantity int, currency string) Amounts {
+	var a Amounts
+	for _, l := range lines {
+		a.Subtotal += l.Subtotal
+		a.Discount += l.Discount
+		a.Tax += l.Tax
+	}
+	discounted := a.Subtotal - a.Discount
+	a.Shipping = Shipping(discounted, quantity, currency)
+	a.Total = discounted + a.Tax + a.Shipping
+	return a
+}
@@ -0,0 +1,198 @@
+package pricing
+
+import (
+	"testing"
+
+	"my-go-data-generator/internal/money"
+)
+
+func TestConvert(t *testing.T) {
+	tests := []struct {
+		amount   money.Amount
+		currency string
+		want     money.Amount
+	}{
+		{10000, "CNY", 10000},
+		{10000, "USD", 1400},
+		{999, "EUR", 130}, // 129.87 分 → 130
+		{-999, "EUR", -130},
+		{1, "USD", 0},          // 0.14 分 → 0
+		{4, "USD", 1},          // 0.56 分 → 1
+		{12345, "JPY", 259200}, // 2592.45 日元取整到元
+		{12381, "JPY", 260000}, // 2600.01 日元
+		{500, "XXX", 500},      // 未知币种按人民币处理
+		{0, "GBP", 0},
+	}
+	for _, tt := range tests {
+		if got := Convert(tt.amount, tt.currency); got != tt.want {
+			t.Errorf("Convert(%d, %s) = %d, want %d", tt.amount, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestDecimalsAndRoundIn(t *testing.T) {
+	tests := []struct {
+		currency string
+		decimals int
+		in, want money.Amount
+	}{
+		{"CNY", 2, 12345, 12345},
+		{"USD", 2, 12345, 12345},
+		{"JPY", 0, 12345, 12300},
+		{"JPY", 0, 12350, 12400},
+		{"JPY", 0, -12350, -12400},
+		{"", 2, 1, 1},
+	}
+	for _, tt := range tests {
+		if got := Decimals(tt.currency); got != tt.decimals {
+			t.Errorf("Decimals(%q) = %d, want %d", tt.currency, got, tt.decimals)
+		}
+		if got := RoundIn(tt.in, tt.currency); got != tt.want {
+			t.Errorf("RoundIn(%d, %q) = %d, want %d", tt.in, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestRound(t *testing.T) {
+	tests := []struct {
+		in, want float64
+	}{
+		{1.234, 1.23},
+		{1.236, 1.24},
+		{-1.236, -1.24},
+		{0, 0},
+		{12, 12},
+	}
+	for _, tt := range tests {
+		if got := Round(tt.in); got != tt.want {
+			t.Errorf("Round(%v) = %v, want %v", tt.in, got, tt.want)
+		}
+	}
+}
+
+func TestTaxRate(t *testing.T) {
+	tests := []struct {
+		category string
+		want     float64
+	}{
+		{"食品", 0.09},
+		{"电子产品", 0.13},
+		{"未知分类", defaultTaxRate},
+	}
+	for _, tt := range tests {
+		if got := TaxRate(tt.category); got != tt.want {
+			t.Errorf("TaxRate(%q) = %v, want %v", tt.category, got, tt.want)
+		}
+	}
+}
+
+func TestShipping(t *testing.T) {
+	tests := []struct {
+		discounted money.Amount
+		quantity   int
+		currency   string
+		want       money.Amount
+	}{
+		{9900, 1, "CNY", 0}, // 恰好达到包邮门槛
+		{9899, 1, "CNY", 800},
+		{9899, 3, "CNY", 1200},
+		{0, 0, "CNY", 0},
+		{1386, 2, "USD", 0}, // 门槛 99 元 × 0.14
+		{1385, 2, "USD", 140},
+		{207900, 1, "JPY", 0},
+		{207800, 1, "JPY", 16800},
+	}
+	for _, tt := range tests {
+		if got := Shipping(tt.discounted, tt.quantity, tt.currency); got != tt.want {
+			t.Errorf("Shipping(%d, %d, %s) = %d, want %d", tt.discounted, tt.quantity, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestMaxDiscount(t *testing.T) {
+	tests := []struct {
+		subtotal money.Amount
+		rate     float64
+		currency string
+		want     money.Amount
+	}{
+		{10000, 0.15, "CNY", 1500},
+		{2997, 0.1, "CNY", 300},    // 299.7 分 → 300
+		{12345, 0.15, "JPY", 1900}, // 18.5175 日元 → 19
+		{12345, 0, "CNY", 0},
+	}
+	for _, tt := range tests {
+		if got := MaxDiscount(tt.subtotal, tt.rate, tt.currency); got != tt.want {
+			t.Errorf("MaxDiscount(%d, %v, %s) = %d, want %d", tt.subtotal, tt.rate, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestComputeLine(t *testing.T) {
+	tests := []struct {
+		unitPrice money.Amount
+		quantity  int
+		rate      float64
+		category  string
+		currency  string
+		want      Line
+	}{
+		{999, 3, 0.1, "食品", "CNY", Line{UnitPrice: 999, Subtotal: 2997, Discount: 300, Tax: 243, Total: 2940}},
+		{5000, 1, 0, "电子产品", "CNY", Line{UnitPrice: 5000, Subtotal: 5000, Tax: 650, Total: 5650}},
+		{1000, 2, 0.05, "服装", "JPY", Line{UnitPrice: 21000, Subtotal: 42000, Discount: 2100, Tax: 5200, Total: 45100}},
+		{999, 1, 0, "家居用品", "EUR", Line{UnitPrice: 130, Subtotal: 130, Tax: 17, Total: 147}},
+	}
+	for _, tt := range tests {
+		if got := ComputeLine(tt.unitPrice, tt.quantity, tt.rate, tt.category, tt.currency); got != tt.want {
+			t.Errorf("ComputeLine(%d, %d, %v, %s, %s) = %+v, want %+v", tt.unitPrice, tt.quantity, tt.rate, tt.category, tt.currency, got, tt.want)
+		}
+	}
+}
+
+func TestSum(t *testing.T) {
+	lines := []Line{
+		ComputeLine(999, 3, 0.1, "食品", "CNY"),
+		ComputeLine(5000, 1, 0, "电子产品", "CNY"),
+	}
+	want := Amounts{Subtotal: 7997, Discount: 300, Tax: 893, Shipping: 1400, Total: 9990}
+	if got := Sum(lines, 4, "CNY"); got != want {
+		t.Errorf("Sum = %+v, want %+v", got, want)
+	}
+	if got := Sum(nil, 0, "CNY"); got != (Amounts{}) {
+		t.Errorf("Sum(nil) = %+v, want zero", got)
+	}
+}
+
+// TestLineInvariants 遍历价格、数量、折扣率与币种，检查订单行与整单金额公式在定点取整下始终成立
+func TestLineInvariants(t *testing.T) {
+	for _, currency := range []string{"CNY", "USD", "GBP", "EUR", "JPY"} {
+		unit := money.Amount(1)
+		if Decimals(currency) == 0 {
+			unit = money.Scale
+		}
+		for price := money.Amount(1); price < 50000; price += 37 {
+			for quantity := 1; quantity <= 5; quantity++ {
+				var lines []Line
+				for _, rate := range []float64{0, 0.05, 0.15, 0.333} {
+					l := ComputeLine(price, quantity, rate, "食品", currency)
+					if l.Total != l.Subtotal-l.Discount+l.Tax {
+						t.Fatalf("%s %d×%d 折扣率 %v: 行金额 %+v 不满足公式", currency, price, quantity, rate, l)
+					}
+					if l.Discount < 0 || l.Discount > l.Subtotal || l.Tax < 0 {
+						t.Fatalf("%s %d×%d 折扣率 %v: 行金额 %+v 越界", currency, price, quantity, rate, l)
+					}
+					for _, v := range []money.Amount{l.UnitPrice, l.Subtotal, l.Discount, l.Tax, l.Total} {
+						if v%unit != 0 {
+							t.Fatalf("%s %d×%d 折扣率 %v: 金额 %d 未按币种最小单位取整", currency, price, quantity, rate, v)
+						}
+					}
+					lines = append(lines, l)
+				}
+				a := Sum(lines, quantity*len(lines), currency)
+				if a.Total != a.Subtotal-a.Discount+a.Tax+a.Shipping {
+					t.Fatalf("%s %d×%d: 整单金额 %+v 不满足公式", currency, price, quantity, a)
+				}
+			}
+		}
+	}
+}
@@ -0,0 +1,137 @@
+package schemaspec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+)
+
+// 表结构变更的类型
+const (
+	AddColumn    = "ADD COLUMN"
+	ModifyColumn = "MODIFY COLUMN"
+	RenameColumn = "RENAME COLUMN"
+	DropColumn   = "DROP COLUMN"
+	AddIndex     = "ADD INDEX"
+)
+
+// Change 表结构变更计划中的一条 DDL。计划文件每行一条，以定时任务启动后的时长开头：
+//
+//	5m   ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(32) NULL;   -- gen: regex(CPN[0-9]{8})
+//	10m  ALTER TABLE orders MODIFY COLUMN coupon_code VARCHAR(64) NULL;
+//	15m  ALTER TABLE users RENAME COLUMN hobby TO hobbies;
+//	20m  ALTER TABLE products ADD INDEX idx_weight (weight);
+//	30m  ALTER TABLE orders DROP COLUMN coupon_code;
+//
+// 每条 ALTER TABLE 只能包含一个操作；ADD/MODIFY COLUMN 行末的 gen 注释为新列的生成器
+type Change struct {
+	After   time.Duration // 定时任务启动后多久执行
+	Table   string
+	Kind    string  // 变更类型，如 AddColumn
+	Column  string  // 涉及的列，ADD INDEX 为空
+	NewName string  // RENAME COLUMN 的新列名
+	Def     *Column // ADD/MODIFY COLUMN 的新列定义
+	SQL     string  // 原样执行的 DDL 语句（不含分号）
+	Line    int     // 在计划文件中的行号
+}
+
+var (
+	alterRe    = regexp.MustCompile(`(?is)^ALTER\s+TABLE\s+` + "`?" + `(\w+)` + "`?" + `\s+(.+)$`)
+	addIndexRe = regexp.MustCompile(`(?i)^ADD\s+(?:UNIQUE\s+|FULLTEXT\s+)?(?:INDEX|KEY)\b`)
+	addOtherRe = regexp.MustCompile(`(?i)^ADD\s+(?:PRIMARY|CONSTRAINT|FOREIGN|CHECK|PARTITION)\b`)
+	addRe      = regexp.MustCompile(`(?i)^ADD\s+(?:COLUMN\s+)?(.+)$`)
+	modifyRe   = regexp.MustCompile(`(?i)^MODIFY\s+(?:COLUMN\s+)?(.+)$`)
+	renameRe   = regexp.MustCompile(`(?i)^RENAME\s+COLUMN\s+` + "`?" + `(\w+)` + "`?" + `\s+TO\s+` + "`?" + `(\w+)` + "`?" + `$`)
+	dropRe     = regexp.MustCompile(`(?i)^DROP\s+(?:COLUMN\s+)?` + "`?" + `(\w+)` + "`?" + `$`)
+)
+
+// ParseChangesFile 解析表结构变更计划文件
+func ParseChangesFile(path string, fixed map[string][]string) ([]*Change, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return ParseChanges(f, fixed)
+}
+
+// ParseChanges 解析表结构变更计划，结果按执行时间排序（同一时间的按行号先后）。
+// fixed 为各表中写入代码直接在查询条件里引用的列（表 -> 列），这些列不能改名或删除
+func ParseChanges(r io.Reader, fixed map[string][]string) ([]*Change, error) {
+	var changes []*Change
+	scanner := bufio.NewScanner(r)
+	lineNo := 0
+	for scanner.Scan() {
+		lineNo++
+		line, comment := splitComment(scanner.Text())
+		line = strings.TrimSpace(line)
+		if line == "" {
+			continue
+		}
+		offset, stmt, _ := strings.Cut(line, " ")
+		after, err := time.ParseDuration(offset)
+		if err != nil || after < 0 {
+			return nil, fmt.Errorf("第 %d 行: 时长 %q 无效", lineNo, offset)
+		}
+		c, err := parseChange(strings.TrimSuffix(strings.TrimSpace(stmt), ";"))
+		if err != nil {
+			return nil, fmt.Errorf("第 %d 行: %w", lineNo, err)
+		}
+		c.After, c.Line = after, lineNo
+		if (c.Kind == RenameColumn || c.Kind == DropColumn) && slices.Contains(fixed[c.Table], c.Column) {
+			return nil, fmt.Errorf("第 %d 行: 列 %s.%s 被写入代码用于查询条件，不能改名或删除", lineNo, c.Table, c.Column)
+		}
+		if g, ok := strings.CutPrefix(strings.TrimSpace(comment), "gen:"); ok {
+			if c.Def == nil {
+				return nil, fmt.Errorf("第 %d 行: 只有 ADD/MODIFY COLUMN 可以指定 gen", lineNo)
+			}
+			c.Def.Gen = strings.TrimSpace(g)
+		}
+		changes = append(changes, c)
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	sort.SliceStable(changes, func(i, j int) bool { return changes[i].After < changes[j].After })
+	return changes, nil
+}
+
+// parseChange 解析一条 ALTER TABLE 语句
+func parseChange(stmt string) (*Change, error) {
+	m := alterRe.FindStringSubmatch(stmt)
+	if m == nil {
+		return nil, fmt.Errorf("%q 不是 ALTER TABLE 语句", stmt)
+	}
+	c := &Change{Table: m[1], SQL: stmt}
+	op := strings.Join(strings.Fields(m[2]), " ")
+	switch {
+	case addIndexRe.MatchString(op):
+		c.Kind = AddIndex
+	case addOtherRe.MatchString(op):
+		return nil, fmt.Errorf("不支持的变更 %q，只支持 ADD/MODIFY/RENAME/DROP COLUMN 与 ADD INDEX", op)
+	case addRe.MatchString(op), modifyRe.MatchString(op):
+		c.Kind, m = AddColumn, addRe.FindStringSubmatch(op)
+		if m == nil {
+			c.Kind, m = ModifyColumn, modifyRe.FindStringSubmatch(op)
+		}
+		def, err := parseColumn(m[1])
+		if err != nil {
+			return nil, err
+		}
+		c.Def, c.Column = def, def.Name
+	case renameRe.MatchString(op):
+		m = renameRe.FindStringSubmatch(op)
+		c.Kind, c.Column, c.NewName = RenameColumn, m[1], m[2]
+	case dropRe.MatchString(op):
+		c.Kind, c.Column = DropColumn, dropRe.FindStringSubmatch(op)[1]
+	default:
+		return nil, fmt.Errorf("不支持的变更 %q，只支持 ADD/MODIFY/RENAME/DROP COLUMN 与 ADD INDEX", op)
+	}
+	return c, nil
+}
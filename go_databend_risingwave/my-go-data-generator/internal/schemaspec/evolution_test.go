@@ -0,0 +1,93 @@
+package schemaspec
+
+import (
+	"strings"
+	"testing"
+	"time"
+)
+
+func TestParseChanges(t *testing.T) {
+	plan := `
+10m  ALTER TABLE orders MODIFY COLUMN coupon_code VARCHAR(64) NULL;
+5m   ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(32) NULL;   -- gen: regex(CPN[0-9]{8})
+15m  ALTER TABLE ` + "`users`" + ` RENAME COLUMN ` + "`hobby`" + ` TO hobbies;
+20m  alter table products add unique index idx_weight (weight);
+5m   ALTER TABLE orders ADD note TEXT;
+30m  ALTER TABLE orders DROP COLUMN coupon_code;
+1h   ALTER TABLE orders DROP note
+`
+	changes, err := ParseChanges(strings.NewReader(plan), map[string][]string{"orders": {"id", "status"}})
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []struct {
+		after   time.Duration
+		line    int
+		table   string
+		kind    string
+		column  string
+		newName string
+		gen     string
+	}{
+		{5 * time.Minute, 3, "orders", AddColumn, "coupon_code", "", "regex(CPN[0-9]{8})"},
+		{5 * time.Minute, 6, "orders", AddColumn, "note", "", ""},
+		{10 * time.Minute, 2, "orders", ModifyColumn, "coupon_code", "", ""},
+		{15 * time.Minute, 4, "users", RenameColumn, "hobby", "hobbies", ""},
+		{20 * time.Minute, 5, "products", AddIndex, "", "", ""},
+		{30 * time.Minute, 7, "orders", DropColumn, "coupon_code", "", ""},
+		{time.Hour, 8, "orders", DropColumn, "note", "", ""},
+	}
+	if len(changes) != len(want) {
+		t.Fatalf("解析出 %d 条变更，want %d", len(changes), len(want))
+	}
+	for i, w := range want {
+		c := changes[i]
+		gen := ""
+		if c.Def != nil {
+			gen = c.Def.Gen
+		}
+		if c.After != w.after || c.Line != w.line || c.Table != w.table || c.Kind != w.kind || c.Column != w.column || c.NewName != w.newName || gen != w.gen {
+			t.Errorf("变更 %d = %+v (gen %q), want %+v", i, *c, gen, w)
+		}
+		if strings.HasSuffix(c.SQL, ";") || !strings.HasPrefix(strings.ToUpper(c.SQL), "ALTER TABLE") {
+			t.Errorf("变更 %d 的 SQL = %q，应为不含分号的 ALTER TABLE", i, c.SQL)
+		}
+	}
+	if d := changes[0].Def; d == nil || d.Type != "VARCHAR" || len(d.Args) != 1 || d.Args[0] != 32 || d.NotNull {
+		t.Errorf("ADD COLUMN 的列定义 = %+v", d)
+	}
+}
+
+func TestParseChangesErrors(t *testing.T) {
+	fixed := map[string][]string{"orders": {"id", "status"}, "users": {"id"}}
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{"时长无效", "soon ALTER TABLE orders DROP COLUMN note;", "时长 \"soon\" 无效"},
+		{"时长为负", "-5m ALTER TABLE orders DROP COLUMN note;", "无效"},
+		{"非 ALTER", "5m DROP TABLE orders;", "不是 ALTER TABLE"},
+		{"不支持的 ADD", "5m ALTER TABLE orders ADD PRIMARY KEY (id);", "不支持的变更"},
+		{"不支持的操作", "5m ALTER TABLE orders ENGINE=InnoDB;", "不支持的变更"},
+		{"缺少类型", "5m ALTER TABLE orders ADD COLUMN note;", "缺少类型"},
+		{"gen 用于非列定义", "5m ALTER TABLE orders DROP COLUMN note; -- gen: enum(x)", "只有 ADD/MODIFY COLUMN 可以指定 gen"},
+		{"改名查询列", "5m ALTER TABLE orders RENAME COLUMN status TO state;", "列 orders.status 被写入代码用于查询条件"},
+		{"删除查询列", "5m ALTER TABLE users DROP COLUMN `id`;", "列 users.id 被写入代码用于查询条件"},
+		{"错误行号", "\n5m ALTER TABLE orders DROP note;\n7m ALTER TABLE orders DROP COLUMN status;", "第 3 行"},
+	}
+	for _, tt := range tests {
+		_, err := ParseChanges(strings.NewReader(tt.in), fixed)
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want 包含 %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestParseChangesFixedOtherTable(t *testing.T) {
+	// 固定列只约束所属的表，其他表的同名列可以改名或删除；MODIFY 固定列不受限制
+	plan := "5m ALTER TABLE coupons DROP COLUMN status;\n6m ALTER TABLE orders MODIFY COLUMN status VARCHAR(32) NOT NULL;"
+	if _, err := ParseChanges(strings.NewReader(plan), map[string][]string{"orders": {"status"}}); err != nil {
+		t.Errorf("err = %v, want nil", err)
+	}
+}
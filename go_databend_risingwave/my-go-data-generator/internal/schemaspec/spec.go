@@ -0,0 +1,363 @@
+// Package schemaspec 解析带注释的 SQL DDL 表结构说明，用于在不编写 Go 模型的情况下生成任意表的数据
+//
+// 说明文件由若干 CREATE TABLE 语句组成，语句本身就是建表 DDL；生成方式写在注释中：
+//
+//	-- @rows 100000
+//	-- @stream 2
+//	CREATE TABLE coupons (
+//	  id BIGINT UNSIGNED PRIMARY KEY AUTO_INCREMENT,
+//	  code VARCHAR(32) NOT NULL UNIQUE,             -- gen: seq(CPN%08d)
+//	  user_id BIGINT UNSIGNED NOT NULL REFERENCES users(id),
+//	  amount DECIMAL(10,2) NOT NULL,                -- gen: floatrange(5,200,2)
+//	  expires_at DATETIME                           -- gen: daterange(0,90d)
+//	);
+//
+// 表前的 @rows 为批量生成的行数，@stream 为定时任务每次追加的行数；列后的 gen 为 fieldgen 生成器，
+// 省略时按列类型、约束与 REFERENCES 推导
+package schemaspec
+
+import (
+	"bufio"
+	"fmt"
+	"io"
+	"os"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// Spec 一份表结构说明，Tables 已按引用关系排序：被引用的表在前
+type Spec struct {
+	Tables []*Table
+}
+
+// Table 一张表
+type Table struct {
+	Name       string
+	DDL        string // 去掉注释后的 CREATE TABLE 语句
+	Rows       int    // 批量生成的行数
+	StreamRows int    // 定时任务每次追加的行数
+	Columns    []*Column
+}
+
+// Column 一列
+type Column struct {
+	Name          string
+	Type          string   // 大写的类型名，如 VARCHAR、DECIMAL
+	Args          []int    // 类型参数，如 VARCHAR(32) 为 [32]，DECIMAL(10,2) 为 [10 2]
+	Values        []string // ENUM、SET 的取值
+	Unsigned      bool
+	NotNull       bool
+	PrimaryKey    bool
+	AutoIncrement bool
+	Unique        bool
+	HasDefault    bool
+	References    string // 引用的表名
+	Gen           string // gen 注释中的生成器描述
+}
+
+// PrimaryKey 返回表的主键列，没有单列主键时返回 nil
+func (t *Table) PrimaryKey() *Column {
+	for _, c := range t.Columns {
+		if c.PrimaryKey {
+			return c
+		}
+	}
+	return nil
+}
+
+// Column 按列名查找列
+func (t *Table) Column(name string) *Column {
+	for _, c := range t.Columns {
+		if strings.EqualFold(c.Name, name) {
+			return c
+		}
+	}
+	return nil
+}
+
+var (
+	createRe     = regexp.MustCompile(`(?is)^CREATE\s+TABLE\s+(?:IF\s+NOT\s+EXISTS\s+)?` + "`?" + `([\w.]+)` + "`?" + `\s*\((.*)\)([^()]*)$`)
+	typeRe       = regexp.MustCompile(`^(\w+)\s*(?:\(([\d\s,]+)\))?`)
+	referencesRe = regexp.MustCompile(`(?i)\bREFERENCES\s+` + "`?" + `(\w+)` + "`?")
+	valuesRe     = regexp.MustCompile(`'((?:[^']|'')*)'`)
+	defaultRe    = regexp.MustCompile(`(?i)\bDEFAULT\b`)
+	constraintRe = regexp.MustCompile(`(?i)^(?:CONSTRAINT\s+\w+\s+)?(PRIMARY\s+KEY|UNIQUE(?:\s+(?:KEY|INDEX))?|FOREIGN\s+KEY|(?:FULLTEXT\s+)?(?:KEY|INDEX)|CHECK)(?:\s+` + "`?" + `\w+` + "`?" + `)?\s*\(([^)]*)\)(.*)$`)
+)
+
+// ParseFile 解析说明文件
+func ParseFile(path string) (*Spec, error) {
+	f, err := os.Open(path)
+	if err != nil {
+		return nil, err
+	}
+	defer f.Close()
+	return Parse(f)
+}
+
+// Parse 解析说明：逐行读取，分离代码与注释，按分号切分语句，只处理 CREATE TABLE 语句
+func Parse(r io.Reader) (*Spec, error) {
+	var (
+		tables  []*Table
+		code    strings.Builder
+		gens    []lineGen // 当前语句中各 gen 注释及其所在的代码位置
+		rows    = -1
+		stream  = 0
+		scanner = bufio.NewScanner(r)
+		lineNo  = 0
+	)
+	for scanner.Scan() {
+		lineNo++
+		line, comment := splitComment(scanner.Text())
+		if a, ok := strings.CutPrefix(strings.TrimSpace(comment), "@"); ok {
+			key, value, _ := strings.Cut(a, " ")
+			n, err := strconv.Atoi(strings.TrimSpace(value))
+			if err != nil || n < 0 {
+				return nil, fmt.Errorf("第 %d 行: @%s 需要非负整数", lineNo, key)
+			}
+			switch key {
+			case "rows":
+				rows = n
+			case "stream":
+				stream = n
+			default:
+				return nil, fmt.Errorf("第 %d 行: 未知的注解 @%s", lineNo, key)
+			}
+			continue
+		}
+		code.WriteString(line)
+		code.WriteByte('\n')
+		if g, ok := strings.CutPrefix(strings.TrimSpace(comment), "gen:"); ok {
+			gens = append(gens, lineGen{offset: lastCodeOffset(code.String()), gen: strings.TrimSpace(g)})
+		}
+		if !strings.HasSuffix(strings.TrimSpace(line), ";") {
+			continue
+		}
+		// 语句前的空行不计入位置，gen 注释的位置随之平移
+		raw := code.String()
+		lead := len(raw) - len(strings.TrimLeft(raw, " \t\n"))
+		stmt := strings.TrimSuffix(strings.TrimSpace(raw), ";")
+		for i := range gens {
+			gens[i].offset -= lead
+		}
+		if m := createRe.FindStringSubmatchIndex(stmt); m != nil {
+			name := stmt[m[2]:m[3]]
+			t, err := parseTable(name, stmt, stmt[m[4]:m[5]], gens, m[4])
+			if err != nil {
+				return nil, fmt.Errorf("表 %s: %w", name, err)
+			}
+			t.Rows, t.StreamRows = rows, stream
+			if t.Rows < 0 {
+				t.Rows = 1000
+			}
+			tables = append(tables, t)
+		} else if strings.TrimSpace(stmt) != "" {
+			return nil, fmt.Errorf("第 %d 行之前的语句不是 CREATE TABLE", lineNo)
+		}
+		code.Reset()
+		gens, rows, stream = nil, -1, 0
+	}
+	if err := scanner.Err(); err != nil {
+		return nil, err
+	}
+	if strings.TrimSpace(code.String()) != "" {
+		return nil, fmt.Errorf("最后一条语句缺少分号")
+	}
+	sorted, err := sortTables(tables)
+	if err != nil {
+		return nil, err
+	}
+	return &Spec{Tables: sorted}, nil
+}
+
+// lineGen 一条 gen 注释，offset 为注释所在行最后一个代码字符在语句中的位置
+type lineGen struct {
+	offset int
+	gen    string
+}
+
+// splitComment 分离一行中的代码与 -- 注释（引号内的 -- 不算注释）
+func splitComment(line string) (string, string) {
+	var quote rune
+	for i, r := range line {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"' || r == '`':
+			quote = r
+		case strings.HasPrefix(line[i:], "--"):
+			return line[:i], line[i+2:]
+		}
+	}
+	return line, ""
+}
+
+// lastCodeOffset 返回语句中最后一个非空白字符的位置
+func lastCodeOffset(s string) int {
+	return len(strings.TrimRight(s, " \t\n")) - 1
+}
+
+// parseTable 解析 CREATE TABLE 的列定义，bodyStart 为列定义部分在语句中的起始位置，用于把 gen 注释对应到列
+func parseTable(name, stmt, body string, gens []lineGen, bodyStart int) (*Table, error) {
+	t := &Table{Name: name, DDL: stmt}
+	type def struct {
+		text       string
+		start, end int // 在语句中的位置
+	}
+	var defs []def
+	depth, start := 0, 0
+	var quote rune
+	for i, r := range body {
+		switch {
+		case quote != 0:
+			if r == quote {
+				quote = 0
+			}
+		case r == '\'' || r == '"':
+			quote = r
+		case r == '(':
+			depth++
+		case r == ')':
+			depth--
+		case r == ',' && depth == 0:
+			defs = append(defs, def{body[start:i], bodyStart + start, bodyStart + i})
+			start = i + 1
+		}
+	}
+	defs = append(defs, def{body[start:], bodyStart + start, bodyStart + len(body)})
+
+	var constraints []string
+	for _, d := range defs {
+		text := strings.Join(strings.Fields(d.text), " ")
+		if text == "" {
+			continue
+		}
+		if constraintRe.MatchString(text) {
+			constraints = append(constraints, text)
+			continue
+		}
+		c, err := parseColumn(text)
+		if err != nil {
+			return nil, err
+		}
+		// gen 注释写在列定义所在行的末尾：注释行的最后一个代码字符落在该列定义内或紧随其后的逗号上
+		for _, g := range gens {
+			if g.offset >= d.start && g.offset <= d.end {
+				c.Gen = g.gen
+			}
+		}
+		t.Columns = append(t.Columns, c)
+	}
+	for _, text := range constraints {
+		m := constraintRe.FindStringSubmatch(text)
+		kind := strings.ToUpper(strings.Fields(m[1])[0])
+		columns := strings.Split(m[2], ",")
+		first := t.Column(strings.Trim(strings.TrimSpace(columns[0]), "`"))
+		if first == nil {
+			continue
+		}
+		switch kind {
+		case "PRIMARY":
+			if len(columns) == 1 {
+				first.PrimaryKey, first.NotNull = true, true
+			}
+		case "UNIQUE":
+			if len(columns) == 1 {
+				first.Unique = true
+			}
+		case "FOREIGN":
+			if ref := referencesRe.FindStringSubmatch(m[3]); ref != nil {
+				first.References = ref[1]
+			}
+		}
+	}
+	if len(t.Columns) == 0 {
+		return nil, fmt.Errorf("没有列定义")
+	}
+	return t, nil
+}
+
+// parseColumn 解析一列的定义：列名、类型与约束
+func parseColumn(text string) (*Column, error) {
+	name, rest, ok := strings.Cut(text, " ")
+	if !ok {
+		return nil, fmt.Errorf("列定义 %q 缺少类型", text)
+	}
+	c := &Column{Name: strings.Trim(name, "`\"")}
+	m := typeRe.FindStringSubmatch(rest)
+	if m == nil {
+		return nil, fmt.Errorf("列 %s 的类型无法识别", c.Name)
+	}
+	c.Type = strings.ToUpper(m[1])
+	if m[2] != "" {
+		for _, a := range strings.Split(m[2], ",") {
+			n, err := strconv.Atoi(strings.TrimSpace(a))
+			if err != nil {
+				return nil, fmt.Errorf("列 %s 的类型参数 %q 无效", c.Name, a)
+			}
+			c.Args = append(c.Args, n)
+		}
+	}
+	if c.Type == "ENUM" || c.Type == "SET" {
+		open, close := strings.Index(rest, "("), strings.Index(rest, ")")
+		if open < 0 || close < open {
+			return nil, fmt.Errorf("列 %s 缺少 %s 取值", c.Name, c.Type)
+		}
+		for _, v := range valuesRe.FindAllStringSubmatch(rest[open:close], -1) {
+			c.Values = append(c.Values, strings.ReplaceAll(v[1], "''", "'"))
+		}
+		rest = rest[close+1:]
+	}
+	upper := strings.ToUpper(rest)
+	c.Unsigned = strings.Contains(upper, "UNSIGNED")
+	c.PrimaryKey = strings.Contains(upper, "PRIMARY KEY")
+	c.NotNull = c.PrimaryKey || strings.Contains(upper, "NOT NULL")
+	c.AutoIncrement = strings.Contains(upper, "AUTO_INCREMENT") || strings.Contains(upper, "AUTOINCREMENT")
+	c.Unique = strings.Contains(upper, "UNIQUE")
+	c.HasDefault = defaultRe.MatchString(rest)
+	if ref := referencesRe.FindStringSubmatch(rest); ref != nil {
+		c.References = ref[1]
+	}
+	return c, nil
+}
+
+// sortTables 按引用关系排序，被引用的表排在引用它的表之前；说明之外的表（如 users）不参与排序
+func sortTables(tables []*Table) ([]*Table, error) {
+	byName := make(map[string]*Table, len(tables))
+	for _, t := range tables {
+		if _, dup := byName[t.Name]; dup {
+			return nil, fmt.Errorf("表 %s 重复定义", t.Name)
+		}
+		byName[t.Name] = t
+	}
+	var sorted []*Table
+	state := make(map[string]int) // 0 未访问，1 访问中，2 已完成
+	var visit func(t *Table) error
+	visit = func(t *Table) error {
+		switch state[t.Name] {
+		case 1:
+			return fmt.Errorf("表 %s 存在循环引用", t.Name)
+		case 2:
+			return nil
+		}
+		state[t.Name] = 1
+		for _, c := range t.Columns {
+			if dep, ok := byName[c.References]; ok && dep != t {
+				if err := visit(dep); err != nil {
+					return err
+				}
+			}
+		}
+		state[t.Name] = 2
+		sorted = append(sorted, t)
+		return nil
+	}
+	for _, t := range tables {
+		if err := visit(t); err != nil {
+			return nil, err
+		}
+	}
+	return sorted, nil
+}
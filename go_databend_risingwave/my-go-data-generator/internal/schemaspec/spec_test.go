@@ -0,0 +1,140 @@
+package schemaspec
+
+import (
+	"reflect"
+	"strings"
+	"testing"
+)
+
+const testSpec = `
+-- @rows 5
+CREATE TABLE redemptions (
+  id BIGINT PRIMARY KEY,
+  coupon_id BIGINT NOT NULL,                  -- gen: ref(coupons)
+  note VARCHAR(20) DEFAULT 'a--b',
+  FOREIGN KEY (coupon_id) REFERENCES coupons(id)
+);
+
+-- @rows 100
+-- @stream 2
+CREATE TABLE IF NOT EXISTS ` + "`coupons`" + ` (
+  id BIGINT UNSIGNED AUTO_INCREMENT,
+  code VARCHAR(32) NOT NULL,                  -- gen: seq(CPN%08d)
+  kind ENUM('a','it''s') NOT NULL,
+  amount DECIMAL(10, 2) NOT NULL,             -- gen: floatrange(5,200,2)
+  user_id BIGINT UNSIGNED REFERENCES users(id),
+  expires_at DATETIME,                        -- gen: daterange(0,90d)
+  PRIMARY KEY (id),
+  UNIQUE KEY uk_code (code)
+) ENGINE=InnoDB;
+`
+
+func TestParse(t *testing.T) {
+	spec, err := Parse(strings.NewReader(testSpec))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := []Table{
+		{Name: "coupons", Rows: 100, StreamRows: 2, Columns: []*Column{
+			{Name: "id", Type: "BIGINT", Unsigned: true, NotNull: true, PrimaryKey: true, AutoIncrement: true},
+			{Name: "code", Type: "VARCHAR", Args: []int{32}, NotNull: true, Unique: true, Gen: "seq(CPN%08d)"},
+			{Name: "kind", Type: "ENUM", Values: []string{"a", "it's"}, NotNull: true},
+			{Name: "amount", Type: "DECIMAL", Args: []int{10, 2}, NotNull: true, Gen: "floatrange(5,200,2)"},
+			{Name: "user_id", Type: "BIGINT", Unsigned: true, References: "users"},
+			{Name: "expires_at", Type: "DATETIME", Gen: "daterange(0,90d)"},
+		}},
+		{Name: "redemptions", Rows: 5, Columns: []*Column{
+			{Name: "id", Type: "BIGINT", NotNull: true, PrimaryKey: true},
+			{Name: "coupon_id", Type: "BIGINT", NotNull: true, References: "coupons", Gen: "ref(coupons)"},
+			{Name: "note", Type: "VARCHAR", Args: []int{20}, HasDefault: true},
+		}},
+	}
+	if len(spec.Tables) != len(want) {
+		t.Fatalf("解析出 %d 张表，want %d", len(spec.Tables), len(want))
+	}
+	for i, w := range want {
+		got := spec.Tables[i]
+		if got.Name != w.Name || got.Rows != w.Rows || got.StreamRows != w.StreamRows {
+			t.Errorf("表 %d = %s rows=%d stream=%d, want %s rows=%d stream=%d", i, got.Name, got.Rows, got.StreamRows, w.Name, w.Rows, w.StreamRows)
+		}
+		if len(got.Columns) != len(w.Columns) {
+			t.Errorf("表 %s 有 %d 列，want %d", got.Name, len(got.Columns), len(w.Columns))
+			continue
+		}
+		for j, c := range w.Columns {
+			if !reflect.DeepEqual(got.Columns[j], c) {
+				t.Errorf("表 %s 列 %d = %+v, want %+v", got.Name, j, *got.Columns[j], *c)
+			}
+		}
+		if !strings.HasPrefix(got.DDL, "CREATE TABLE") || strings.HasSuffix(got.DDL, ";") || strings.Contains(got.DDL, "gen:") {
+			t.Errorf("表 %s 的 DDL 应为去掉注释与分号的建表语句: %q", got.Name, got.DDL)
+		}
+	}
+	if pk := spec.Tables[0].PrimaryKey(); pk == nil || pk.Name != "id" {
+		t.Errorf("coupons 主键 = %v, want id", pk)
+	}
+	if c := spec.Tables[0].Column("CODE"); c == nil || c.Name != "code" {
+		t.Errorf("Column(CODE) = %v, want code", c)
+	}
+}
+
+func TestParseDefaults(t *testing.T) {
+	spec, err := Parse(strings.NewReader("CREATE TABLE t (\n  id INT PRIMARY KEY,\n  parent_id INT REFERENCES t(id),\n  name TEXT  -- gen: enum(x)\n);\n"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	tbl := spec.Tables[0]
+	if tbl.Rows != 1000 || tbl.StreamRows != 0 {
+		t.Errorf("默认 rows=%d stream=%d, want 1000 0", tbl.Rows, tbl.StreamRows)
+	}
+	if c := tbl.Column("name"); c == nil || c.Gen != "enum(x)" {
+		t.Errorf("最后一列的 gen = %v, want enum(x)", c)
+	}
+	if c := tbl.Column("parent_id"); c == nil || c.References != "t" {
+		t.Errorf("自引用列 = %v, want REFERENCES t", c)
+	}
+}
+
+func TestParseErrors(t *testing.T) {
+	tests := []struct {
+		name    string
+		in      string
+		wantErr string
+	}{
+		{"rows 非整数", "-- @rows x\nCREATE TABLE t (id INT);", "@rows 需要非负整数"},
+		{"rows 为负数", "-- @rows -1\nCREATE TABLE t (id INT);", "@rows 需要非负整数"},
+		{"未知注解", "-- @limit 1\nCREATE TABLE t (id INT);", "未知的注解 @limit"},
+		{"缺少分号", "CREATE TABLE t (id INT)", "缺少分号"},
+		{"非建表语句", "INSERT INTO t VALUES (1);", "不是 CREATE TABLE"},
+		{"缺少类型", "CREATE TABLE t (id);", "缺少类型"},
+		{"类型无法识别", "CREATE TABLE t (id (x));", "类型无法识别"},
+		{"没有列", "CREATE TABLE t (PRIMARY KEY (id));", "没有列定义"},
+		{"ENUM 缺少取值", "CREATE TABLE t (kind ENUM NOT NULL);", "缺少 ENUM 取值"},
+		{"重复定义", "CREATE TABLE t (id INT);\nCREATE TABLE t (id INT);", "重复定义"},
+		{"循环引用", "CREATE TABLE a (b_id INT REFERENCES b(id));\nCREATE TABLE b (a_id INT REFERENCES a(id));", "循环引用"},
+	}
+	for _, tt := range tests {
+		_, err := Parse(strings.NewReader(tt.in))
+		if err == nil || !strings.Contains(err.Error(), tt.wantErr) {
+			t.Errorf("%s: err = %v, want 包含 %q", tt.name, err, tt.wantErr)
+		}
+	}
+}
+
+func TestSplitComment(t *testing.T) {
+	tests := []struct {
+		in, code, comment string
+	}{
+		{"id INT, -- gen: seq(%d)", "id INT, ", " gen: seq(%d)"},
+		{"note VARCHAR(8) DEFAULT 'a--b'", "note VARCHAR(8) DEFAULT 'a--b'", ""},
+		{"`a--b` INT -- x", "`a--b` INT ", " x"},
+		{"-- @rows 10", "", " @rows 10"},
+		{"", "", ""},
+	}
+	for _, tt := range tests {
+		code, comment := splitComment(tt.in)
+		if code != tt.code || comment != tt.comment {
+			t.Errorf("splitComment(%q) = %q, %q, want %q, %q", tt.in, code, comment, tt.code, tt.comment)
+		}
+	}
+}
@@ -0,0 +1,134 @@
+package text
+
+import (
+	_ "embed"
+	"math"
+	"math/rand"
+	"strings"
+	"unicode/utf8"
+)
+
+// Lang 文本语言
+type Lang int
+
+const (
+	Chinese Lang = iota
+	English
+)
+
+var (
+	//go:embed corpus/zh.txt
+	zhCorpus string
+	//go:embed corpus/en.txt
+	enCorpus string
+)
+
+// chain 马尔可夫链：key 为前缀，value 为可能的后继 token
+type chain struct {
+	order  int
+	next   map[string][]string
+	starts [][]string // 句首前缀
+	join   string     // token 之间的连接符
+}
+
+const (
+	zhOrder = 2 // 中文按字建模，二阶
+	enOrder = 1 // 英文按词建模，一阶
+)
+
+var chains = map[Lang]*chain{
+	Chinese: build(zhCorpus, zhOrder, func(line string) []string { return strings.Split(line, "") }, ""),
+	English: build(enCorpus, enOrder, strings.Fields, " "),
+}
+
+// build 以语料中的每一行作为一个句子训练马尔可夫链
+func build(corpus string, order int, tokenize func(string) []string, join string) *chain {
+	c := &chain{order: order, next: make(map[string][]string), join: join}
+	for _, line := range strings.Split(corpus, "\n") {
+		tokens := tokenize(strings.TrimSpace(line))
+		if len(tokens) <= order {
+			continue
+		}
+		c.starts = append(c.starts, tokens[:order])
+		for i := order; i <= len(tokens); i++ {
+			key := strings.Join(tokens[i-order:i], "\x00")
+			if i == len(tokens) {
+				c.next[key] = append(c.next[key], "")
+			} else {
+				c.next[key] = append(c.next[key], tokens[i])
+			}
+		}
+	}
+	return c
+}
+
+// maxSentenceTokens 单个句子的最大 token 数，防止链在环上无限游走
+const maxSentenceTokens = 80
+
+func (c *chain) sentence() string {
+	start := c.starts[rand.Intn(len(c.starts))]
+	tokens := append([]string(nil), start...)
+	for len(tokens) < maxSentenceTokens {
+		options := c.next[strings.Join(tokens[len(tokens)-c.order:], "\x00")]
+		if len(options) == 0 {
+			break
+		}
+		t := options[rand.Intn(len(options))]
+		if t == "" {
+			break
+		}
+		tokens = append(tokens, t)
+	}
+	return strings.Join(tokens, c.join)
+}
+
+// Sentence 生成一句指定语言的句子
+func Sentence(lang Lang) string {
+	return chains[lang].sentence()
+}
+
+// Paragraph 连续生成句子直到长度达到 runes 个字符，超出部分被截断
+func Paragraph(lang Lang, runes int) string {
+	if runes <= 0 {
+		return ""
+	}
+	c := chains[lang]
+	var b strings.Builder
+	n := 0
+	for n < runes {
+		if n > 0 && c.join != "" {
+			b.WriteString(c.join)
+			n++
+		}
+		s := c.sentence()
+		b.WriteString(s)
+		n += utf8.RuneCountInString(s)
+	}
+	return Truncate(b.String(), runes)
+}
+
+// Truncate 按字符数截断字符串，不会截断多字节字符
+func Truncate(s string, runes int) string {
+	if utf8.RuneCountInString(s) <= runes {
+		return s
+	}
+	return string([]rune(s)[:runes])
+}
+
+// Length 在 [min, max] 区间内抽取一个长度：服从以几何中点为中位数的对数正态分布，
+// 多数文本较短、少数文本很长，更接近真实的备注与描述
+func Length(min, max int) int {
+	if max <= min {
+		return min
+	}
+	lo, hi := math.Log(float64(min)+1), math.Log(float64(max)+1)
+	mid, spread := (lo+hi)/2, (hi-lo)/4
+	n := int(math.Exp(mid+rand.NormFloat64()*spread)) - 1
+	if n < min {
+		return min
+	}
+	if n > max {
+		return max
+	}
+	return n
+}
@@ -0,0 +1,880 @@
+package validate
+
+import (
+	"fmt"
+	"math"
+	"time"
+
+	"gorm.io/gorm"
+	"my-go-data-generator/internal/locale"
+	"my-go-data-generator/internal/models"
+	"my-go-data-generator/internal/money"
+	"my-go-data-generator/internal/pricing"
+)
+
+// Violation 描述一条不满足不变量的数据
+type Violation struct {
+	Table  string // 表名
+	ID     uint   // 主键
+	Rule   string // 违反的规则
+	Detail string // 详细信息
+}
+
+func (v Violation) String() string {
+	return fmt.Sprintf("%s#%d [%s] %s", v.Table, v.ID, v.Rule, v.Detail)
+}
+
+// equal 比较两个金额：金额为定点数，按分精确比较
+func equal(a, b money.Amount) bool {
+	return a == b
+}
+
+// Order 校验单条订单的金额与生命周期不变量
+// items 为订单的全部订单行，products 为订单行所购产品（按产品ID索引）
+func Order(o *models.Order, items []models.OrderItem, products map[uint]models.Product) []Violation {
+	var out []Violation
+	add := func(rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "orders", ID: o.ID, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if o.Quantity <= 0 {
+		add("quantity_positive", "数量=%d", o.Quantity)
+	}
+	for _, f := range []struct {
+		name  string
+		value money.Amount
+	}{{"subtotal", o.Subtotal}, {"discount", o.DiscountAmount}, {"tax", o.TaxAmount}, {"shipping", o.ShippingCost}} {
+		if f.value < 0 {
+			add("amount_non_negative", "%s=%s", f.name, f.value)
+		}
+	}
+
+	out = append(out, lifecycle(o)...)
+
+	if sum := o.Subtotal - o.DiscountAmount + o.TaxAmount + o.ShippingCost; !equal(o.TotalAmount, sum) {
+		add("total_formula", "总额=%s，小计-折扣+税费+运费=%s", o.TotalAmount, sum)
+	}
+	if len(items) == 0 {
+		add("items_exist", "订单没有订单行")
+		return out
+	}
+
+	var subtotal, discount, tax money.Amount
+	quantity := 0
+	for i := range items {
+		it := &items[i]
+		if it.LineNumber == 1 && it.ProductID != o.ProductID {
+			add("primary_product", "主产品ID=%d，第一个订单行的产品ID=%d", o.ProductID, it.ProductID)
+		}
+		if it.Currency != o.Currency {
+			add("item_currency", "订单行%d 币种=%s，订单币种=%s", it.LineNumber, it.Currency, o.Currency)
+		}
+		var p *models.Product
+		if prod, ok := products[it.ProductID]; ok {
+			p = &prod
+		}
+		out = append(out, OrderItem(it, p)...)
+		subtotal += it.Subtotal
+		discount += it.DiscountAmount
+		tax += it.TaxAmount
+		quantity += it.Quantity
+	}
+	if o.Quantity != quantity {
+		add("items_quantity", "数量=%d，订单行数量之和=%d", o.Quantity, quantity)
+	}
+	if !equal(o.Subtotal, subtotal) {
+		add("items_subtotal", "小计=%s，订单行小计之和=%s", o.Subtotal, subtotal)
+	}
+	if !equal(o.DiscountAmount, discount) {
+		add("items_discount", "折扣=%s，订单行折扣之和=%s", o.DiscountAmount, discount)
+	}
+	if !equal(o.TaxAmount, tax) {
+		add("items_tax", "税费=%s，订单行税费之和=%s", o.TaxAmount, tax)
+	}
+	discounted := o.Subtotal - o.DiscountAmount
+	if want := pricing.Shipping(discounted, o.Quantity, o.Currency); !equal(o.ShippingCost, want) {
+		add("shipping_rule", "运费=%s，应为%s", o.ShippingCost, want)
+	}
+	return out
+}
+
+// OrderItem 校验单个订单行的金额不变量，p 为订单行所购产品
+func OrderItem(it *models.OrderItem, p *models.Product) []Violation {
+	var out []Violation
+	add := func(rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "order_items", ID: it.ID, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if it.Quantity <= 0 {
+		add("quantity_positive", "数量=%d", it.Quantity)
+	}
+	if p == nil {
+		add("product_exists", "产品ID=%d 不存在", it.ProductID)
+		return out
+	}
+	if want := pricing.Convert(p.Price, it.Currency); !equal(it.UnitPrice, want) {
+		add("unit_price", "单价=%s，产品价格换算为 %s 为%s", it.UnitPrice, it.Currency, want)
+	}
+	if want := it.UnitPrice.Times(it.Quantity); !equal(it.Subtotal, want) {
+		add("subtotal_formula", "小计=%s，单价×数量=%s %s", it.Subtotal, want, it.Currency)
+	}
+	if limit := pricing.MaxDiscount(it.Subtotal, p.Discount, it.Currency); it.DiscountAmount < 0 || it.DiscountAmount > limit {
+		add("discount_limit", "折扣=%s，超出产品折扣上限%s", it.DiscountAmount, limit)
+	}
+	want := pricing.LineFromSubtotal(it.UnitPrice, it.Subtotal, it.DiscountAmount, p.Category, it.Currency)
+	if !equal(it.TaxAmount, want.Tax) {
+		add("tax_rate", "税费=%s，按税率%.2f应为%s", it.TaxAmount, pricing.TaxRate(p.Category), want.Tax)
+	}
+	if !equal(it.LineTotal, want.Total) {
+		add("line_total", "行金额=%s，小计-折扣+税费=%s", it.LineTotal, want.Total)
+	}
+	return out
+}
+
+// Payments 校验订单的支付与退款：支付方式与币种和订单一致，已支付订单的成功支付之和等于订单总额，
+// 未支付订单没有成功支付；退款仅出现在有退货状态的订单上，且不超过对应支付的金额
+func Payments(o *models.Order, payments []models.Payment, refunds []models.Refund) []Violation {
+	var out []Violation
+	add := func(table string, id uint, rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: table, ID: id, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	var paid money.Amount
+	succeeded := make(map[uint]*models.Payment)
+	for i := range payments {
+		p := &payments[i]
+		if p.Method != o.PaymentMethod {
+			add("payments", p.ID, "payment_method", "支付方式=%s，订单支付方式=%s", p.Method, o.PaymentMethod)
+		}
+		if p.Currency != o.Currency {
+			add("payments", p.ID, "payment_currency", "币种=%s，订单币种=%s", p.Currency, o.Currency)
+		}
+		if p.Amount <= 0 {
+			add("payments", p.ID, "payment_positive", "金额=%s", p.Amount)
+		}
+		if p.CreatedAt.Before(o.OrderDate.Add(-clockSkew)) {
+			add("payments", p.ID, "payment_after_order", "发起时间 %s 早于下单时间 %s", p.CreatedAt, o.OrderDate)
+		}
+		if p.Status != models.PaymentStatusSucceeded {
+			if p.PaidAt != nil {
+				add("payments", p.ID, "paid_at_status", "状态=%s 却有支付时间", p.Status)
+			}
+			continue
+		}
+		paid += p.Amount
+		succeeded[p.ID] = p
+		if p.PaidAt == nil {
+			add("payments", p.ID, "paid_at_status", "支付成功但没有支付时间")
+		} else if o.ShippedAt != nil && p.PaidAt.After(o.ShippedAt.Add(clockSkew)) {
+			add("payments", p.ID, "paid_before_ship", "支付时间 %s 晚于发货时间 %s", p.PaidAt, o.ShippedAt)
+		}
+		if p.TransactionID == nil && p.Method != models.PaymentMethodCash {
+			add("payments", p.ID, "transaction_id", "%s 支付成功但没有交易号", p.Method)
+		}
+	}
+	if o.IsPaid() && !equal(paid, o.TotalAmount) {
+		add("orders", o.ID, "paid_total", "状态=%s，成功支付之和=%s，订单总额=%s", o.OrderStatus, paid, o.TotalAmount)
+	}
+	if !o.IsPaid() && paid > 0 {
+		add("orders", o.ID, "paid_total", "状态=%s 却有成功支付 %s", o.OrderStatus, paid)
+	}
+
+	if o.ReturnStatus == nil {
+		if len(refunds) > 0 {
+			add("orders", o.ID, "refund_after_return", "没有退货状态却有 %d 笔退款", len(refunds))
+		}
+		return out
+	}
+	if len(refunds) == 0 && paid > 0 {
+		add("orders", o.ID, "refund_after_return", "退货状态=%s 但没有退款记录", *o.ReturnStatus)
+	}
+	var refunded money.Amount
+	for i := range refunds {
+		r := &refunds[i]
+		refunded += r.Amount
+		if r.Amount <= 0 {
+			add("refunds", r.ID, "refund_positive", "金额=%s", r.Amount)
+		}
+		if p, ok := succeeded[r.PaymentID]; !ok {
+			add("refunds", r.ID, "refund_payment", "支付ID=%d 不是该订单的成功支付", r.PaymentID)
+		} else if r.Amount > p.Amount {
+			add("refunds", r.ID, "refund_limit", "退款=%s，超过支付金额%s", r.Amount, p.Amount)
+		}
+		if o.DeliveryDate != nil && r.RequestedAt.Before(o.DeliveryDate.Add(-clockSkew)) {
+			add("refunds", r.ID, "refund_after_delivery", "申请时间 %s 早于送达日期 %s", r.RequestedAt, o.DeliveryDate)
+		}
+		if (r.RefundedAt != nil) != (r.Status == models.RefundStatusRefunded) {
+			add("refunds", r.ID, "refunded_at_status", "状态=%s 与退款到账时间不一致", r.Status)
+		}
+	}
+	if refunded > paid {
+		add("orders", o.ID, "refund_limit", "退款之和=%s，超过已支付%s", refunded, paid)
+	}
+	return out
+}
+
+// Shipment 校验订单的物流轨迹：只有已发货订单才有事件，事件从揽收开始、序号连续、时间不倒退，
+// 且都在发货时间与送达日期之间；已完成订单以签收结束，未完成订单没有签收事件
+func Shipment(o *models.Order, events []models.ShipmentEvent) []Violation {
+	var out []Violation
+	add := func(id uint, rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "shipment_events", ID: id, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if !o.IsShipped() {
+		if len(events) > 0 {
+			out = append(out, Violation{Table: "orders", ID: o.ID, Rule: "events_after_ship", Detail: fmt.Sprintf("状态=%s 却有 %d 条物流事件", o.OrderStatus, len(events))})
+		}
+		return out
+	}
+	if len(events) == 0 {
+		return append(out, Violation{Table: "orders", ID: o.ID, Rule: "events_after_ship", Detail: fmt.Sprintf("状态=%s 但没有物流事件", o.OrderStatus)})
+	}
+	if events[0].EventType != models.ShipmentEventPickedUp {
+		add(events[0].ID, "event_order", "第一个事件为 %s，应为%s", events[0].EventType, models.ShipmentEventPickedUp)
+	}
+	for i := range events {
+		e := &events[i]
+		if e.Sequence != i+1 {
+			add(e.ID, "event_sequence", "序号=%d，应为%d", e.Sequence, i+1)
+		}
+		if o.TrackingNumber != nil && e.TrackingNumber != *o.TrackingNumber {
+			add(e.ID, "event_tracking", "物流单号=%s，订单物流单号=%s", e.TrackingNumber, *o.TrackingNumber)
+		}
+		if i > 0 && e.EventTime.Before(events[i-1].EventTime) {
+			add(e.ID, "event_time_order", "事件时间 %s 早于上一个事件 %s", e.EventTime, events[i-1].EventTime)
+		}
+		if o.ShippedAt != nil && e.EventTime.Before(o.ShippedAt.Add(-clockSkew)) {
+			add(e.ID, "event_after_ship", "事件时间 %s 早于发货时间 %s", e.EventTime, o.ShippedAt)
+		}
+		if o.DeliveryDate != nil && e.EventTime.After(o.DeliveryDate.Add(clockSkew)) {
+			add(e.ID, "event_before_delivery", "事件时间 %s 晚于送达日期 %s", e.EventTime, o.DeliveryDate)
+		}
+		if e.EventType == models.ShipmentEventDelivered && (o.OrderStatus != models.OrderStatusCompleted || i != len(events)-1) {
+			add(e.ID, "delivered_last", "状态=%s，签收事件是第 %d/%d 个", o.OrderStatus, i+1, len(events))
+		}
+	}
+	if last := events[len(events)-1]; o.OrderStatus == models.OrderStatusCompleted && last.EventType != models.ShipmentEventDelivered {
+		add(last.ID, "delivered_last", "已完成订单的最后一个事件为 %s", last.EventType)
+	}
+	return out
+}
+
+// Reviews 校验订单的评论：只有已完成订单才有评论，评论者是下单用户，评论的产品在订单行中，
+// 评论时间不早于送达日期
+func Reviews(o *models.Order, items []models.OrderItem, reviews []models.Review) []Violation {
+	var out []Violation
+	add := func(id uint, rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "reviews", ID: id, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	bought := make(map[uint]bool, len(items))
+	for _, it := range items {
+		bought[it.ProductID] = true
+	}
+	for i := range reviews {
+		r := &reviews[i]
+		if o.OrderStatus != models.OrderStatusCompleted {
+			add(r.ID, "review_after_complete", "订单状态=%s 却有评论", o.OrderStatus)
+		}
+		if r.UserID != o.UserID {
+			add(r.ID, "review_by_buyer", "评论用户=%d，下单用户=%d", r.UserID, o.UserID)
+		}
+		if !bought[r.ProductID] {
+			add(r.ID, "review_by_buyer", "产品ID=%d 不在订单 %d 中", r.ProductID, o.ID)
+		}
+		if r.Stars < 1 || r.Stars > 5 {
+			add(r.ID, "stars_range", "星级=%d", r.Stars)
+		}
+		if o.DeliveryDate != nil && r.CreatedAt.Before(o.DeliveryDate.Add(-clockSkew)) {
+			add(r.ID, "review_after_delivery", "评论时间 %s 早于送达日期 %s", r.CreatedAt, o.DeliveryDate)
+		}
+	}
+	return out
+}
+
+// ProductRating 校验产品的 Rating 与 NumberOfReviews 是否等于其评论的汇总，count、totalStars 为评论数与星级之和
+func ProductRating(p *models.Product, count, totalStars int) []Violation {
+	var out []Violation
+	if p.NumberOfReviews != count {
+		out = append(out, Violation{Table: "products", ID: p.ID, Rule: "review_count", Detail: fmt.Sprintf("评论数=%d，实际评论=%d", p.NumberOfReviews, count)})
+	}
+	// 评分为两位小数的浮点数，容差半个百分点
+	if want := models.RatingOf(totalStars, count); math.Abs(p.Rating-want) > 0.005 {
+		out = append(out, Violation{Table: "products", ID: p.ID, Rule: "rating_average", Detail: fmt.Sprintf("评分=%.2f，评论平均星级=%.2f", p.Rating, want)})
+	}
+	return out
+}
+
+// ProductStock 校验产品的库存是否等于其库存流水的余额，balance 为该产品全部流水数量之和
+func ProductStock(p *models.Product, balance int) []Violation {
+	if p.Stock != balance {
+		return []Violation{{Table: "products", ID: p.ID, Rule: "stock_ledger", Detail: fmt.Sprintf("库存=%d，流水余额=%d", p.Stock, balance)}}
+	}
+	return nil
+}
+
+// Inventory 校验订单的库存流水：每个订单行恰有等量的销售出库，已取消订单全部释放库存，
+// 退货入库只出现在有退货状态的订单上且不超过购买数量，补货入库不关联订单
+func Inventory(o *models.Order, items []models.OrderItem, movements []models.InventoryMovement) []Violation {
+	var out []Violation
+	add := func(id uint, rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "inventory_movements", ID: id, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	bought := make(map[uint]int, len(items))
+	for _, it := range items {
+		bought[it.ProductID] += it.Quantity
+	}
+	sold := make(map[uint]int)
+	released := make(map[uint]int)
+	returned := make(map[uint]int)
+	for i := range movements {
+		m := &movements[i]
+		if _, ok := bought[m.ProductID]; !ok {
+			add(m.ID, "movement_product", "产品ID=%d 不在订单 %d 中", m.ProductID, o.ID)
+		}
+		switch m.MovementType {
+		case models.MovementSale:
+			sold[m.ProductID] -= m.Quantity
+		case models.MovementAdjustment:
+			released[m.ProductID] += m.Quantity
+		case models.MovementReturn:
+			returned[m.ProductID] += m.Quantity
+		case models.MovementReceipt:
+			add(m.ID, "receipt_unlinked", "入库流水关联了订单 %d", o.ID)
+		default:
+			add(m.ID, "movement_type", "未知流水类型 %s", m.MovementType)
+		}
+	}
+	for id, q := range bought {
+		if sold[id] != q {
+			add(0, "sale_quantity", "订单 %d 产品ID=%d 购买 %d 件，销售出库 %d 件", o.ID, id, q, sold[id])
+		}
+		if o.OrderStatus == models.OrderStatusCancelled && released[id] != q {
+			add(0, "cancel_release", "已取消订单 %d 产品ID=%d 购买 %d 件，释放 %d 件", o.ID, id, q, released[id])
+		}
+		if returned[id] < 0 || returned[id] > q {
+			add(0, "return_quantity", "订单 %d 产品ID=%d 购买 %d 件，退货入库 %d 件", o.ID, id, q, returned[id])
+		}
+	}
+	if o.OrderStatus != models.OrderStatusCancelled && len(released) > 0 {
+		add(0, "cancel_release", "订单 %d 状态=%s 却释放了库存", o.ID, o.OrderStatus)
+	}
+	if o.ReturnStatus == nil && len(returned) > 0 {
+		add(0, "return_quantity", "订单 %d 没有退货状态却有退货入库", o.ID)
+	}
+	return out
+}
+
+// Conversion 校验订单的转化会话：订单至多关联一个下单事件，下单者即订单用户、时间即下单时间，
+// 下单事件是会话的最后一个事件，且会话中此前加购过订单中的每个商品；session 为下单事件所在会话的全部事件
+func Conversion(o *models.Order, items []models.OrderItem, purchases []models.PageView, session []models.PageView) []Violation {
+	var out []Violation
+	add := func(id uint, rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "page_views", ID: id, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if len(purchases) == 0 {
+		return nil
+	}
+	if len(purchases) > 1 {
+		add(purchases[1].ID, "conversion_single", "订单 %d 关联了 %d 个下单事件", o.ID, len(purchases))
+	}
+	p := &purchases[0]
+	if p.EventType != models.PageViewPurchase {
+		add(p.ID, "conversion_type", "关联订单的事件类型为 %s", p.EventType)
+	}
+	if p.UserID == nil || *p.UserID != o.UserID {
+		add(p.ID, "conversion_user", "下单事件的用户与订单 %d 的用户 %d 不一致", o.ID, o.UserID)
+	}
+	if d := p.EventTime.Sub(o.OrderDate); d > clockSkew || d < -clockSkew {
+		add(p.ID, "conversion_time", "下单事件时间 %s，下单时间 %s", p.EventTime, o.OrderDate)
+	}
+	if len(session) == 0 {
+		return out
+	}
+	if last := session[len(session)-1]; last.ID != p.ID {
+		add(p.ID, "conversion_last", "下单事件是会话 %s 的第 %d 个事件，会话共 %d 个", p.SessionID, p.Sequence, len(session))
+	}
+	carted := make(map[uint]bool)
+	for i := range session {
+		v := &session[i]
+		if i > 0 && v.EventTime.Before(session[i-1].EventTime) {
+			add(v.ID, "session_order", "会话 %s 第 %d 个事件早于上一个事件", v.SessionID, v.Sequence)
+		}
+		if v.EventType == models.PageViewAddToCart && v.ProductID != nil && v.Sequence < p.Sequence {
+			carted[*v.ProductID] = true
+		}
+	}
+	for _, it := range items {
+		if !carted[it.ProductID] {
+			add(p.ID, "conversion_cart", "订单 %d 的产品ID=%d 在会话中没有加购", o.ID, it.ProductID)
+		}
+	}
+	return out
+}
+
+// DimensionRefs 校验规范化模式下一行引用的维度ID：必须非空且存在于对应的维度表，
+// refs 为 维度表 -> 引用的ID，known 为各维度表已有的主键到名称的映射
+func DimensionRefs(table string, id uint, refs map[string]*uint, known map[string]map[uint]string) []Violation {
+	var out []Violation
+	for dim, ref := range refs {
+		switch {
+		case ref == nil:
+			out = append(out, Violation{Table: table, ID: id, Rule: "dimension_ref", Detail: fmt.Sprintf("%s 的维度ID为 NULL", dim)})
+		case !hasKey(known[dim], *ref):
+			out = append(out, Violation{Table: table, ID: id, Rule: "dimension_ref", Detail: fmt.Sprintf("%s 中不存在ID=%d", dim, *ref)})
+		}
+	}
+	return out
+}
+
+// lifecycle 校验订单生命周期字段与 OrderStatus 是否一致
+func lifecycle(o *models.Order) []Violation {
+	var out []Violation
+	add := func(rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "orders", ID: o.ID, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if o.IsShipped() {
+		if o.TrackingNumber == nil || *o.TrackingNumber == "" {
+			add("tracking_after_ship", "状态=%s 但没有物流单号", o.OrderStatus)
+		}
+		if o.ShippedAt == nil || o.DeliveryDate == nil {
+			add("delivery_after_ship", "状态=%s 但缺少发货时间或送达日期", o.OrderStatus)
+		} else {
+			if o.ShippedAt.Before(o.OrderDate) {
+				add("ship_after_order", "发货时间 %s 早于下单时间 %s", o.ShippedAt, o.OrderDate)
+			}
+			if o.DeliveryDate.Before(*o.ShippedAt) {
+				add("delivery_after_ship", "送达日期 %s 早于发货时间 %s", o.DeliveryDate, o.ShippedAt)
+			}
+		}
+	} else {
+		if o.TrackingNumber != nil {
+			add("tracking_after_ship", "状态=%s 却有物流单号 %s", o.OrderStatus, *o.TrackingNumber)
+		}
+		if o.ShippedAt != nil || o.DeliveryDate != nil {
+			add("delivery_after_ship", "状态=%s 却有发货时间或送达日期", o.OrderStatus)
+		}
+	}
+	if o.ReturnStatus != nil && o.OrderStatus != models.OrderStatusCompleted {
+		add("return_after_complete", "状态=%s 却有退货状态 %s", o.OrderStatus, *o.ReturnStatus)
+	}
+	if o.GiftMessage != nil && !o.IsGift {
+		add("gift_message", "非礼物订单却有礼物留言")
+	}
+	return out
+}
+
+// clockSkew 比较时间时允许的误差，吸收数据库时间精度截断与时钟偏差
+const clockSkew = time.Second
+
+// User 校验用户属性之间的相关性与时间顺序，now 为校验时刻
+func User(u *models.User, now time.Time) []Violation {
+	var out []Violation
+	add := func(rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "users", ID: u.ID, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if u.Age < 18 || u.Age > 120 {
+		add("age_range", "年龄=%d", u.Age)
+	}
+	if u.RegistrationDate.After(u.LastLogin.Add(clockSkew)) {
+		add("login_after_registration", "最后登录 %s 早于注册 %s", u.LastLogin, u.RegistrationDate)
+	}
+	if u.LastLogin.After(now.Add(clockSkew)) {
+		add("login_not_future", "最后登录 %s 晚于当前时间", u.LastLogin)
+	}
+	if d := u.CreatedAt.Sub(u.RegistrationDate); d > clockSkew || d < -clockSkew {
+		add("created_is_registration", "创建时间 %s 不等于注册时间 %s", u.CreatedAt, u.RegistrationDate)
+	}
+	if u.Age < models.MinMarriageAge && u.MaritalStatus != models.MaritalSingle {
+		add("marital_age", "年龄=%d 婚姻状况=%s", u.Age, u.MaritalStatus)
+	}
+	if u.Occupation == models.OccupationRetired && u.Age < 50 {
+		add("occupation_age", "年龄=%d 职业=%s", u.Age, u.Occupation)
+	}
+	if u.Income < 0 {
+		add("income_non_negative", "收入=%s", u.Income)
+	}
+	if pack, ok := locale.ByNationality(u.Nationality); !ok {
+		add("locale_known", "未知国籍 %s", u.Nationality)
+	} else if u.Currency != pack.Currency || u.PreferredLanguage != pack.Language || !pack.HasTimezone(u.Timezone) {
+		add("locale_consistent", "国籍=%s 币种=%s 语言=%s 时区=%s 不一致", u.Nationality, u.Currency, u.PreferredLanguage, u.Timezone)
+	}
+	tenureDays := now.Sub(u.RegistrationDate).Hours() / 24
+	if limit := int(math.Ceil(tenureDays * models.MaxLoyaltyPointsPerDay)); u.LoyaltyPoints < 0 || u.LoyaltyPoints > limit {
+		add("loyalty_tenure", "积分=%d 超过在网 %.0f 天的上限 %d", u.LoyaltyPoints, tenureDays, limit)
+	}
+	return out
+}
+
+// Product 校验产品的库存不变量：库存状态必须与库存数量一致
+func Product(p *models.Product) []Violation {
+	var out []Violation
+	add := func(rule, format string, args ...interface{}) {
+		out = append(out, Violation{Table: "products", ID: p.ID, Rule: rule, Detail: fmt.Sprintf(format, args...)})
+	}
+
+	if p.Stock < 0 {
+		add("stock_non_negative", "库存=%d", p.Stock)
+	}
+	switch p.StockStatus {
+	case models.StockStatusInStock:
+		if p.Stock <= 0 {
+			add("stock_status", "状态=%s 但库存=%d", p.StockStatus, p.Stock)
+		}
+	case models.StockStatusOutOfStock, models.StockStatusPreOrder:
+		if p.Stock != 0 {
+			add("stock_status", "状态=%s 但库存=%d", p.StockStatus, p.Stock)
+		}
+	default:
+		add("stock_status", "未知库存状态 %s", p.StockStatus)
+	}
+	if p.Price <= 0 {
+		add("price_positive", "价格=%s", p.Price)
+	}
+	if p.Discount < 0 || p.Discount >= 1 {
+		add("discount_range", "折扣率=%.2f", p.Discount)
+	}
+	return out
+}
+
+// Report 回读校验的汇总结果
+type Report struct {
+	Users          int         // 已检查的用户数
+	Products       int         // 已检查的产品数
+	Orders         int         // 已检查的订单数
+	OrderItems     int         // 已检查的订单行数
+	Payments       int         // 已检查的支付记录数
+	Refunds        int         // 已检查的退款记录数
+	ShipmentEvents int         // 已检查的物流事件数
+	Reviews        int         // 已检查的评论数
+	Movements      int         // 已检查的库存流水数
+	PageViews      int         // 已检查的转化会话事件数
+	Violations     []Violation // 发现的违规记录（最多保留 maxKept 条）
+	Total          int         // 违规总数
+}
+
+// maxKept 报告中保留的违规明细上限，避免大表校验时内存膨胀
+const maxKept = 1000
+
+func (r *Report) add(vs []Violation) {
+	r.Total += len(vs)
+	for _, v := range vs {
+		if len(r.Violations) >= maxKept {
+			return
+		}
+		r.Violations = append(r.Violations, v)
+	}
+}
+
+// OrderNumbers 检查订单号唯一：orders 分区后唯一索引包含 order_date，数据库不再单独保证订单号唯一
+func OrderNumbers(db *gorm.DB) ([]Violation, error) {
+	var rows []struct {
+		OrderNumber string
+		ID          uint
+		N           int
+	}
+	err := db.Unscoped().Model(&models.Order{}).Select("order_number, MIN(id) AS id, COUNT(*) AS n").
+		Group("order_number").Having("COUNT(*) > 1").Limit(maxKept).Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	out := make([]Violation, len(rows))
+	for i, r := range rows {
+		out[i] = Violation{Table: "orders", ID: r.ID, Rule: "order_number_unique", Detail: fmt.Sprintf("订单号 %s 出现 %d 次", r.OrderNumber, r.N)}
+	}
+	return out, nil
+}
+
+// Database 从任意 MySQL 协议的目标库（MySQL、Databend 等）分批回读用户、产品与订单并校验不变量；
+// since 非零时跳过更早下单的订单，其从属数据可能已随过期分区删除
+func Database(db *gorm.DB, batchSize int, since time.Time) (*Report, error) {
+	report := &Report{}
+	now := time.Now()
+	// 产品表没有分类文本列时为规范化模式，各行的维度ID需引用已有的维度行
+	known, err := loadDimensions(db)
+	if err != nil {
+		return report, err
+	}
+	var users []models.User
+	result := db.Order("id").FindInBatches(&users, batchSize, func(tx *gorm.DB, _ int) error {
+		for i := range users {
+			u := &users[i]
+			if known != nil {
+				nameUser(u, known)
+				report.add(DimensionRefs("users", u.ID, map[string]*uint{"regions": u.RegionID}, known))
+			}
+			report.add(User(u, now))
+		}
+		report.Users += len(users)
+		return nil
+	})
+	if result.Error != nil {
+		return report, result.Error
+	}
+
+	var products []models.Product
+	result = db.Order("id").FindInBatches(&products, batchSize, func(tx *gorm.DB, _ int) error {
+		stats, err := loadProductStats(db, &models.Review{}, "stars", products)
+		if err != nil {
+			return err
+		}
+		ledger, err := loadProductStats(db, &models.InventoryMovement{}, "quantity", products)
+		if err != nil {
+			return err
+		}
+		for i := range products {
+			p := &products[i]
+			if known != nil {
+				nameProduct(p, known)
+			}
+			report.add(Product(p))
+			report.add(ProductRating(p, stats[p.ID].N, stats[p.ID].Total))
+			report.add(ProductStock(p, ledger[p.ID].Total))
+			if known != nil {
+				report.add(DimensionRefs("products", p.ID, map[string]*uint{
+					"categories":    p.CategoryID,
+					"manufacturers": p.ManufacturerID,
+					"suppliers":     p.SupplierID,
+					"regions":       p.OriginRegionID,
+				}, known))
+			}
+			report.Movements += ledger[p.ID].N
+		}
+		report.Products += len(products)
+		return nil
+	})
+	if result.Error != nil {
+		return report, result.Error
+	}
+
+	duplicates, err := OrderNumbers(db)
+	if err != nil {
+		return report, err
+	}
+	report.add(duplicates)
+
+	var batch []models.Order
+	orders := db.Order("id")
+	if !since.IsZero() {
+		orders = orders.Where("order_date >= ?", since)
+	}
+	result = orders.FindInBatches(&batch, batchSize, func(tx *gorm.DB, _ int) error {
+		ids := make([]uint, len(batch))
+		for i, o := range batch {
+			ids[i] = o.ID
+		}
+		items, err := loadByOrder(db, ids, "order_id, line_number", func(it *models.OrderItem) uint { return it.OrderID })
+		if err != nil {
+			return err
+		}
+		payments, err := loadByOrder(db, ids, "order_id, attempt_number", func(p *models.Payment) uint { return p.OrderID })
+		if err != nil {
+			return err
+		}
+		refunds, err := loadByOrder(db, ids, "order_id, id", func(r *models.Refund) uint { return r.OrderID })
+		if err != nil {
+			return err
+		}
+		events, err := loadByOrder(db, ids, "order_id, sequence", func(e *models.ShipmentEvent) uint { return e.OrderID })
+		if err != nil {
+			return err
+		}
+		reviews, err := loadByOrder(db, ids, "order_id, product_id", func(r *models.Review) uint { return r.OrderID })
+		if err != nil {
+			return err
+		}
+		movements, err := loadByOrder(db, ids, "order_id, id", func(m *models.InventoryMovement) uint { return *m.OrderID })
+		if err != nil {
+			return err
+		}
+		purchases, err := loadByOrder(db, ids, "order_id, id", func(v *models.PageView) uint { return *v.OrderID })
+		if err != nil {
+			return err
+		}
+		sessions, err := loadSessions(db, purchases)
+		if err != nil {
+			return err
+		}
+		products, err := loadProducts(db, items, known)
+		if err != nil {
+			return err
+		}
+		for i := range batch {
+			o := &batch[i]
+			report.add(Order(o, items[o.ID], products))
+			report.add(Payments(o, payments[o.ID], refunds[o.ID]))
+			report.add(Shipment(o, events[o.ID]))
+			report.add(Reviews(o, items[o.ID], reviews[o.ID]))
+			report.add(Inventory(o, items[o.ID], movements[o.ID]))
+			var session []models.PageView
+			if len(purchases[o.ID]) > 0 {
+				session = sessions[purchases[o.ID][0].SessionID]
+			}
+			report.add(Conversion(o, items[o.ID], purchases[o.ID], session))
+			if known != nil {
+				report.add(DimensionRefs("orders", o.ID, map[string]*uint{"payment_methods": o.PaymentMethodID}, known))
+				for j := range payments[o.ID] {
+					p := &payments[o.ID][j]
+					report.add(DimensionRefs("payments", p.ID, map[string]*uint{"payment_methods": p.MethodID}, known))
+				}
+			}
+			report.PageViews += len(session)
+			report.OrderItems += len(items[o.ID])
+			report.Payments += len(payments[o.ID])
+			report.Refunds += len(refunds[o.ID])
+			report.ShipmentEvents += len(events[o.ID])
+			report.Reviews += len(reviews[o.ID])
+		}
+		report.Orders += len(batch)
+		return nil
+	})
+	return report, result.Error
+}
+
+// loadByOrder 加载一批订单的从属明细（订单行、支付、退款、物流事件、评论、库存流水、下单事件），按订单ID分组，组内按 orderBy 排序
+func loadByOrder[T any](db *gorm.DB, ids []uint, orderBy string, orderID func(*T) uint) (map[uint][]T, error) {
+	var rows []T
+	if err := db.Where("order_id IN ?", ids).Order(orderBy).Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	m := make(map[uint][]T, len(ids))
+	for i := range rows {
+		id := orderID(&rows[i])
+		m[id] = append(m[id], rows[i])
+	}
+	return m, nil
+}
+
+// loadSessions 加载下单事件所在会话的全部事件，按会话ID分组，组内按会话内序号排序
+func loadSessions(db *gorm.DB, purchases map[uint][]models.PageView) (map[string][]models.PageView, error) {
+	var ids []string
+	for _, group := range purchases {
+		for _, v := range group {
+			ids = append(ids, v.SessionID)
+		}
+	}
+	m := make(map[string][]models.PageView, len(ids))
+	if len(ids) == 0 {
+		return m, nil
+	}
+	var rows []models.PageView
+	if err := db.Where("session_id IN ?", ids).Order("session_id, sequence").Find(&rows).Error; err != nil {
+		return nil, err
+	}
+	for _, v := range rows {
+		m[v.SessionID] = append(m[v.SessionID], v)
+	}
+	return m, nil
+}
+
+// loadDimensions 规范化模式下加载各维度表的主键与名称，反规范化模式（产品表仍有分类文本列）返回 nil
+func loadDimensions(db *gorm.DB) (map[string]map[uint]string, error) {
+	if db.Migrator().HasColumn(&models.Product{}, "category") {
+		return nil, nil
+	}
+	known := make(map[string]map[uint]string)
+	for _, table := range []string{"categories", "manufacturers", "suppliers", "regions", "payment_methods"} {
+		var rows []struct {
+			ID   uint
+			Name string
+		}
+		if err := db.Table(table).Select("id, name").Scan(&rows).Error; err != nil {
+			return nil, err
+		}
+		known[table] = make(map[uint]string, len(rows))
+		for _, r := range rows {
+			known[table][r.ID] = r.Name
+		}
+	}
+	return known, nil
+}
+
+// hasKey 维度表中是否有该主键
+func hasKey(names map[uint]string, id uint) bool {
+	_, ok := names[id]
+	return ok
+}
+
+// dimensionName 返回维度ID对应的名称，ID 为空或不存在时返回空串（由 DimensionRefs 报告）
+func dimensionName(names map[uint]string, id *uint) string {
+	if id == nil {
+		return ""
+	}
+	return names[*id]
+}
+
+// nameUser 规范化模式下按维度ID回填用户的国籍，规范化模式不建文本列，否则每个用户都按未知国籍报告
+func nameUser(u *models.User, known map[string]map[uint]string) {
+	u.Nationality = dimensionName(known["regions"], u.RegionID)
+}
+
+// nameProduct 规范化模式下按维度ID回填产品的分类、制造商、供应商与产地，否则订单行税率按默认分类校验
+func nameProduct(p *models.Product, known map[string]map[uint]string) {
+	p.Category = dimensionName(known["categories"], p.CategoryID)
+	p.Manufacturer = dimensionName(known["manufacturers"], p.ManufacturerID)
+	p.Supplier = dimensionName(known["suppliers"], p.SupplierID)
+	p.CountryOfOrigin = dimensionName(known["regions"], p.OriginRegionID)
+}
+
+// productStat 一个产品的明细汇总：记录数与某一列之和
+type productStat struct {
+	ProductID uint
+	N         int
+	Total     int
+}
+
+// loadProductStats 在目标库中按产品汇总一批产品在 model 表中的记录数与 column 列之和（如评论星级、库存流水数量）
+func loadProductStats(db *gorm.DB, model interface{}, column string, products []models.Product) (map[uint]productStat, error) {
+	ids := make([]uint, len(products))
+	for i, p := range products {
+		ids[i] = p.ID
+	}
+	var rows []productStat
+	err := db.Model(model).
+		Select("product_id, COUNT(*) AS n, SUM("+column+") AS total").
+		Where("product_id IN ?", ids).
+		Group("product_id").
+		Scan(&rows).Error
+	if err != nil {
+		return nil, err
+	}
+	m := make(map[uint]productStat, len(rows))
+	for _, r := range rows {
+		m[r.ProductID] = r
+	}
+	return m, nil
+}
+
+// loadProducts 加载一批订单行所购的产品，known 非 nil（规范化模式）时按维度ID回填名称
+func loadProducts(db *gorm.DB, items map[uint][]models.OrderItem, known map[string]map[uint]string) (map[uint]models.Product, error) {
+	var ids []uint
+	seen := make(map[uint]bool)
+	for _, group := range items {
+		for _, it := range group {
+			if !seen[it.ProductID] {
+				seen[it.ProductID] = true
+				ids = append(ids, it.ProductID)
+			}
+		}
+	}
+	if len(ids) == 0 {
+		return map[uint]models.Product{}, nil
+	}
+	var products []models.Product
+	if err := db.Where("id IN ?", ids).Find(&products).Error; err != nil {
+		return nil, err
+	}
+	m := make(map[uint]models.Product, len(products))
+	for _, p := range products {
+		if known != nil {
+			nameProduct(&p, known)
+		}
+		m[p.ID] = p
+	}
+	return m, nil
+}
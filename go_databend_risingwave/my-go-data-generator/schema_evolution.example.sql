@@ -0,0 +1,12 @@
+-- 表结构变更计划示例：SCHEMA_EVOLUTION=schema_evolution.example.sql
+-- 每行一条 ALTER TABLE，以定时任务启动后的时长开头，到期后在下一次定时任务（每 30 秒）时执行；
+-- 每条只能有一个操作。ADD/MODIFY COLUMN 行末 gen: 指定新列的 fieldgen 生成器，省略时按列类型推导
+
+2m   ALTER TABLE orders ADD COLUMN coupon_code VARCHAR(16) NULL;             -- gen: regex(CPN[0-9]{8})
+5m   ALTER TABLE users ADD COLUMN vip_level TINYINT NOT NULL DEFAULT 0;      -- gen: intrange(0,5)
+8m   ALTER TABLE orders MODIFY COLUMN coupon_code VARCHAR(32) NULL;          -- gen: regex(CPN-[A-Z]{4}-[0-9]{8})
+10m  ALTER TABLE products ADD INDEX idx_product_weight (weight);
+12m  ALTER TABLE users RENAME COLUMN hobby TO hobbies;
+15m  ALTER TABLE users MODIFY COLUMN vip_level SMALLINT NOT NULL DEFAULT 0;  -- gen: intrange(0,100)
+20m  ALTER TABLE orders RENAME COLUMN coupon_code TO promo_code;
+30m  ALTER TABLE orders DROP COLUMN promo_code;
@@ -0,0 +1,27 @@
+-- 表结构说明示例：SCHEMA_SPEC=schema_spec.example.sql
+-- 每条 CREATE TABLE 即建表 DDL；表前 @rows 为批量生成的行数，@stream 为定时任务每 30 秒追加的行数；
+-- 列后 gen: 指定 fieldgen 生成器，省略时按 REFERENCES、主键/唯一约束与列类型推导
+
+-- @rows 2000
+CREATE TABLE IF NOT EXISTS warehouses (
+  id INT UNSIGNED NOT NULL PRIMARY KEY,
+  code VARCHAR(16) NOT NULL UNIQUE,                 -- gen: seq(WH%05d)
+  city VARCHAR(32) NOT NULL,                        -- gen: enum(上海:30,北京:25,广州:20,成都:15,武汉:10)
+  capacity INT NOT NULL,                            -- gen: intrange(1000,200000)
+  opened_at DATE NOT NULL                           -- gen: daterange(-3650d,-30d)
+);
+
+-- @rows 100000
+-- @stream 5
+CREATE TABLE IF NOT EXISTS coupons (
+  id BIGINT UNSIGNED NOT NULL PRIMARY KEY AUTO_INCREMENT,
+  code VARCHAR(32) NOT NULL UNIQUE,                 -- gen: seq(CPN%010d)
+  user_id BIGINT UNSIGNED NOT NULL REFERENCES users(id), -- gen: ref(users,zipf)
+  warehouse_id INT UNSIGNED REFERENCES warehouses(id),
+  kind ENUM('满减','折扣','免运费') NOT NULL,
+  amount DECIMAL(10,2) NOT NULL,                    -- gen: floatrange(5,200,2)
+  redeemed TINYINT(1) NOT NULL,                     -- gen: bool(0.3)
+  expires_at DATETIME,                              -- gen: daterange(0,90d)
+  created_at DATETIME NOT NULL DEFAULT CURRENT_TIMESTAMP,
+  KEY idx_coupon_user (user_id)
+);